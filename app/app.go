@@ -131,6 +131,7 @@ var (
 		govtypes.ModuleName:            {authtypes.Burner},
 		ibctransfertypes.ModuleName:    {authtypes.Minter, authtypes.Burner},
 		icatypes.ModuleName:            nil,
+		compute.ModuleName:             {authtypes.Burner},
 	}
 
 	// Module accounts that are allowed to receive tokens
@@ -268,7 +269,7 @@ func NewSecretNetworkApp(
 		reg.StoreKey, feegrant.StoreKey, authzkeeper.StoreKey, icahosttypes.StoreKey,
 	)
 
-	tKeys := sdk.NewTransientStoreKeys(paramstypes.TStoreKey)
+	tKeys := sdk.NewTransientStoreKeys(paramstypes.TStoreKey, compute.TStoreKey)
 	memKeys := sdk.NewMemoryStoreKeys(capabilitytypes.MemStoreKey)
 
 	// Initialize our application with the store keys it requires
@@ -426,18 +427,22 @@ func NewSecretNetworkApp(
 		appCodec,
 		*legacyAmino,
 		keys[compute.StoreKey],
+		tKeys[compute.TStoreKey],
+		app.getSubspace(compute.ModuleName),
 		app.accountKeeper,
 		app.bankKeeper,
 		app.govKeeper,
 		app.distrKeeper,
 		app.mintKeeper,
 		app.stakingKeeper,
+		app.BaseApp.MsgServiceRouter(),
 		computeRouter,
 		computeDir,
 		computeConfig,
 		supportedFeatures,
 		nil,
 		nil,
+		compute.WithPortKeeper(&app.ibcKeeper.PortKeeper),
 	)
 
 	// NOTE: we may consider parsing `appOpts` inside module constructors. For the moment
@@ -47,6 +47,7 @@ pub fn migrate<S: Storage, A: Api, Q: Querier>(
         from_address: env.contract.address,
         to_address: msg.payout.clone(),
         amount: balance,
+        deadline_height: None,
     };
 
     let data_msg = format!("burnt {} keys", count).into_bytes();
@@ -116,6 +117,7 @@ mod tests {
                 from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
                 to_address: payout,
                 amount: coins(123456, "gold"),
+                deadline_height: None,
             }
             .into(),
         );
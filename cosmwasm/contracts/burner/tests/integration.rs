@@ -82,6 +82,7 @@ fn migrate_cleans_up_data() {
             from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
             to_address: payout,
             amount: coins(123456, "gold"),
+            deadline_height: None,
         }
         .into(),
     );
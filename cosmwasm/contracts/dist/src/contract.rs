@@ -54,5 +54,6 @@ pub fn try_query_rewards<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![],
         data: Some(Binary::from(active_proposal.to_be_bytes().to_vec())),
+        submessages: vec![],
     })
 }
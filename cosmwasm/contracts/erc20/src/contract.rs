@@ -134,6 +134,7 @@ fn try_transfer<S: Storage, A: Api, Q: Querier>(
             log("recipient", recipient.as_str()),
         ],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -180,6 +181,7 @@ fn try_transfer_from<S: Storage, A: Api, Q: Querier>(
             log("recipient", recipient.as_str()),
         ],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -206,6 +208,7 @@ fn try_approve<S: Storage, A: Api, Q: Querier>(
             log("spender", spender.as_str()),
         ],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -254,6 +257,7 @@ fn try_burn<S: Storage, A: Api, Q: Querier>(
             log("amount", &amount.to_string()),
         ],
         data: None,
+        submessages: vec![],
     };
 
     Ok(res)
@@ -111,9 +111,11 @@ fn send_tokens<A: Api>(
             from_address: from_human,
             to_address: to_human,
             amount,
+            deadline_height: None,
         })],
         log,
         data: None,
+        submessages: vec![],
     };
     Ok(r)
 }
@@ -242,6 +244,7 @@ mod tests {
                 from_address: HumanAddr::from("cosmos2contract"),
                 to_address: HumanAddr::from("benefits"),
                 amount: coins(1000, "earth"),
+                deadline_height: None,
             })
         );
 
@@ -259,6 +262,7 @@ mod tests {
                 from_address: HumanAddr::from("cosmos2contract"),
                 to_address: HumanAddr::from("benefits"),
                 amount: coins(500, "earth"),
+                deadline_height: None,
             })
         );
     }
@@ -307,6 +311,7 @@ mod tests {
                 from_address: HumanAddr::from("cosmos2contract"),
                 to_address: HumanAddr::from("creator"),
                 amount: coins(1000, "earth"),
+                deadline_height: None,
             })
         );
     }
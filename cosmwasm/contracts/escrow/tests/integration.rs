@@ -138,6 +138,7 @@ fn handle_approve() {
             from_address: HumanAddr::from("cosmos2contract"),
             to_address: HumanAddr::from("benefits"),
             amount: coins(1000, "earth"),
+            deadline_height: None,
         })
     );
 
@@ -155,6 +156,7 @@ fn handle_approve() {
             from_address: HumanAddr::from("cosmos2contract"),
             to_address: HumanAddr::from("benefits"),
             amount: coins(500, "earth"),
+            deadline_height: None,
         })
     );
 }
@@ -197,6 +199,7 @@ fn handle_refund() {
             from_address: HumanAddr::from("cosmos2contract"),
             to_address: HumanAddr::from("creator"),
             amount: coins(1000, "earth"),
+            deadline_height: None,
         })
     );
 }
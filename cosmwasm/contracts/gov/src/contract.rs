@@ -36,6 +36,7 @@ pub fn try_vote<S: Storage, A: Api, Q: Querier>(
         })],
         log: vec![],
         data: None,
+        submessages: vec![],
     })
 }
 
@@ -56,5 +57,6 @@ pub fn try_query_proposal<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![],
         data: Some(Binary::from(active_proposal.to_be_bytes().to_vec())),
+        submessages: vec![],
     })
 }
@@ -163,6 +163,7 @@ fn do_release<S: Storage, A: Api, Q: Querier>(
             from_address: env.contract.address,
             to_address: to_addr,
             amount: balance,
+            deadline_height: None,
         });
         ctx.set_data(&[0xF0, 0x0B, 0xAA]);
         Ok(ctx.into())
@@ -504,6 +505,7 @@ mod tests {
                 from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
                 to_address: beneficiary,
                 amount: coins(1000, "earth"),
+                deadline_height: None,
             }
             .into(),
         );
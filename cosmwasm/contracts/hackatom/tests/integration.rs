@@ -218,6 +218,7 @@ fn handle_release_works() {
             from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
             to_address: beneficiary,
             amount: coins(1000, "earth"),
+            deadline_height: None,
         }
         .into(),
     );
@@ -44,6 +44,7 @@ pub fn try_inflation_rate<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![],
         data: Some(Binary::from(active_proposal)),
+        submessages: vec![],
     })
 }
 
@@ -66,5 +67,6 @@ pub fn try_bonded_ratio<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![],
         data: Some(Binary::from(active_proposal)),
+        submessages: vec![],
     })
 }
@@ -26,6 +26,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
             log("ZW5jb2RlZCBsb2cK", "ZW5jb2RlZCB2YWx1ZQo="), // base64
             plaintext_log("plaintext log", "plaintext value"),
         ],
+        submessages: vec![],
     };
     Ok(response)
 }
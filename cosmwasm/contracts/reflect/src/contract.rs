@@ -49,6 +49,7 @@ pub fn try_reflect<S: Storage, A: Api, Q: Querier>(
         messages: msgs,
         log: vec![log("action", "reflect")],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -133,6 +134,7 @@ mod tests {
             from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
             to_address: HumanAddr::from("friend"),
             amount: coins(1, "token"),
+            deadline_height: None,
         }
         .into()];
 
@@ -157,6 +159,7 @@ mod tests {
             from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
             to_address: HumanAddr::from("friend"),
             amount: coins(1, "token"),
+            deadline_height: None,
         }
         .into()];
         let msg = HandleMsg::ReflectMsg {
@@ -207,6 +210,7 @@ mod tests {
                 from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
                 to_address: HumanAddr::from("friend"),
                 amount: coins(1, "token"),
+                deadline_height: None,
             }
             .into(),
             // make sure we can pass through custom native messages
@@ -87,6 +87,7 @@ fn reflect() {
             from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
             to_address: HumanAddr::from("friend"),
             amount: coins(1, "token"),
+            deadline_height: None,
         }
         .into(),
         // make sure we can pass through custom native messages
@@ -121,6 +122,7 @@ fn reflect_requires_owner() {
         from_address: HumanAddr::from(MOCK_CONTRACT_ADDR),
         to_address: HumanAddr::from("friend"),
         amount: coins(1, "token"),
+        deadline_height: None,
     }
     .into()];
     let msg = HandleMsg::ReflectMsg {
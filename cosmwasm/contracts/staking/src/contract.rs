@@ -93,6 +93,7 @@ pub fn transfer<S: Storage, A: Api, Q: Querier>(
             log("amount", send),
         ],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -181,6 +182,7 @@ pub fn bond<S: Storage, A: Api, Q: Querier>(
             log("minted", to_mint),
         ],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -250,6 +252,7 @@ pub fn unbond<S: Storage, A: Api, Q: Querier>(
             log("burnt", amount),
         ],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -291,6 +294,7 @@ pub fn claim<S: Storage, A: Api, Q: Querier>(
             from_address: env.contract.address,
             to_address: env.message.sender.clone(),
             amount: vec![balance],
+            deadline_height: None,
         }
         .into()],
         log: vec![
@@ -299,6 +303,7 @@ pub fn claim<S: Storage, A: Api, Q: Querier>(
             log("amount", to_send),
         ],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -327,11 +332,13 @@ pub fn reinvest<S: Storage, A: Api, Q: Querier>(
                 callback_code_hash: env.contract_code_hash,
                 msg,
                 send: vec![],
+                gas_limit: None,
             }
             .into(),
         ],
         log: vec![],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
@@ -375,6 +382,7 @@ pub fn _bond_all_tokens<S: Storage, A: Api, Q: Querier>(
         .into()],
         log: vec![log("action", "reinvest"), log("bonded", balance.amount)],
         data: None,
+        submessages: vec![],
     };
     Ok(res)
 }
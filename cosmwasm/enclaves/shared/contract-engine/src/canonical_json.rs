@@ -0,0 +1,156 @@
+use std::fmt;
+
+use log::*;
+use serde::de::{self, Deserialize, Deserializer, MapAccess, SeqAccess, Visitor};
+use serde_json::{Map, Value};
+
+use enclave_ffi_types::EnclaveError;
+
+/// Re-encodes a JSON msg without insignificant whitespace and with the keys of all objects sorted, so logically
+/// identical msgs have identical bytes. A msg that isn't JSON is returned unchanged, as contracts may send any bytes.
+///
+/// Objects with duplicate keys are rejected, as it's ambiguous which value the called contract would read, and so
+/// are numbers that aren't integers, which contracts can't parse and which might not be re-encoded as written.
+pub fn canonicalize_msg(msg: &[u8]) -> Result<Vec<u8>, EnclaveError> {
+    let value: CanonicalValue = match serde_json::from_slice(msg) {
+        Ok(value) => value,
+        Err(err) if err.is_syntax() || err.is_eof() => return Ok(msg.to_vec()),
+        Err(err) => {
+            warn!(
+                "got an error while trying to canonicalize a wasm msg: {}",
+                err
+            );
+            return Err(EnclaveError::FailedToDeserialize);
+        }
+    };
+
+    serde_json::to_vec(&value.0).map_err(|err| {
+        debug!(
+            "got an error while trying to serialize a canonical wasm msg: {}",
+            err
+        );
+        EnclaveError::FailedToSerialize
+    })
+}
+
+/// A JSON value that fails to deserialize on duplicate keys and non-integer numbers.
+/// The objects of serde_json are ordered by key, so serializing the inner value sorts them.
+struct CanonicalValue(Value);
+
+impl<'de> Deserialize<'de> for CanonicalValue {
+    fn deserialize<D>(deserializer: D) -> Result<Self, D::Error>
+    where
+        D: Deserializer<'de>,
+    {
+        deserializer.deserialize_any(CanonicalValueVisitor)
+    }
+}
+
+struct CanonicalValueVisitor;
+
+impl<'de> Visitor<'de> for CanonicalValueVisitor {
+    type Value = CanonicalValue;
+
+    fn expecting(&self, formatter: &mut fmt::Formatter) -> fmt::Result {
+        formatter.write_str("a JSON value")
+    }
+
+    fn visit_unit<E>(self) -> Result<Self::Value, E> {
+        Ok(CanonicalValue(Value::Null))
+    }
+
+    fn visit_bool<E>(self, v: bool) -> Result<Self::Value, E> {
+        Ok(CanonicalValue(Value::Bool(v)))
+    }
+
+    fn visit_i64<E>(self, v: i64) -> Result<Self::Value, E> {
+        Ok(CanonicalValue(Value::from(v)))
+    }
+
+    fn visit_u64<E>(self, v: u64) -> Result<Self::Value, E> {
+        Ok(CanonicalValue(Value::from(v)))
+    }
+
+    fn visit_f64<E>(self, v: f64) -> Result<Self::Value, E>
+    where
+        E: de::Error,
+    {
+        Err(E::custom(format!("the number {} is not an integer", v)))
+    }
+
+    fn visit_str<E>(self, v: &str) -> Result<Self::Value, E> {
+        Ok(CanonicalValue(Value::String(v.to_string())))
+    }
+
+    fn visit_string<E>(self, v: String) -> Result<Self::Value, E> {
+        Ok(CanonicalValue(Value::String(v)))
+    }
+
+    fn visit_seq<A>(self, mut seq: A) -> Result<Self::Value, A::Error>
+    where
+        A: SeqAccess<'de>,
+    {
+        let mut values = Vec::new();
+        while let Some(CanonicalValue(value)) = seq.next_element()? {
+            values.push(value);
+        }
+        Ok(CanonicalValue(Value::Array(values)))
+    }
+
+    fn visit_map<A>(self, mut map: A) -> Result<Self::Value, A::Error>
+    where
+        A: MapAccess<'de>,
+    {
+        let mut object = Map::new();
+        while let Some(key) = map.next_key::<String>()? {
+            if object.contains_key(&key) {
+                return Err(de::Error::custom(format!("duplicate key {:?}", key)));
+            }
+            let CanonicalValue(value) = map.next_value()?;
+            object.insert(key, value);
+        }
+        Ok(CanonicalValue(Value::Object(object)))
+    }
+}
+
+#[cfg(feature = "test")]
+pub mod tests {
+    use super::canonicalize_msg;
+
+    pub fn test_identical_msgs_are_canonicalized_identically() {
+        let canonical =
+            br#"{"transfer":{"amount":"100","memo":[1,-2,null,true],"recipient":"secret1aa"}}"#;
+        for msg in &[
+            &br#"{"transfer":{"amount":"100","memo":[1,-2,null,true],"recipient":"secret1aa"}}"#[..],
+            &br#" { "transfer" : { "amount" : "100" , "memo" : [ 1 , -2 , null , true ] , "recipient" : "secret1aa" } }
+"#[..],
+            &br#"{"transfer":{"recipient":"secret1aa","memo":[1,-2,null,true],"amount":"100"}}"#[..],
+            &br#"{"transfer":{"amount":"\u0031\u0030\u0030","memo":[1,-2,null,true],"recipient":"secret1aa"}}"#[..],
+        ] {
+            assert_eq!(canonicalize_msg(msg).unwrap(), canonical.to_vec());
+        }
+    }
+
+    pub fn test_ambiguous_msgs_are_rejected() {
+        for msg in &[
+            &br#"{"a":1,"a":2}"#[..],
+            &br#"{"a":{"b":1,"b":1}}"#[..],
+            &br#"{"a":1.0}"#[..],
+            &br#"[1e3]"#[..],
+        ] {
+            assert!(canonicalize_msg(msg).is_err());
+        }
+    }
+
+    pub fn test_msgs_that_are_not_json_are_unchanged() {
+        for msg in &[
+            &b""[..],
+            &b"banana"[..],
+            &b"{\"a\":1"[..],
+            &b"{} {}"[..],
+            &[0xff, 0xfe][..],
+        ] {
+            assert_eq!(canonicalize_msg(msg).unwrap(), msg.to_vec());
+        }
+    }
+}
@@ -15,7 +15,7 @@ use super::contract_validation::{
     verify_params, ContractKey,
 };
 use super::gas::WasmCosts;
-use super::io::encrypt_output;
+use super::io::{decrypt_reply, encrypt_output};
 use super::module_cache::create_module_instance;
 use super::types::{IoNonce, SecretMessage};
 use super::wasm::{ContractInstance, ContractOperation, Engine};
@@ -27,6 +27,7 @@ fn allocate(size: usize) -> *mut c_void;
 fn deallocate(pointer: *mut c_void);
 fn init(env_ptr: *mut c_void, msg_ptr: *mut c_void) -> *mut c_void
 fn handle(env_ptr: *mut c_void, msg_ptr: *mut c_void) -> *mut c_void
+fn reply(env_ptr: *mut c_void, msg_ptr: *mut c_void) -> *mut c_void // optional
 fn query(msg_ptr: *mut c_void) -> *mut c_void
 
 Re `init`, `handle` and `query`: We need to pass `env` & `msg`
@@ -127,6 +128,7 @@ pub fn init(
             secret_msg.nonce,
             secret_msg.user_public_key,
             &canonical_contract_address,
+            parsed_env.canonical_wasm_msgs,
         )?;
 
         Ok(output)
@@ -248,6 +250,101 @@ pub fn handle(
             secret_msg.nonce,
             secret_msg.user_public_key,
             &canonical_contract_address,
+            parsed_env.canonical_wasm_msgs,
+        )?;
+        Ok(output)
+    })
+    .map_err(|err| {
+        *used_gas = engine.gas_used();
+        err
+    })?;
+
+    *used_gas = engine.gas_used();
+    Ok(HandleSuccess { output })
+}
+
+/// Calls the `reply` export of a contract with the result of a SubMsg it sent.
+/// msg is the nonce and public key of the message that sent the SubMsg, followed by the Reply as json.
+/// The output is encrypted with the key of that message, like the output of the `handle` that sent the SubMsg.
+pub fn reply(
+    context: Ctx,
+    gas_limit: u64,
+    used_gas: &mut u64,
+    contract: &[u8],
+    env: &[u8],
+    msg: &[u8],
+) -> Result<HandleSuccess, EnclaveError> {
+    let contract_code = ContractCode::new(contract);
+
+    let mut parsed_env: Env = serde_json::from_slice(env).map_err(|err| {
+        warn!(
+            "reply got an error while trying to deserialize env input bytes into json {:?}: {}",
+            env, err
+        );
+        EnclaveError::FailedToDeserialize
+    })?;
+    parsed_env.contract_code_hash = hex::encode(contract_code.hash());
+
+    let canonical_contract_address = CanonicalAddr::from_human(&parsed_env.contract.address).map_err(|err| {
+        warn!(
+            "got an error while trying to deserialize parsed_env.contract.address from bech32 string to bytes {:?}: {}",
+            parsed_env.contract.address, err
+        );
+        EnclaveError::FailedToDeserialize
+    })?;
+
+    let contract_key = extract_contract_key(&parsed_env)?;
+
+    if !validate_contract_key(&contract_key, &canonical_contract_address, &contract_code) {
+        warn!("reply got an error while trying to validate contract key");
+        return Err(EnclaveError::FailedContractAuthentication);
+    }
+
+    trace!("reply parsed_env: {:?}", parsed_env);
+
+    // The Reply itself is plaintext, it only carries the key of the message that sent the SubMsg
+    let secret_msg = SecretMessage::from_slice(msg)?;
+    let reply = decrypt_reply(&secret_msg.msg, &secret_msg.encryption_key())?;
+
+    trace!(
+        "reply input after decryption: {:?}",
+        String::from_utf8_lossy(&reply)
+    );
+
+    let mut engine = start_engine(
+        context,
+        gas_limit,
+        contract_code,
+        &contract_key,
+        ContractOperation::Handle,
+        secret_msg.nonce,
+        secret_msg.user_public_key,
+    )?;
+
+    let new_env = serde_json::to_vec(&parsed_env).map_err(|err| {
+        warn!(
+            "got an error while trying to serialize parsed_env into bytes {:?}: {}",
+            parsed_env, err
+        );
+        EnclaveError::FailedToSerialize
+    })?;
+
+    let env_ptr = engine.write_to_memory(&new_env)?;
+    let msg_ptr = engine.write_to_memory(&reply)?;
+
+    // This wrapper is used to coalesce all errors in this block to one object
+    // so we can `.map_err()` in one place for all of them
+    let output = coalesce!(EnclaveError, {
+        let vec_ptr = engine.reply(env_ptr, msg_ptr)?;
+
+        let output = engine.extract_vector(vec_ptr)?;
+
+        let output = encrypt_output(
+            output,
+            secret_msg.nonce,
+            secret_msg.user_public_key,
+            &canonical_contract_address,
+            parsed_env.canonical_wasm_msgs,
         )?;
         Ok(output)
     })
@@ -332,6 +429,8 @@ pub fn query(
             secret_msg.nonce,
             secret_msg.user_public_key,
             &CanonicalAddr(Binary(Vec::new())), // Not used for queries (can't init a new contract from a query)
+            // Queries don't send messages
+            false,
         )?;
         Ok(output)
     })
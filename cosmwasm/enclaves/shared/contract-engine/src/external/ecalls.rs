@@ -314,6 +314,81 @@ pub unsafe extern "C" fn ecall_handle(
     }
 }
 
+/// # Safety
+/// Always use protection
+#[no_mangle]
+pub unsafe extern "C" fn ecall_reply(
+    context: Ctx,
+    gas_limit: u64,
+    used_gas: *mut u64,
+    contract: *const u8,
+    contract_len: usize,
+    env: *const u8,
+    env_len: usize,
+    msg: *const u8,
+    msg_len: usize,
+) -> HandleResult {
+    let _recursion_guard = match recursion_depth::guard() {
+        Ok(rg) => rg,
+        Err(err) => {
+            // see ecall_handle
+            error!("recursion limit exceeded, can not perform reply!");
+            return HandleResult::Failure { err };
+        }
+    };
+    if let Err(err) = oom_handler::register_oom_handler() {
+        error!("Could not register OOM handler!");
+        return HandleResult::Failure { err };
+    }
+
+    let failed_call =
+        || result_handle_success_to_handleresult(Err(EnclaveError::FailedFunctionCall));
+    validate_mut_ptr!(used_gas as _, std::mem::size_of::<u64>(), failed_call());
+    validate_const_ptr!(env, env_len as usize, failed_call());
+    validate_const_ptr!(msg, msg_len as usize, failed_call());
+    validate_const_ptr!(contract, contract_len as usize, failed_call());
+
+    let contract = std::slice::from_raw_parts(contract, contract_len);
+    let env = std::slice::from_raw_parts(env, env_len);
+    let msg = std::slice::from_raw_parts(msg, msg_len);
+    let result = panic::catch_unwind(|| {
+        let mut local_used_gas = *used_gas;
+        let result = crate::contract_operations::reply(
+            context,
+            gas_limit,
+            &mut local_used_gas,
+            contract,
+            env,
+            msg,
+        );
+        *used_gas = local_used_gas;
+        result_handle_success_to_handleresult(result)
+    });
+
+    if let Err(err) = oom_handler::restore_safety_buffer() {
+        error!("Could not restore OOM safety buffer!");
+        return HandleResult::Failure { err };
+    }
+
+    if let Ok(res) = result {
+        res
+    } else {
+        *used_gas = gas_limit / 2;
+
+        if oom_handler::get_then_clear_oom_happened() {
+            error!("Call ecall_reply failed because the enclave ran out of memory!");
+            HandleResult::Failure {
+                err: EnclaveError::OutOfMemory,
+            }
+        } else {
+            error!("Call ecall_reply panicked unexpectedly!");
+            HandleResult::Failure {
+                err: EnclaveError::Panic,
+            }
+        }
+    }
+}
+
 /// # Safety
 /// Always use protection
 #[cfg(not(feature = "query-only"))]
@@ -10,9 +10,12 @@ use sha2::Digest;
 use enclave_ffi_types::EnclaveError;
 
 use enclave_cosmwasm_types::encoding::Binary;
-use enclave_cosmwasm_types::types::{CanonicalAddr, Coin, CosmosMsg, WasmMsg, WasmOutput};
+use enclave_cosmwasm_types::types::{
+    AuthzMsg, CanonicalAddr, Coin, CosmosMsg, Reply, SubcallResult, WasmMsg, WasmOutput,
+};
 use enclave_crypto::{AESKey, Ed25519PublicKey, Kdf, SIVEncryptable, KEY_MANAGER};
 
+use super::canonical_json::canonicalize_msg;
 use super::types::{IoNonce, SecretMessage};
 
 pub fn calc_encryption_key(nonce: &IoNonce, user_public_key: &Ed25519PublicKey) -> AESKey {
@@ -73,6 +76,7 @@ pub fn encrypt_output(
     nonce: IoNonce,
     user_public_key: Ed25519PublicKey,
     contract_addr: &CanonicalAddr,
+    canonical_wasm_msgs: bool,
 ) -> Result<Vec<u8>, EnclaveError> {
     let key = calc_encryption_key(&nonce, &user_public_key);
 
@@ -102,9 +106,23 @@ pub fn encrypt_output(
         // Encrypt all Wasm messages (keeps Bank, Staking, etc.. as is)
         WasmOutput::OkObject { ok } => {
             for msg in &mut ok.messages {
-                if let CosmosMsg::Wasm(wasm_msg) = msg {
-                    encrypt_wasm_msg(wasm_msg, nonce, user_public_key, contract_addr)?;
-                }
+                encrypt_cosmos_msg(
+                    msg,
+                    nonce,
+                    user_public_key,
+                    contract_addr,
+                    canonical_wasm_msgs,
+                )?;
+            }
+
+            for sub_msg in &mut ok.submessages {
+                encrypt_cosmos_msg(
+                    &mut sub_msg.msg,
+                    nonce,
+                    user_public_key,
+                    contract_addr,
+                    canonical_wasm_msgs,
+                )?;
             }
 
             for log in ok.log.iter_mut().filter(|log| log.encrypted) {
@@ -131,11 +149,93 @@ pub fn encrypt_output(
     Ok(encrypted_output)
 }
 
+/// Parses a Reply and decrypts the data of a successful SubMsg with the key of the message that sent it.
+/// A contract called by the SubMsg encrypted its data with the same key in encrypt_output, so the contract
+/// gets it back in plaintext. Data that doesn't decrypt, like the data of a non-Wasm message, is passed as is.
+pub fn decrypt_reply(reply: &[u8], key: &AESKey) -> Result<Vec<u8>, EnclaveError> {
+    let mut reply: Reply = serde_json::from_slice(reply).map_err(|err| {
+        warn!(
+            "got an error while trying to deserialize reply bytes into json {:?}: {}",
+            String::from_utf8_lossy(reply),
+            err
+        );
+        EnclaveError::FailedToDeserialize
+    })?;
+
+    if let SubcallResult::Ok(response) = &mut reply.result {
+        if let Some(data) = &mut response.data {
+            let decrypted = key
+                .decrypt_siv(data.as_slice(), None)
+                .ok()
+                .and_then(|b64| Binary::from_base64(&String::from_utf8_lossy(&b64)).ok());
+            if let Some(decrypted) = decrypted {
+                *data = decrypted;
+            }
+        }
+    }
+
+    serde_json::to_vec(&reply).map_err(|err| {
+        debug!(
+            "got an error while trying to serialize reply json into bytes {:?}: {}",
+            reply, err
+        );
+        EnclaveError::FailedToSerialize
+    })
+}
+
+/// Encrypts the Wasm messages in msg, including the ones an Authz Exec sends
+fn encrypt_cosmos_msg(
+    msg: &mut CosmosMsg,
+    nonce: IoNonce,
+    user_public_key: Ed25519PublicKey,
+    contract_addr: &CanonicalAddr,
+    canonical_msgs: bool,
+) -> Result<(), EnclaveError> {
+    match msg {
+        CosmosMsg::Wasm(wasm_msg) => {
+            encrypt_wasm_msg(
+                wasm_msg,
+                nonce,
+                user_public_key,
+                contract_addr,
+                canonical_msgs,
+            )?;
+        }
+        // The messages of an exec are sent by the granter, so that's who their callback signatures are for
+        CosmosMsg::Authz(AuthzMsg::Exec { granter, msgs }) => {
+            let granter_addr = CanonicalAddr::from_human(granter).map_err(|err| {
+                warn!(
+                    "got an error while trying to deserialize authz exec granter {:?}: {}",
+                    granter, err
+                );
+                EnclaveError::FailedToDeserialize
+            })?;
+            for inner_msg in msgs {
+                encrypt_cosmos_msg(
+                    inner_msg,
+                    nonce,
+                    user_public_key,
+                    &granter_addr,
+                    canonical_msgs,
+                )?;
+            }
+        }
+        _ => {}
+    }
+
+    Ok(())
+}
+
+/// Encrypts the msg of wasm_msg for the contract it calls, and signs it for the contract that sends it.
+/// With canonical_msgs set, a JSON msg is canonicalized first, so the ciphertext and the signature of logically
+/// identical msgs are the same. The called contract then gets the canonical bytes rather than the ones the caller
+/// sent, which matters to a contract that hashes or verifies its raw msg.
 fn encrypt_wasm_msg(
     wasm_msg: &mut WasmMsg,
     nonce: IoNonce,
     user_public_key: Ed25519PublicKey,
     contract_addr: &CanonicalAddr,
+    canonical_msgs: bool,
 ) -> Result<(), EnclaveError> {
     match wasm_msg {
         WasmMsg::Execute {
@@ -151,7 +251,18 @@ fn encrypt_wasm_msg(
             callback_sig,
             send,
             ..
+        }
+        | WasmMsg::Instantiate2 {
+            msg,
+            callback_code_hash,
+            callback_sig,
+            send,
+            ..
         } => {
+            if canonical_msgs {
+                *msg = Binary(canonicalize_msg(msg.as_slice())?);
+            }
+
             let mut hash_appended_msg = callback_code_hash.as_bytes().to_vec();
             hash_appended_msg.extend_from_slice(msg.as_slice());
 
@@ -166,6 +277,8 @@ fn encrypt_wasm_msg(
 
             *callback_sig = Some(create_callback_signature(contract_addr, &msg_to_pass, send));
         }
+        // the code is stored in plaintext
+        WasmMsg::StoreCode { .. } => {}
     }
 
     Ok(())
@@ -189,3 +302,90 @@ pub fn create_callback_signature(
 
     sha2::Sha256::digest(callback_sig_bytes.as_slice()).to_vec()
 }
+
+#[cfg(feature = "test")]
+pub mod tests {
+    use enclave_cosmwasm_types::encoding::Binary;
+    use enclave_cosmwasm_types::types::{ContractResult, Reply, ReplyOn, SubMsg, SubcallResult};
+    use enclave_crypto::AESKey;
+
+    use super::{decrypt_reply, encrypt_serializable};
+
+    /// The output of a contract with every message go-cosmwasm/types knows, which the enclave must pass on unchanged
+    const EVERY_MSG: &str = r#"{"messages":[
+    {"bank":{"send":{"from_address":"secret1aa","to_address":"secret1bb","amount":[{"denom":"uscrt","amount":"1"}],"deadline_height":100}}},
+    {"bank":{"send":{"from_address":"secret1aa","to_address":"secret1bb","amount":[]}}},
+    {"bank":{"burn":{"amount":[{"denom":"uscrt","amount":"2"}]}}},
+    {"bank":{"multi_send":{"inputs":[{"address":"secret1aa","coins":[{"denom":"uscrt","amount":"3"}]}],"outputs":[{"address":"secret1bb","coins":[{"denom":"uscrt","amount":"3"}]}]}}},
+    {"staking":{"withdraw":{"validator":"secretvaloper1cc","recipient":null}}},
+    {"gov":{"vote":{"proposal":1,"vote_option":"Yes"}}},
+    {"gov":{"submit_proposal":{"title":"t","description":"d","initial_deposit":[{"denom":"uscrt","amount":"4"}]}}},
+    {"ibc":{"transfer":{"source_port":"custom","channel_id":"channel-0","to_address":"cosmos1dd","amount":{"denom":"uscrt","amount":"5"},"timeout":{"block":{"revision":1,"height":2},"timestamp":3,"relative_blocks":4}}}},
+    {"ibc":{"transfer":{"channel_id":"channel-0","to_address":"cosmos1dd","amount":{"denom":"uscrt","amount":"5"},"timeout":{"relative_blocks":4}}}},
+    {"distribution":{"set_withdraw_address":{"address":"secret1bb"}}},
+    {"distribution":{"fund_community_pool":{"amount":[{"denom":"uscrt","amount":"6"}]}}},
+    {"distribution":{"withdraw_validator_commission":{"validator":"secretvaloper1cc"}}},
+    {"stargate":{"type_url":"/cosmos.bank.v1beta1.MsgSend","value":"CgRhYmNk"}},
+    {"vesting":{"create_vesting_account":{"to":"secret1bb","amount":[{"denom":"uscrt","amount":"7"}],"end_time":1700000000,"delayed":true}}},
+    {"feegrant":{"grant_allowance":{"grantee":"secret1bb","allowance":{"basic":{"spend_limit":[],"expiration":0}}}}},
+    {"feegrant":{"grant_allowance":{"grantee":"secret1bb","allowance":{"periodic":{"basic":{"spend_limit":[{"denom":"uscrt","amount":"8"}],"expiration":1700000000},"period":60,"period_spend_limit":[{"denom":"uscrt","amount":"1"}]}}}}},
+    {"authz":{"exec":{"granter":"secret1aa","msgs":[{"bank":{"send":{"from_address":"secret1aa","to_address":"secret1bb","amount":[]}}}]}}},
+    {"slashing":{"unjail":{"validator":"secretvaloper1cc"}}},
+    {"wasm":{"execute":{"contract_addr":"secret1ee","callback_code_hash":"ab","msg":"e30=","send":[],"callback_sig":null,"gas_limit":50000}}},
+    {"wasm":{"instantiate":{"code_id":1,"callback_code_hash":"ab","msg":"e30=","send":[],"label":"l","callback_sig":null}}},
+    {"wasm":{"store_code":{"wasm_bytes":"AGFzbQ==","source":"https://example.com","builder":"enigmampc/secret-contract-optimizer:1.0.5"}}},
+    {"wasm":{"instantiate2":{"code_id":1,"callback_code_hash":"ab","msg":"e30=","send":[],"label":"l","callback_sig":null,"salt":"c2FsdA=="}}}
+],"submessages":[
+    {"id":1,"msg":{"bank":{"burn":{"amount":[]}}},"gas_limit":10,"reply_on":"always"},
+    {"id":2,"msg":{"bank":{"burn":{"amount":[]}}},"reply_on":"never"}
+],"log":[],"data":null}"#;
+
+    pub fn test_every_msg_round_trips() {
+        let input: serde_json::Value = serde_json::from_str(EVERY_MSG).unwrap();
+        let parsed: ContractResult = serde_json::from_value(input.clone()).unwrap();
+        assert_eq!(serde_json::to_value(&parsed).unwrap(), input);
+    }
+
+    pub fn test_output_without_submessages_is_unchanged() {
+        let output = r#"{"messages":[],"log":[],"data":null}"#;
+        let parsed: ContractResult = serde_json::from_str(output).unwrap();
+        assert_eq!(serde_json::to_string(&parsed).unwrap(), output);
+
+        let sub_msg: SubMsg =
+            serde_json::from_str(r#"{"id":1,"msg":{"bank":{"burn":{"amount":[]}}}}"#).unwrap();
+        assert_eq!(sub_msg.reply_on, ReplyOn::Never);
+    }
+
+    pub fn test_reply_data_is_decrypted() {
+        let key = AESKey::new_from_slice(&[7u8; 32]);
+        // as encrypt_output encrypts the data of the contract the SubMsg executed
+        let encrypted =
+            Binary::from_base64(&encrypt_serializable(&key, &Binary(b"banana".to_vec())).unwrap())
+                .unwrap();
+        let reply = format!(
+            r#"{{"id":1,"result":{{"ok":{{"events":[],"data":"{}"}}}}}}"#,
+            encrypted.to_base64()
+        );
+
+        let reply: Reply =
+            serde_json::from_slice(&decrypt_reply(reply.as_bytes(), &key).unwrap()).unwrap();
+        match reply.result {
+            SubcallResult::Ok(response) => {
+                assert_eq!(response.data, Some(Binary(b"banana".to_vec())))
+            }
+            SubcallResult::Err(err) => panic!("unexpected error {}", err),
+        }
+    }
+
+    pub fn test_reply_data_that_does_not_decrypt_is_unchanged() {
+        let key = AESKey::new_from_slice(&[7u8; 32]);
+        for reply in &[
+            r#"{"id":2,"result":{"ok":{"events":[{"type":"t","attributes":[{"key":"k","value":"v"}]}],"data":"YmFuYW5h"}}}"#,
+            r#"{"id":3,"result":{"ok":{"events":[],"data":null}}}"#,
+            r#"{"id":4,"result":{"error":"boom"}}"#,
+        ] {
+            let decrypted = decrypt_reply(reply.as_bytes(), &key).unwrap();
+            assert_eq!(std::str::from_utf8(&decrypted).unwrap(), *reply);
+        }
+    }
+}
@@ -4,6 +4,7 @@ extern crate sgx_tstd as std;
 
 extern crate sgx_types;
 
+mod canonical_json;
 mod contract_operations;
 mod contract_validation;
 mod db;
@@ -21,7 +22,7 @@ pub use contract_operations::{handle, init, query};
 
 #[cfg(feature = "test")]
 pub mod tests {
-    use crate::types;
+    use crate::{canonical_json, io, types};
 
     /// Catch failures like the standard test runner, and print similar information per test.
     /// Tests can only fail by panicking, not by returning a `Result` type.
@@ -47,6 +48,13 @@ pub mod tests {
 
         count_failures!(failures, {
             types::tests::test_new_from_slice();
+            canonical_json::tests::test_identical_msgs_are_canonicalized_identically();
+            canonical_json::tests::test_ambiguous_msgs_are_rejected();
+            canonical_json::tests::test_msgs_that_are_not_json_are_unchanged();
+            io::tests::test_every_msg_round_trips();
+            io::tests::test_output_without_submessages_is_unchanged();
+            io::tests::test_reply_data_is_decrypted();
+            io::tests::test_reply_data_that_does_not_decrypt_is_unchanged();
         });
 
         if failures != 0 {
@@ -121,6 +121,29 @@ impl Engine {
         }
     }
 
+    pub fn reply(&mut self, env_ptr: u32, msg_ptr: u32) -> Result<u32, EnclaveError> {
+        info!("Invoking reply() in wasm");
+
+        match self
+            .module
+            .invoke_export(
+                "reply",
+                &[
+                    RuntimeValue::I32(env_ptr as i32),
+                    RuntimeValue::I32(msg_ptr as i32),
+                ],
+                &mut self.contract_instance,
+            )
+            .map_err(wasmi_error_to_enclave_error)?
+        {
+            Some(RuntimeValue::I32(offset)) => Ok(offset as u32),
+            other => {
+                warn!("reply method returned value which wasn't u32: {:?}", other);
+                Err(EnclaveError::FailedFunctionCall)
+            }
+        }
+    }
+
     pub fn query(&mut self, msg_ptr: u32) -> Result<u32, EnclaveError> {
         info!("Invoking query() in wasm");
 
@@ -101,6 +101,10 @@ pub struct Env {
     pub contract_key: Option<String>,
     #[serde(default)]
     pub contract_code_hash: String,
+    /// Set by the chain to canonicalize the msg of the Wasm messages the contract sends, see encrypt_wasm_msg.
+    /// It's not passed on to the contract.
+    #[serde(default, skip_serializing)]
+    pub canonical_wasm_msgs: bool,
 }
 
 #[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq)]
@@ -153,10 +157,77 @@ pub enum WasmOutput {
 #[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
 pub struct ContractResult {
     pub messages: Vec<CosmosMsg>,
+    /// skipped when empty, so the output of a contract that doesn't use submessages is unchanged
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub submessages: Vec<SubMsg>,
     pub log: Vec<LogAttribute>,
     pub data: Option<Binary>,
 }
 
+/// A SubMsg is a CosmosMsg the contract can ask to be called back with the result of, in its `reply` export
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct SubMsg<T = CustomMsg>
+where
+    T: Clone + fmt::Debug + PartialEq,
+{
+    pub id: u64,
+    pub msg: CosmosMsg<T>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub gas_limit: Option<u64>,
+    #[serde(default)]
+    pub reply_on: ReplyOn,
+}
+
+#[derive(Serialize, Deserialize, Clone, Copy, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum ReplyOn {
+    Always,
+    Success,
+    Error,
+    Never,
+}
+
+impl Default for ReplyOn {
+    fn default() -> Self {
+        ReplyOn::Never
+    }
+}
+
+// This should be in correlation with cosmwasm-std/init_handle's Reply
+/// The result of a SubMsg, which the chain passes to the `reply` export of the contract that sent it
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct Reply {
+    pub id: u64,
+    pub result: SubcallResult,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum SubcallResult {
+    Ok(SubcallResponse),
+    #[serde(rename = "error")]
+    Err(String),
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct SubcallResponse {
+    pub events: Vec<Event>,
+    pub data: Option<Binary>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct Event {
+    #[serde(rename = "type")]
+    pub kind: String,
+    pub attributes: Vec<Attribute>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct Attribute {
+    pub key: String,
+    pub value: String,
+}
+
 #[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
 #[serde(rename_all = "snake_case")]
 // This should be in correlation with cosmwasm-std/init_handle's CosmosMsg
@@ -172,6 +243,17 @@ where
     Staking(StakingMsg),
     Wasm(WasmMsg),
     Gov(GovMsg),
+    Ibc(IbcMsg),
+    Distribution(DistributionMsg),
+    /// a protobuf encoded sdk.Msg, see go-cosmwasm/types.StargateMsg
+    Stargate {
+        type_url: String,
+        value: Binary,
+    },
+    Vesting(VestingMsg),
+    Feegrant(FeegrantMsg),
+    Authz(AuthzMsg<T>),
+    Slashing(SlashingMsg),
 }
 
 /// Added this here for reflect tests....
@@ -197,6 +279,11 @@ pub enum GovMsg {
         proposal: u64,
         vote_option: VoteOption,
     },
+    SubmitProposal {
+        title: String,
+        description: String,
+        initial_deposit: Vec<Coin>,
+    },
 }
 
 #[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
@@ -215,9 +302,135 @@ pub enum BankMsg {
         from_address: HumanAddr,
         to_address: HumanAddr,
         amount: Vec<Coin>,
+        /// the last block height the send may be executed at
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        deadline_height: Option<u64>,
+    },
+    Burn {
+        amount: Vec<Coin>,
+    },
+    MultiSend {
+        inputs: Vec<BankInput>,
+        outputs: Vec<BankOutput>,
+    },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct BankInput {
+    pub address: HumanAddr,
+    pub coins: Vec<Coin>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct BankOutput {
+    pub address: HumanAddr,
+    pub coins: Vec<Coin>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum IbcMsg {
+    /// an ICS-20 token transfer from the contract to to_address on the other side of channel_id
+    Transfer {
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        source_port: Option<String>,
+        channel_id: String,
+        to_address: String,
+        amount: Coin,
+        timeout: IbcTimeout,
+    },
+}
+
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq)]
+pub struct IbcTimeout {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub block: Option<IbcTimeoutBlock>,
+    /// nanoseconds since the unix epoch
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub timestamp: Option<u64>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub relative_blocks: Option<u64>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq)]
+pub struct IbcTimeoutBlock {
+    pub revision: u64,
+    pub height: u64,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum DistributionMsg {
+    SetWithdrawAddress { address: HumanAddr },
+    FundCommunityPool { amount: Vec<Coin> },
+    WithdrawValidatorCommission { validator: HumanAddr },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum VestingMsg {
+    CreateVestingAccount {
+        to: HumanAddr,
+        amount: Vec<Coin>,
+        /// seconds since the unix epoch
+        end_time: i64,
+        #[serde(default)]
+        delayed: bool,
+    },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum FeegrantMsg {
+    GrantAllowance {
+        grantee: HumanAddr,
+        allowance: FeeAllowance,
+    },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum FeeAllowance {
+    Basic(BasicAllowance),
+    Periodic(PeriodicAllowance),
+}
+
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq)]
+pub struct BasicAllowance {
+    #[serde(default)]
+    pub spend_limit: Vec<Coin>,
+    /// seconds since the unix epoch, 0 for no expiration
+    #[serde(default)]
+    pub expiration: i64,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+pub struct PeriodicAllowance {
+    pub basic: BasicAllowance,
+    /// seconds
+    pub period: u64,
+    pub period_spend_limit: Vec<Coin>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum AuthzMsg<T = CustomMsg>
+where
+    T: Clone + fmt::Debug + PartialEq,
+{
+    /// executes msgs as granter, which must have authorized the contract to send them
+    Exec {
+        granter: HumanAddr,
+        msgs: Vec<CosmosMsg<T>>,
     },
 }
 
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
+#[serde(rename_all = "snake_case")]
+pub enum SlashingMsg {
+    Unjail { validator: HumanAddr },
+}
+
 #[derive(Serialize, Deserialize, Clone, Debug, PartialEq)]
 #[serde(rename_all = "snake_case")]
 pub enum StakingMsg {
@@ -259,6 +472,9 @@ pub enum WasmMsg {
         msg: Binary,
         send: Vec<Coin>,
         callback_sig: Option<Vec<u8>>,
+        /// the most gas the called contract may use
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        gas_limit: Option<u64>,
     },
     /// this instantiates a new contracts from previously uploaded wasm code
     Instantiate {
@@ -274,6 +490,25 @@ pub enum WasmMsg {
         label: String,
         callback_sig: Option<Vec<u8>>,
     },
+    /// this uploads new wasm code, with the contract as its creator
+    StoreCode {
+        wasm_bytes: Binary,
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        source: Option<String>,
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        builder: Option<String>,
+    },
+    /// this is Instantiate at an address derived from the code, the contract and salt
+    Instantiate2 {
+        code_id: u64,
+        callback_code_hash: String,
+        msg: Binary,
+        send: Vec<Coin>,
+        #[serde(default)]
+        label: String,
+        callback_sig: Option<Vec<u8>>,
+        salt: Binary,
+    },
 }
 
 impl<T: Clone + fmt::Debug + PartialEq> From<GovMsg> for CosmosMsg<T> {
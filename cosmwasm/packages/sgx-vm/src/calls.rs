@@ -120,6 +120,17 @@ pub fn call_handle_raw<S: Storage + 'static, A: Api + 'static, Q: Querier + 'sta
     instance.call_handle(env, msg, sig_info)
 }
 
+/// Calls Wasm export "reply" with the result of a submessage and returns raw data from the contract.
+/// The result is length limited to prevent abuse but otherwise unchecked.
+pub fn call_reply_raw<S: Storage + 'static, A: Api + 'static, Q: Querier + 'static>(
+    instance: &mut Instance<S, A, Q>,
+    env: &[u8],
+    msg: &[u8],
+) -> VmResult<Vec<u8>> {
+    instance.set_storage_readonly(false);
+    instance.call_reply(env, msg)
+}
+
 /// Calls Wasm export "migrate" and returns raw data from the contract.
 /// The result is length limited to prevent abuse but otherwise unchecked.
 pub fn call_migrate_raw<S: Storage + 'static, A: Api + 'static, Q: Querier + 'static>(
@@ -336,6 +336,11 @@ where
         Ok(init_result.into_output())
     }
 
+    pub fn call_reply(&mut self, env: &[u8], msg: &[u8]) -> VmResult<Vec<u8>> {
+        let reply_result = self.inner.reply(env, msg)?;
+        Ok(reply_result.into_output())
+    }
+
     pub fn call_migrate(&mut self, _env: &[u8], _msg: &[u8]) -> VmResult<Vec<u8>> {
         Ok(Vec::new())
     }
@@ -28,7 +28,9 @@ mod wasmi;
 pub mod enclave_tests;
 
 pub use crate::cache::CosmCache;
-pub use crate::calls::{call_handle_raw, call_init_raw, call_migrate_raw, call_query_raw};
+pub use crate::calls::{
+    call_handle_raw, call_init_raw, call_migrate_raw, call_query_raw, call_reply_raw,
+};
 pub use crate::checksum::Checksum;
 pub use crate::errors::{
     CommunicationError, CommunicationResult, RegionValidationError, RegionValidationResult,
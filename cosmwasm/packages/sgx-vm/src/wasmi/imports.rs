@@ -50,6 +50,21 @@ extern "C" {
         sig_info: *const u8,
         sig_info_len: usize,
     ) -> sgx_status_t;
+
+    /// Trigger the reply method in a wasm contract
+    pub fn ecall_reply(
+        eid: sgx_enclave_id_t,
+        retval: *mut HandleResult,
+        context: Ctx,
+        gas_limit: u64,
+        used_gas: *mut u64,
+        contract: *const u8,
+        contract_len: usize,
+        env: *const u8,
+        env_len: usize,
+        msg: *const u8,
+        msg_len: usize,
+    ) -> sgx_status_t;
 }
 
 #[cfg(not(feature = "query-node"))]
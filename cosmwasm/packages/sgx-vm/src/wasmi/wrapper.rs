@@ -196,6 +196,56 @@ where
         }
     }
 
+    pub fn reply(&mut self, env: &[u8], msg: &[u8]) -> VmResult<HandleSuccess> {
+        trace!(
+            "reply() called with env: {:?} msg: {:?} gas_left: {}",
+            String::from_utf8_lossy(env),
+            String::from_utf8_lossy(msg),
+            self.gas_left()
+        );
+
+        let mut reply_result = MaybeUninit::<HandleResult>::uninit();
+        let mut used_gas = 0_u64;
+
+        // Bind the token to a local variable to ensure its
+        // destructor runs in the end of the function
+        let enclave_access_token = ENCLAVE_DOORBELL
+            .get_access(false) // This can never be recursive
+            .ok_or_else(Self::busy_enclave_err)?;
+        let enclave = enclave_access_token.map_err(EnclaveError::sdk_err)?;
+
+        let status = unsafe {
+            imports::ecall_reply(
+                enclave.geteid(),
+                reply_result.as_mut_ptr(),
+                self.ctx.unsafe_clone(),
+                self.gas_left(),
+                &mut used_gas,
+                self.bytecode.as_ptr(),
+                self.bytecode.len(),
+                env.as_ptr(),
+                env.len(),
+                msg.as_ptr(),
+                msg.len(),
+            )
+        };
+
+        trace!(
+            "reply() returned with gas_used: {} (gas_limit: {})",
+            used_gas,
+            self.gas_limit
+        );
+        self.consume_gas(used_gas);
+
+        match status {
+            sgx_status_t::SGX_SUCCESS => {
+                let reply_result = unsafe { reply_result.assume_init() };
+                handle_result_to_vm_result(reply_result)
+            }
+            failure_status => Err(EnclaveError::sdk_err(failure_status).into()),
+        }
+    }
+
     pub fn query(&mut self, env: &[u8], msg: &[u8]) -> VmResult<QuerySuccess> {
         trace!(
             "query() called with env: {:?} msg: {:?}",
@@ -16,7 +16,7 @@ use crate::imports::{ExternalApi, ExternalQuerier, ExternalStorage};
 use crate::memory::{alloc, consume_region, release_buffer, Region};
 use crate::serde::{from_slice, to_vec};
 use crate::traits::Extern;
-use crate::{Env, HandleResult, InitResult, MigrateResult, QueryResponse, QueryResult};
+use crate::{Env, HandleResult, InitResult, MigrateResult, QueryResponse, QueryResult, Reply};
 
 #[cfg(feature = "staking")]
 #[no_mangle]
@@ -83,6 +83,25 @@ where
     release_buffer(v) as u32
 }
 
+/// do_reply should be wrapped in an external "C" export named "reply", containing a contract-specific function as arg.
+/// The export is optional, it is only called for submessages that ask for a reply.
+pub fn do_reply<U>(
+    reply_fn: &dyn Fn(
+        &mut Extern<ExternalStorage, ExternalApi, ExternalQuerier>,
+        Env,
+        Reply,
+    ) -> HandleResult<U>,
+    env_ptr: u32,
+    msg_ptr: u32,
+) -> u32
+where
+    U: Serialize + Clone + fmt::Debug + PartialEq + JsonSchema,
+{
+    let res: HandleResult<U> = _do_reply(reply_fn, env_ptr as *mut Region, msg_ptr as *mut Region);
+    let v = to_vec(&res).unwrap();
+    release_buffer(v) as u32
+}
+
 /// do_query should be wrapped in an external "C" export, containing a contract-specific function as arg
 pub fn do_query<T: DeserializeOwned + JsonSchema>(
     query_fn: &dyn Fn(
@@ -159,6 +178,27 @@ where
     handle_fn(&mut deps, env, msg)
 }
 
+fn _do_reply<U>(
+    reply_fn: &dyn Fn(
+        &mut Extern<ExternalStorage, ExternalApi, ExternalQuerier>,
+        Env,
+        Reply,
+    ) -> HandleResult<U>,
+    env_ptr: *mut Region,
+    msg_ptr: *mut Region,
+) -> HandleResult<U>
+where
+    U: Serialize + Clone + fmt::Debug + PartialEq + JsonSchema,
+{
+    let env: Vec<u8> = unsafe { consume_region(env_ptr) };
+    let msg: Vec<u8> = unsafe { consume_region(msg_ptr) };
+
+    let env: Env = from_slice(&env)?;
+    let msg: Reply = from_slice(&msg)?;
+    let mut deps = make_dependencies();
+    reply_fn(&mut deps, env, msg)
+}
+
 fn _do_query<T: DeserializeOwned + JsonSchema>(
     query_fn: &dyn Fn(
         &Extern<ExternalStorage, ExternalApi, ExternalQuerier>,
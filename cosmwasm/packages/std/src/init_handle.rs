@@ -25,6 +25,18 @@ where
     Staking(StakingMsg),
     Wasm(WasmMsg),
     Gov(GovMsg),
+    Ibc(IbcMsg),
+    Distribution(DistributionMsg),
+    /// A protobuf encoded sdk.Msg, for the messages that don't have a variant here.
+    /// type_url is the type of the message (e.g. "/cosmos.bank.v1beta1.MsgSend") and value is its protobuf encoding.
+    Stargate {
+        type_url: String,
+        value: Binary,
+    },
+    Vesting(VestingMsg),
+    Feegrant(FeegrantMsg),
+    Authz(AuthzMsg<T>),
+    Slashing(SlashingMsg),
 }
 
 #[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
@@ -35,6 +47,12 @@ pub enum GovMsg {
         proposal: u64,
         vote_option: VoteOption,
     },
+    /// Submit a text proposal, funded with initial_deposit from the contract
+    SubmitProposal {
+        title: String,
+        description: String,
+        initial_deposit: Vec<Coin>,
+    },
 }
 
 #[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
@@ -54,9 +72,150 @@ pub enum BankMsg {
         from_address: HumanAddr,
         to_address: HumanAddr,
         amount: Vec<Coin>,
+        /// the last block height the send may be executed at, if any
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        deadline_height: Option<u64>,
+    },
+    /// this permanently removes the tokens from the contract's balance and from the total supply
+    Burn { amount: Vec<Coin> },
+    /// this sends tokens to many recipients at once. The inputs must add up to the outputs for every denom,
+    /// and the contract can only send its own tokens.
+    MultiSend {
+        inputs: Vec<BankInput>,
+        outputs: Vec<BankOutput>,
     },
 }
 
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+pub struct BankInput {
+    pub address: HumanAddr,
+    pub coins: Vec<Coin>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+pub struct BankOutput {
+    pub address: HumanAddr,
+    pub coins: Vec<Coin>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum IbcMsg {
+    /// an ICS-20 token transfer from the contract to to_address on the other side of channel_id
+    Transfer {
+        /// the port the tokens are sent from, the port of the transfer module if None
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        source_port: Option<String>,
+        channel_id: String,
+        to_address: String,
+        amount: Coin,
+        timeout: IbcTimeout,
+    },
+}
+
+/// At least one of the fields must be set. relative_blocks can't be combined with block.
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq, JsonSchema)]
+pub struct IbcTimeout {
+    /// a block height on the other chain
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub block: Option<IbcTimeoutBlock>,
+    /// nanoseconds since the unix epoch
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub timestamp: Option<u64>,
+    /// a number of blocks after the current height of this chain
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub relative_blocks: Option<u64>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq, JsonSchema)]
+pub struct IbcTimeoutBlock {
+    pub revision: u64,
+    pub height: u64,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum DistributionMsg {
+    /// this changes the address the staking rewards of the contract are sent to
+    SetWithdrawAddress { address: HumanAddr },
+    /// this sends tokens from the contract to the community pool
+    FundCommunityPool { amount: Vec<Coin> },
+    /// this withdraws the commission of a validator the contract operates
+    WithdrawValidatorCommission { validator: HumanAddr },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum VestingMsg {
+    /// this creates a new account at to, funded by the contract with amount, which vests until end_time
+    CreateVestingAccount {
+        to: HumanAddr,
+        amount: Vec<Coin>,
+        /// seconds since the unix epoch
+        end_time: i64,
+        /// vest all of amount at end_time, instead of continuously until then
+        #[serde(default)]
+        delayed: bool,
+    },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum FeegrantMsg {
+    /// this lets grantee pay its transaction fees from the balance of the contract
+    GrantAllowance {
+        grantee: HumanAddr,
+        allowance: FeeAllowance,
+    },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum FeeAllowance {
+    Basic(BasicAllowance),
+    Periodic(PeriodicAllowance),
+}
+
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq, JsonSchema)]
+pub struct BasicAllowance {
+    /// the most the grantee may spend on fees in total, empty for no limit
+    #[serde(default)]
+    pub spend_limit: Vec<Coin>,
+    /// seconds since the unix epoch, 0 for no expiration
+    #[serde(default)]
+    pub expiration: i64,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+pub struct PeriodicAllowance {
+    pub basic: BasicAllowance,
+    /// the length of a period in seconds
+    pub period: u64,
+    /// the most the grantee may spend on fees in a period
+    pub period_spend_limit: Vec<Coin>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum AuthzMsg<T = Empty>
+where
+    T: Clone + fmt::Debug + PartialEq + JsonSchema,
+{
+    /// this sends msgs as granter, which must have authorized the contract to send them.
+    /// msgs can't contain another Authz message.
+    Exec {
+        granter: HumanAddr,
+        msgs: Vec<CosmosMsg<T>>,
+    },
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum SlashingMsg {
+    /// this unjails a validator the contract operates
+    Unjail { validator: HumanAddr },
+}
+
 #[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
 #[serde(rename_all = "snake_case")]
 pub enum StakingMsg {
@@ -97,6 +256,9 @@ pub enum WasmMsg {
         /// msg is the json-encoded HandleMsg struct (as raw Binary)
         msg: Binary,
         send: Vec<Coin>,
+        /// the most gas the called contract may use, all the gas that is left if None
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        gas_limit: Option<u64>,
     },
     /// this instantiates a new contracts from previously uploaded wasm code
     Instantiate {
@@ -110,6 +272,30 @@ pub enum WasmMsg {
         /// mandatory human-readbale label for the contract
         label: String,
     },
+    /// this uploads new wasm code, with the contract as its creator
+    StoreCode {
+        /// the wasm byte code, raw or gzip compressed
+        wasm_bytes: Binary,
+        /// an absolute HTTPS URI to the source code of the contract
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        source: Option<String>,
+        /// the docker image with tag that built the code
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        builder: Option<String>,
+    },
+    /// this is Instantiate, but the address of the new contract is derived from the code hash of code_id,
+    /// the contract and salt, so it is known before the contract exists
+    Instantiate2 {
+        code_id: u64,
+        callback_code_hash: String,
+        /// msg is the json-encoded InitMsg struct (as raw Binary)
+        msg: Binary,
+        send: Vec<Coin>,
+        /// mandatory human-readbale label for the contract
+        label: String,
+        /// up to 64 arbitrary bytes
+        salt: Binary,
+    },
 }
 
 impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<GovMsg> for CosmosMsg<T> {
@@ -137,6 +323,117 @@ impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<WasmMsg> for CosmosMsg
     }
 }
 
+impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<IbcMsg> for CosmosMsg<T> {
+    fn from(msg: IbcMsg) -> Self {
+        CosmosMsg::Ibc(msg)
+    }
+}
+
+impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<DistributionMsg> for CosmosMsg<T> {
+    fn from(msg: DistributionMsg) -> Self {
+        CosmosMsg::Distribution(msg)
+    }
+}
+
+impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<VestingMsg> for CosmosMsg<T> {
+    fn from(msg: VestingMsg) -> Self {
+        CosmosMsg::Vesting(msg)
+    }
+}
+
+impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<FeegrantMsg> for CosmosMsg<T> {
+    fn from(msg: FeegrantMsg) -> Self {
+        CosmosMsg::Feegrant(msg)
+    }
+}
+
+impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<AuthzMsg<T>> for CosmosMsg<T> {
+    fn from(msg: AuthzMsg<T>) -> Self {
+        CosmosMsg::Authz(msg)
+    }
+}
+
+impl<T: Clone + fmt::Debug + PartialEq + JsonSchema> From<SlashingMsg> for CosmosMsg<T> {
+    fn from(msg: SlashingMsg) -> Self {
+        CosmosMsg::Slashing(msg)
+    }
+}
+
+/// A SubMsg is a message the contract is called back with the result of, in its `reply` export,
+/// as set by reply_on. Unlike for messages, an error of a SubMsg that asks for a reply on error
+/// doesn't fail the contract call, only its state changes are reverted.
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+pub struct SubMsg<T = Empty>
+where
+    T: Clone + fmt::Debug + PartialEq + JsonSchema,
+{
+    /// echoed back in the Reply, so the contract can tell which SubMsg it is for
+    pub id: u64,
+    pub msg: CosmosMsg<T>,
+    /// the most gas the message may use. Running out only fails the SubMsg, not the contract call
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub gas_limit: Option<u64>,
+    #[serde(default)]
+    pub reply_on: ReplyOn,
+}
+
+#[derive(Serialize, Deserialize, Clone, Copy, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum ReplyOn {
+    /// call back with the result on success and on error
+    Always,
+    /// call back only on success, an error fails the contract call
+    Success,
+    /// call back only on error
+    Error,
+    /// never call back, the SubMsg is dispatched like a message
+    Never,
+}
+
+impl Default for ReplyOn {
+    fn default() -> Self {
+        ReplyOn::Never
+    }
+}
+
+/// The result of a SubMsg, passed to the `reply` export of the contract that dispatched it
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+pub struct Reply {
+    pub id: u64,
+    pub result: SubcallResult,
+}
+
+#[derive(Serialize, Deserialize, Clone, Debug, PartialEq, JsonSchema)]
+#[serde(rename_all = "snake_case")]
+pub enum SubcallResult {
+    Ok(SubcallResponse),
+    /// the error of the SubMsg, as a string
+    #[serde(rename = "error")]
+    Err(String),
+}
+
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq, JsonSchema)]
+pub struct SubcallResponse {
+    /// the events the chain emitted while executing the SubMsg
+    pub events: Vec<Event>,
+    /// the data of the SubMsg, decrypted if a contract call of this transaction returned it
+    pub data: Option<Binary>,
+}
+
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq, JsonSchema)]
+pub struct Event {
+    #[serde(rename = "type")]
+    pub kind: String,
+    pub attributes: Vec<Attribute>,
+}
+
+/// An attribute of an Event. Unlike a LogAttribute, the chain already decided whether to encrypt it.
+#[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq, JsonSchema)]
+pub struct Attribute {
+    pub key: String,
+    pub value: String,
+}
+
 #[derive(Serialize, Deserialize, Clone, Default, Debug, PartialEq, JsonSchema)]
 pub struct LogAttribute {
     pub key: String,
@@ -169,6 +466,9 @@ where
 {
     pub messages: Vec<CosmosMsg<T>>,
     pub log: Vec<LogAttribute>,
+    /// dispatched in order after the messages
+    #[serde(default = "Vec::new", skip_serializing_if = "Vec::is_empty")]
+    pub submessages: Vec<SubMsg<T>>,
 }
 
 pub type InitResult<U = Empty> = StdResult<InitResponse<U>>;
@@ -181,6 +481,7 @@ where
         InitResponse {
             messages: vec![],
             log: vec![],
+            submessages: vec![],
         }
     }
 }
@@ -200,6 +501,7 @@ where
             Ok(InitResponse {
                 messages: ctx.messages,
                 log: ctx.log,
+                submessages: vec![],
             })
         }
     }
@@ -213,6 +515,9 @@ where
     pub messages: Vec<CosmosMsg<T>>,
     pub log: Vec<LogAttribute>,
     pub data: Option<Binary>,
+    /// dispatched in order after the messages. The data of the last reply that set data replaces data.
+    #[serde(default = "Vec::new", skip_serializing_if = "Vec::is_empty")]
+    pub submessages: Vec<SubMsg<T>>,
 }
 
 pub type HandleResult<U = Empty> = StdResult<HandleResponse<U>>;
@@ -226,6 +531,7 @@ where
             messages: vec![],
             log: vec![],
             data: None,
+            submessages: vec![],
         }
     }
 }
@@ -239,6 +545,7 @@ where
             messages: ctx.messages,
             log: ctx.log,
             data: ctx.data,
+            submessages: vec![],
         }
     }
 }
@@ -368,6 +675,7 @@ mod test {
                 from_address: HumanAddr("me".to_string()),
                 to_address: HumanAddr("you".to_string()),
                 amount: coins(1015, "earth"),
+                deadline_height: None,
             }
             .into()],
             log: vec![LogAttribute {
@@ -375,6 +683,7 @@ mod test {
                 value: "release".to_string(),
                 encrypted: true,
             }],
+            submessages: vec![],
         });
         let bin = to_vec(&send).expect("encode contract result");
         println!("ok: {}", std::str::from_utf8(&bin).unwrap());
@@ -382,6 +691,187 @@ mod test {
         assert_eq!(send, back);
     }
 
+    #[test]
+    fn optional_fields_are_omitted() {
+        let send: CosmosMsg = BankMsg::Send {
+            from_address: HumanAddr::from("me"),
+            to_address: HumanAddr::from("you"),
+            amount: coins(1015, "earth"),
+            deadline_height: None,
+        }
+        .into();
+        assert_eq!(
+            std::str::from_utf8(&to_vec(&send).unwrap()).unwrap(),
+            r#"{"bank":{"send":{"from_address":"me","to_address":"you","amount":[{"denom":"earth","amount":"1015"}]}}}"#
+        );
+
+        let execute: CosmosMsg = WasmMsg::Execute {
+            contract_addr: HumanAddr::from("contract"),
+            callback_code_hash: "".to_string(),
+            msg: Binary::from(b"{}"),
+            send: vec![],
+            gas_limit: Some(100_000),
+        }
+        .into();
+        let bin = to_vec(&execute).unwrap();
+        assert!(std::str::from_utf8(&bin)
+            .unwrap()
+            .contains(r#""gas_limit":100000"#));
+        let back: CosmosMsg = from_slice(&bin).unwrap();
+        assert_eq!(execute, back);
+    }
+
+    #[test]
+    fn submessages_are_omitted_when_empty() {
+        let handle: HandleResponse = HandleResponse::default();
+        assert_eq!(
+            std::str::from_utf8(&to_vec(&handle).unwrap()).unwrap(),
+            r#"{"messages":[],"log":[],"data":null}"#
+        );
+
+        let handle: HandleResponse = HandleResponse {
+            submessages: vec![SubMsg {
+                id: 7,
+                msg: BankMsg::Burn {
+                    amount: coins(5, "earth"),
+                }
+                .into(),
+                gas_limit: None,
+                reply_on: ReplyOn::Error,
+            }],
+            ..HandleResponse::default()
+        };
+        let bin = to_vec(&handle).unwrap();
+        assert!(std::str::from_utf8(&bin)
+            .unwrap()
+            .contains(r#""submessages":[{"id":7,"msg":{"bank":{"burn":{"amount":[{"denom":"earth","amount":"5"}]}}},"reply_on":"error"}]"#));
+        let back: HandleResponse = from_slice(&bin).unwrap();
+        assert_eq!(handle, back);
+
+        // reply_on is never if unset
+        let sub: SubMsg = from_slice(br#"{"id":1,"msg":{"bank":{"burn":{"amount":[]}}}}"#).unwrap();
+        assert_eq!(sub.reply_on, ReplyOn::Never);
+    }
+
+    #[test]
+    fn can_deser_reply() {
+        // as the chain sends them
+        let ok: Reply = from_slice(br#"{"id":3,"result":{"ok":{"events":[{"type":"transfer","attributes":[{"key":"amount","value":"5earth"}]}],"data":"YmFuYW5h"}}}"#).unwrap();
+        assert_eq!(
+            ok,
+            Reply {
+                id: 3,
+                result: SubcallResult::Ok(SubcallResponse {
+                    events: vec![Event {
+                        kind: "transfer".to_string(),
+                        attributes: vec![Attribute {
+                            key: "amount".to_string(),
+                            value: "5earth".to_string(),
+                        }],
+                    }],
+                    data: Some(Binary::from(b"banana")),
+                }),
+            }
+        );
+
+        let no_data: Reply = from_slice(br#"{"id":4,"result":{"ok":{"events":[]}}}"#).unwrap();
+        assert_eq!(
+            no_data.result,
+            SubcallResult::Ok(SubcallResponse::default())
+        );
+
+        let err: Reply = from_slice(br#"{"id":5,"result":{"error":"out of gas"}}"#).unwrap();
+        assert_eq!(err.result, SubcallResult::Err("out of gas".to_string()));
+    }
+
+    #[test]
+    fn can_deser_every_msg() {
+        let msgs: Vec<CosmosMsg> = vec![
+            BankMsg::Burn {
+                amount: coins(10, "earth"),
+            }
+            .into(),
+            BankMsg::MultiSend {
+                inputs: vec![BankInput {
+                    address: HumanAddr::from("me"),
+                    coins: coins(10, "earth"),
+                }],
+                outputs: vec![BankOutput {
+                    address: HumanAddr::from("you"),
+                    coins: coins(10, "earth"),
+                }],
+            }
+            .into(),
+            IbcMsg::Transfer {
+                source_port: None,
+                channel_id: "channel-0".to_string(),
+                to_address: "you".to_string(),
+                amount: Coin::new(10, "earth"),
+                timeout: IbcTimeout {
+                    relative_blocks: Some(100),
+                    ..IbcTimeout::default()
+                },
+            }
+            .into(),
+            DistributionMsg::FundCommunityPool {
+                amount: coins(10, "earth"),
+            }
+            .into(),
+            CosmosMsg::Stargate {
+                type_url: "/cosmos.bank.v1beta1.MsgSend".to_string(),
+                value: Binary::from(b"\x0a\x02me"),
+            },
+            VestingMsg::CreateVestingAccount {
+                to: HumanAddr::from("you"),
+                amount: coins(10, "earth"),
+                end_time: 1_700_000_000,
+                delayed: true,
+            }
+            .into(),
+            FeegrantMsg::GrantAllowance {
+                grantee: HumanAddr::from("you"),
+                allowance: FeeAllowance::Periodic(PeriodicAllowance {
+                    basic: BasicAllowance::default(),
+                    period: 3600,
+                    period_spend_limit: coins(10, "earth"),
+                }),
+            }
+            .into(),
+            AuthzMsg::Exec {
+                granter: HumanAddr::from("granter"),
+                msgs: vec![SlashingMsg::Unjail {
+                    validator: HumanAddr::from("validator"),
+                }
+                .into()],
+            }
+            .into(),
+            GovMsg::SubmitProposal {
+                title: "title".to_string(),
+                description: "description".to_string(),
+                initial_deposit: coins(10, "earth"),
+            }
+            .into(),
+            WasmMsg::StoreCode {
+                wasm_bytes: Binary::from(b"\0asm"),
+                source: None,
+                builder: None,
+            }
+            .into(),
+            WasmMsg::Instantiate2 {
+                code_id: 1,
+                callback_code_hash: "".to_string(),
+                msg: Binary::from(b"{}"),
+                send: vec![],
+                label: "label".to_string(),
+                salt: Binary::from(b"salt"),
+            }
+            .into(),
+        ];
+        let bin = to_vec(&msgs).expect("encode messages");
+        let back: Vec<CosmosMsg> = from_slice(&bin).expect("decode messages");
+        assert_eq!(msgs, back);
+    }
+
     #[test]
     fn msg_from_works() {
         let from_address = HumanAddr("me".to_string());
@@ -391,6 +881,7 @@ mod test {
             from_address,
             to_address,
             amount,
+            deadline_height: None,
         };
         let msg: CosmosMsg = bank.clone().into();
         match msg {
@@ -424,6 +915,7 @@ mod test {
             from_address: HumanAddr::from("goo"),
             to_address: HumanAddr::from("foo"),
             amount: coins(128, "uint"),
+            deadline_height: None,
         });
 
         // and this is what is should return
@@ -432,6 +924,7 @@ mod test {
             from_address: HumanAddr::from("goo"),
             to_address: HumanAddr::from("foo"),
             amount: coins(128, "uint"),
+            deadline_height: None,
         })];
         let expected_data = Some(Binary::from(b"banana"));
 
@@ -22,9 +22,11 @@ pub use crate::debug_print::debug_print;
 pub use crate::encoding::Binary;
 pub use crate::errors::{StdError, StdResult, SystemError, SystemResult};
 pub use crate::init_handle::{
-    log, plaintext_log, BankMsg, Context, CosmosMsg, GovMsg, HandleResponse, HandleResult,
-    InitResponse, InitResult, LogAttribute, MigrateResponse, MigrateResult, StakingMsg, VoteOption,
-    WasmMsg,
+    log, plaintext_log, Attribute, AuthzMsg, BankInput, BankMsg, BankOutput, BasicAllowance,
+    Context, CosmosMsg, DistributionMsg, Event, FeeAllowance, FeegrantMsg, GovMsg, HandleResponse,
+    HandleResult, IbcMsg, IbcTimeout, IbcTimeoutBlock, InitResponse, InitResult, LogAttribute,
+    MigrateResponse, MigrateResult, PeriodicAllowance, Reply, ReplyOn, SlashingMsg, StakingMsg,
+    SubMsg, SubcallResponse, SubcallResult, VestingMsg, VoteOption, WasmMsg,
 };
 #[cfg(feature = "iterator")]
 pub use crate::iterator::{Order, KV};
@@ -50,7 +52,7 @@ mod imports;
 mod memory; // Used by exports and imports only. This assumes pointers are 32 bit long, which makes it untestable on dev machines.
 
 #[cfg(target_arch = "wasm32")]
-pub use crate::exports::{do_handle, do_init, do_migrate, do_query};
+pub use crate::exports::{do_handle, do_init, do_migrate, do_query, do_reply};
 #[cfg(target_arch = "wasm32")]
 pub use crate::imports::{ExternalApi, ExternalQuerier, ExternalStorage};
 
@@ -213,6 +213,49 @@ func Handle(
 	return receiveVector(res), uint64(gasUsed), nil
 }
 
+// Reply calls the reply export of a contract with the result of a submessage it dispatched
+func Reply(
+	cache Cache,
+	code_id []byte,
+	params []byte,
+	msg []byte,
+	gasMeter *GasMeter,
+	store KVStore,
+	api *GoAPI,
+	querier *Querier,
+	gasLimit uint64,
+) ([]byte, uint64, error) {
+	id := sendSlice(code_id)
+	defer freeAfterSend(id)
+	p := sendSlice(params)
+	defer freeAfterSend(p)
+	m := sendSlice(msg)
+	defer freeAfterSend(m)
+
+	// set up a new stack frame to handle iterators
+	counter := startContract()
+	defer endContract(counter)
+
+	dbState := buildDBState(store, counter)
+	db := buildDB(&dbState, gasMeter)
+	a := buildAPI(api)
+	q := buildQuerier(querier)
+	var gasUsed u64
+	errmsg := C.Buffer{}
+
+	// This is done in order to ensure that goroutines don't
+	// swap threads between recursive calls to the enclave.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	res, err := C.reply(cache.ptr, id, p, m, db, a, q, u64(gasLimit), &gasUsed, &errmsg)
+	if err != nil && err.(syscall.Errno) != C.ErrnoValue_Success {
+		// Depending on the nature of the error, `gasUsed` will either have a meaningful value, or just 0.
+		return nil, uint64(gasUsed), errorWithMessage(err, errmsg)
+	}
+	return receiveVector(res), uint64(gasUsed), nil
+}
+
 func Migrate(
 	cache Cache,
 	code_id []byte,
@@ -170,6 +170,20 @@ func Handle(
 	return nil, 0, nil
 }
 
+func Reply(
+	cache Cache,
+	code_id []byte,
+	params []byte,
+	msg []byte,
+	gasMeter *GasMeter,
+	store KVStore,
+	api *GoAPI,
+	querier *Querier,
+	gasLimit uint64,
+) ([]byte, uint64, error) {
+	return nil, 0, nil
+}
+
 func Migrate(
 	cache Cache,
 	code_id []byte,
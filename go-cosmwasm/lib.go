@@ -173,6 +173,42 @@ func (w *Wasmer) Execute(
 	return resp.Ok, gasUsed, nil
 }
 
+// Reply calls a given contract back with the result of a submessage it dispatched. replyMsg is the nonce and
+// public key of the message that returned the submessage, followed by the json of the types.Reply, so the
+// enclave can decrypt the data of the submessage and encrypt the response for the same user.
+func (w *Wasmer) Reply(
+	code CodeID,
+	env types.Env,
+	replyMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.HandleResponse, uint64, error) {
+	paramBin, err := json.Marshal(env)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, gasUsed, err := api.Reply(w.cache, code, paramBin, replyMsg, &gasMeter, store, &goapi, &querier, gasLimit)
+	if err != nil {
+		return nil, gasUsed, err
+	}
+
+	var resp types.HandleResult
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		return nil, gasUsed, err
+	}
+
+	if resp.Err != nil {
+		return nil, gasUsed, fmt.Errorf("%v", resp.Err)
+	}
+
+	return resp.Ok, gasUsed, nil
+}
+
 // Query allows a client to execute a contract-specific query. If the result is not empty, it should be
 // valid json-encoded data to return to the client.
 // The meaning of path and data can be determined by the code. Path is the suffix of the abci.QueryRequest.Path
@@ -19,8 +19,8 @@ use crate::error::{clear_error, handle_c_error, set_error, Error};
 
 use cosmwasm_sgx_vm::untrusted_init_bootstrap;
 use cosmwasm_sgx_vm::{
-    call_handle_raw, call_init_raw, call_migrate_raw, call_query_raw, features_from_csv, Checksum,
-    CosmCache, Extern,
+    call_handle_raw, call_init_raw, call_migrate_raw, call_query_raw, call_reply_raw,
+    features_from_csv, Checksum, CosmCache, Extern,
 };
 use cosmwasm_sgx_vm::{
     create_attestation_report_u, untrusted_get_encrypted_seed, untrusted_health_check,
@@ -447,6 +447,59 @@ fn do_handle(
     Ok(res?)
 }
 
+#[no_mangle]
+pub extern "C" fn reply(
+    cache: *mut cache_t,
+    code_id: Buffer,
+    params: Buffer,
+    msg: Buffer,
+    db: DB,
+    api: GoApi,
+    querier: GoQuerier,
+    gas_limit: u64,
+    gas_used: Option<&mut u64>,
+    err: Option<&mut Buffer>,
+) -> Buffer {
+    let r = match to_cache(cache) {
+        Some(c) => catch_unwind(AssertUnwindSafe(move || {
+            do_reply(
+                c, code_id, params, msg, db, api, querier, gas_limit, gas_used,
+            )
+        }))
+        .unwrap_or_else(|_| Err(Error::panic())),
+        None => Err(Error::empty_arg(CACHE_ARG)),
+    };
+    let data = handle_c_error(r, err);
+    Buffer::from_vec(data)
+}
+
+fn do_reply(
+    cache: &mut CosmCache<DB, GoApi, GoQuerier>,
+    code_id: Buffer,
+    params: Buffer,
+    msg: Buffer,
+    db: DB,
+    api: GoApi,
+    querier: GoQuerier,
+    gas_limit: u64,
+    gas_used: Option<&mut u64>,
+) -> Result<Vec<u8>, Error> {
+    let gas_used = gas_used.ok_or_else(|| Error::empty_arg(GAS_USED_ARG))?;
+    let code_id: Checksum = unsafe { code_id.read() }
+        .ok_or_else(|| Error::empty_arg(CODE_ID_ARG))?
+        .try_into()?;
+    let params = unsafe { params.read() }.ok_or_else(|| Error::empty_arg(PARAMS_ARG))?;
+    let msg = unsafe { msg.read() }.ok_or_else(|| Error::empty_arg(MSG_ARG))?;
+
+    let deps = to_extern(db, api, querier);
+    let mut instance = cache.get_instance(&code_id, deps, gas_limit)?;
+    // We only check this result after reporting gas usage and returning the instance into the cache.
+    let res = call_reply_raw(&mut instance, params, msg);
+    *gas_used = instance.create_gas_report().used_internally;
+    instance.recycle();
+    Ok(res?)
+}
+
 #[no_mangle]
 pub extern "C" fn migrate(
     cache: *mut cache_t,
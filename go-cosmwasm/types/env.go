@@ -13,6 +13,9 @@ type Env struct {
 	Contract  ContractInfo `json:"contract"`
 	Key       ContractKey  `json:"contract_key"`
 	Recursive bool         `json:"recursive"`
+	// CanonicalWasmMsgs makes the enclave canonicalize the Msg of the Wasm messages the contract sends before
+	// encrypting them. It's read by the enclave and isn't passed on to the contract.
+	CanonicalWasmMsgs bool `json:"canonical_wasm_msgs,omitempty"`
 }
 
 type ContractKey string
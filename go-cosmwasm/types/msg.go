@@ -16,6 +16,8 @@ type HandleResult struct {
 type HandleResponse struct {
 	// Messages comes directly from the contract and is it's request for action
 	Messages []CosmosMsg `json:"messages"`
+	// Submessages are like Messages, but the contract can ask to be called back with their result
+	Submessages []SubMsg `json:"submessages,omitempty"`
 	// base64-encoded bytes to return as ABCI.Data field
 	Data []byte `json:"data"`
 	// log message to return over abci interface
@@ -32,6 +34,8 @@ type InitResult struct {
 type InitResponse struct {
 	// Messages comes directly from the contract and is it's request for action
 	Messages []CosmosMsg `json:"messages"`
+	// Submessages are like Messages, but the contract can ask to be called back with their result
+	Submessages []SubMsg `json:"submessages,omitempty"`
 	// log message to return over abci interface
 	Log []LogAttribute `json:"log"`
 }
@@ -61,19 +65,107 @@ type LogAttribute struct {
 // CosmosMsg is an rust enum and only (exactly) one of the fields should be set
 // Should we do a cleaner approach in Go? (type/data?)
 type CosmosMsg struct {
-	Bank    *BankMsg        `json:"bank,omitempty"`
-	Custom  json.RawMessage `json:"custom,omitempty"`
-	Staking *StakingMsg     `json:"staking,omitempty"`
-	Wasm    *WasmMsg        `json:"wasm,omitempty"`
-	Gov     *GovMsg         `json:"gov,omitempty"`
+	Bank         *BankMsg         `json:"bank,omitempty"`
+	Custom       json.RawMessage  `json:"custom,omitempty"`
+	Staking      *StakingMsg      `json:"staking,omitempty"`
+	Wasm         *WasmMsg         `json:"wasm,omitempty"`
+	Gov          *GovMsg          `json:"gov,omitempty"`
+	IBC          *IBCMsg          `json:"ibc,omitempty"`
+	Distribution *DistributionMsg `json:"distribution,omitempty"`
+	Stargate     *StargateMsg     `json:"stargate,omitempty"`
+	Vesting      *VestingMsg      `json:"vesting,omitempty"`
+	Feegrant     *FeegrantMsg     `json:"feegrant,omitempty"`
+	Authz        *AuthzMsg        `json:"authz,omitempty"`
+	Slashing     *SlashingMsg     `json:"slashing,omitempty"`
+}
+
+// StargateMsg is encoded the same way as a protobuf [Any](https://github.com/protocolbuffers/protobuf/blob/master/src/google/protobuf/any.proto).
+// This is the same structure as messages in `TxBody` from [ADR-020](https://github.com/cosmos/cosmos-sdk/blob/master/docs/architecture/adr-020-protobuf-transaction-encoding.md)
+type StargateMsg struct {
+	TypeURL string `json:"type_url"`
+	Value   []byte `json:"value"`
 }
 
 type BankMsg struct {
-	Send *SendMsg `json:"send,omitempty"`
+	Send      *SendMsg      `json:"send,omitempty"`
+	Burn      *BurnMsg      `json:"burn,omitempty"`
+	MultiSend *MultiSendMsg `json:"multi_send,omitempty"`
+}
+
+// BurnMsg permanently removes the given coins from the contract's balance and from the total supply
+type BurnMsg struct {
+	Amount Coins `json:"amount"`
+}
+
+// MultiSendMsg sends coins from the inputs to the outputs in a single message, e.g. to pay many recipients at once.
+// For each denom, the inputs must add up to the same amount as the outputs.
+type MultiSendMsg struct {
+	Inputs  []BankInput  `json:"inputs"`
+	Outputs []BankOutput `json:"outputs"`
+}
+
+// BankInput is an address coins are sent from. Only the contract itself can be an input.
+type BankInput struct {
+	Address string `json:"address"`
+	Coins   Coins  `json:"coins"`
+}
+
+// BankOutput is an address coins are sent to
+type BankOutput struct {
+	Address string `json:"address"`
+	Coins   Coins  `json:"coins"`
+}
+
+type IBCMsg struct {
+	Transfer *TransferMsg `json:"transfer,omitempty"`
+}
+
+// TransferMsg contains instructions for an ICS-20 token transfer over an IBC channel
+// It has a fixed interface here and should be converted into the proper SDK format before dispatching
+type TransferMsg struct {
+	// SourcePort is the port on this chain the tokens are sent from, by default the port of the transfer module.
+	// Another port must be bound, e.g. by an IBC middleware.
+	SourcePort string `json:"source_port,omitempty"`
+	// ChannelID is the id of the channel on this chain the tokens are sent over
+	ChannelID string `json:"channel_id"`
+	// ToAddress is the recipient address on the remote chain
+	ToAddress string `json:"to_address"`
+	Amount    Coin   `json:"amount"`
+	// Timeout must have at least one of the block height or timestamp set
+	Timeout IBCTimeout `json:"timeout"`
+}
+
+// IBCTimeout is the timeout for an IBC packet. At least one of the fields must be set
+type IBCTimeout struct {
+	Block *IBCTimeoutBlock `json:"block,omitempty"`
+	// Nanoseconds since UNIX epoch
+	Timestamp uint64 `json:"timestamp,omitempty"`
+	// RelativeBlocks is a timeout height this many blocks after the current height of this chain, in its revision.
+	// It can't be combined with Block
+	RelativeBlocks uint64 `json:"relative_blocks,omitempty"`
+}
+
+// IBCTimeoutBlock is a block height on the remote chain, after which the packet times out
+type IBCTimeoutBlock struct {
+	// the version that the client is currently on
+	// (eg. after resetting the chain this could increment 1 as height drops to 0)
+	Revision uint64 `json:"revision"`
+	// block height after which the packet times out.
+	// the height within the given revision
+	Height uint64 `json:"height"`
 }
 
 type GovMsg struct {
-	Vote *VoteMsg `json:"vote,omitempty"`
+	Vote           *VoteMsg           `json:"vote,omitempty"`
+	SubmitProposal *SubmitProposalMsg `json:"submit_proposal,omitempty"`
+}
+
+// SubmitProposalMsg contains instructions for a Cosmos-SDK/GovSubmitProposal of a text proposal
+// It has a fixed interface here and should be converted into the proper SDK format before dispatching
+type SubmitProposalMsg struct {
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	InitialDeposit Coins  `json:"initial_deposit"`
 }
 
 // VoteMsg contains instructions for a Cosmos-SDK/GovVote
@@ -96,6 +188,8 @@ type SendMsg struct {
 	FromAddress string `json:"from_address"`
 	ToAddress   string `json:"to_address"`
 	Amount      Coins  `json:"amount"`
+	// DeadlineHeight is the last block height the send may be executed at, 0 for no deadline
+	DeadlineHeight uint64 `json:"deadline_height,omitempty"`
 }
 
 type StakingMsg struct {
@@ -127,9 +221,105 @@ type WithdrawMsg struct {
 	Recipient string `json:"recipient,omitempty"`
 }
 
+type DistributionMsg struct {
+	SetWithdrawAddress          *SetWithdrawAddressMsg          `json:"set_withdraw_address,omitempty"`
+	FundCommunityPool           *FundCommunityPoolMsg           `json:"fund_community_pool,omitempty"`
+	WithdrawValidatorCommission *WithdrawValidatorCommissionMsg `json:"withdraw_validator_commission,omitempty"`
+}
+
+// SetWithdrawAddressMsg changes the address the contract's staking rewards are sent to
+// It has a fixed interface here and should be converted into the proper SDK format before dispatching
+type SetWithdrawAddressMsg struct {
+	// Address is the new withdraw address of the contract (the delegator)
+	Address string `json:"address"`
+}
+
+// FundCommunityPoolMsg sends coins from the contract to the community pool
+type FundCommunityPoolMsg struct {
+	// Amount is the list of coins to donate
+	Amount Coins `json:"amount"`
+}
+
+// WithdrawValidatorCommissionMsg withdraws the commission of a validator that is operated by the contract
+type WithdrawValidatorCommissionMsg struct {
+	// Validator is the operator address of the validator, which must be the contract itself
+	Validator string `json:"validator"`
+}
+
+type VestingMsg struct {
+	CreateVestingAccount *CreateVestingAccountMsg `json:"create_vesting_account,omitempty"`
+}
+
+// CreateVestingAccountMsg creates a new account at To, funded by the contract with Amount that vests until EndTime
+type CreateVestingAccountMsg struct {
+	// To is the address of the new account, which must not exist yet
+	To     string `json:"to"`
+	Amount Coins  `json:"amount"`
+	// EndTime is when all of Amount has vested, in seconds since the unix epoch. It must be in the future.
+	EndTime int64 `json:"end_time"`
+	// Delayed vests all of Amount at EndTime, instead of continuously until then
+	Delayed bool `json:"delayed"`
+}
+
+type SlashingMsg struct {
+	Unjail *UnjailMsg `json:"unjail,omitempty"`
+}
+
+// UnjailMsg unjails a validator after its downtime jail period has passed
+type UnjailMsg struct {
+	// Validator is the operator address of the validator, which must be the contract itself
+	Validator string `json:"validator"`
+}
+
+type AuthzMsg struct {
+	Exec *AuthzExecMsg `json:"exec,omitempty"`
+}
+
+// AuthzExecMsg executes Msgs on behalf of Granter, which must have authorized the contract to send them
+type AuthzExecMsg struct {
+	Granter string `json:"granter"`
+	// Msgs are encoded as if Granter sent them. They can't contain another Authz message.
+	Msgs []CosmosMsg `json:"msgs"`
+}
+
+type FeegrantMsg struct {
+	GrantAllowance *GrantAllowanceMsg `json:"grant_allowance,omitempty"`
+}
+
+// GrantAllowanceMsg lets Grantee pay its transaction fees from the balance of the contract, up to the Allowance
+type GrantAllowanceMsg struct {
+	Grantee   string       `json:"grantee"`
+	Allowance FeeAllowance `json:"allowance"`
+}
+
+// FeeAllowance must have exactly one of its fields set
+type FeeAllowance struct {
+	Basic    *BasicAllowance    `json:"basic,omitempty"`
+	Periodic *PeriodicAllowance `json:"periodic,omitempty"`
+}
+
+type BasicAllowance struct {
+	// SpendLimit is the most the grantee may spend on fees in total, empty for no limit
+	SpendLimit Coins `json:"spend_limit"`
+	// Expiration is when the allowance expires, in seconds since the unix epoch. 0 means it never expires.
+	Expiration int64 `json:"expiration"`
+}
+
+// PeriodicAllowance is a BasicAllowance that also limits how much the grantee may spend in each period
+type PeriodicAllowance struct {
+	Basic BasicAllowance `json:"basic"`
+	// Period is the length of a period in seconds
+	Period uint64 `json:"period"`
+	// PeriodSpendLimit is the most the grantee may spend on fees in a period
+	PeriodSpendLimit Coins `json:"period_spend_limit"`
+}
+
 type WasmMsg struct {
 	Execute     *ExecuteMsg     `json:"execute,omitempty"`
 	Instantiate *InstantiateMsg `json:"instantiate,omitempty"`
+	StoreCode   *StoreCodeMsg   `json:"store_code,omitempty"`
+	// Instantiate2 is like Instantiate, but the address of the new contract is derived from Salt
+	Instantiate2 *Instantiate2Msg `json:"instantiate2,omitempty"`
 }
 
 // ExecuteMsg is used to call another defined contract on this chain.
@@ -152,6 +342,8 @@ type ExecuteMsg struct {
 	// Send is an optional amount of coins this contract sends to the called contract
 	Send              Coins  `json:"send"`
 	CallbackSignature []byte `json:"callback_sig"` // Optional
+	// GasLimit is the most gas the called contract may use. Leave empty to forward all remaining gas.
+	GasLimit *uint64 `json:"gas_limit,omitempty"`
 }
 
 type InstantiateMsg struct {
@@ -169,3 +361,33 @@ type InstantiateMsg struct {
 	Send              Coins  `json:"send"`
 	CallbackSignature []byte `json:"callback_sig"` // Optional
 }
+
+// Instantiate2Msg instantiates a contract at an address derived from the code hash of CodeID, the sending
+// contract and Salt, so it is known before the contract exists.
+type Instantiate2Msg struct {
+	// CodeID is the reference to the wasm byte code as used by the Cosmos-SDK
+	CodeID uint64 `json:"code_id"`
+	// Custom addition to support binding a message to specific code to harden against offline & replay attacks
+	// This is only needed when creating a callback message
+	CallbackCodeHash string `json:"callback_code_hash"`
+	// Msg is assumed to be a json-encoded message, which will be passed directly
+	// as `userMsg` when calling `Handle` on the above-defined contract
+	Msg []byte `json:"msg"`
+	/// Label is a mandatory human-readbale label for the contract
+	Label string `json:"label"`
+	// Send is an optional amount of coins this contract sends to the called contract
+	Send              Coins  `json:"send"`
+	CallbackSignature []byte `json:"callback_sig"` // Optional
+	// Salt is an arbitrary value of up to 64 bytes the address of the contract is derived from
+	Salt []byte `json:"salt"`
+}
+
+// StoreCodeMsg uploads new wasm code, which is stored with the sending contract as its creator.
+type StoreCodeMsg struct {
+	// WasmBytes is the wasm byte code, which can be raw or gzip compressed
+	WasmBytes []byte `json:"wasm_bytes"`
+	// Source is an optional absolute HTTPS URI to the source code of the contract
+	Source string `json:"source,omitempty"`
+	// Builder is an optional docker image name with tag that built the code
+	Builder string `json:"builder,omitempty"`
+}
@@ -67,11 +67,14 @@ type QueryRequest struct {
 	Dist    *DistQuery      `json:"dist,omitempty"`
 	Mint    *MintQuery      `json:"mint,omitempty"`
 	Gov     *GovQuery       `json:"gov,omitempty"`
+	Env     *EnvQuery       `json:"env,omitempty"`
 }
 
 type BankQuery struct {
 	Balance     *BalanceQuery     `json:"balance,omitempty"`
 	AllBalances *AllBalancesQuery `json:"all_balances,omitempty"`
+	SupplyOf    *SupplyOfQuery    `json:"supply_of,omitempty"`
+	SendEnabled *SendEnabledQuery `json:"send_enabled,omitempty"`
 }
 
 type BalanceQuery struct {
@@ -84,24 +87,60 @@ type BalanceResponse struct {
 	Amount Coin `json:"amount"`
 }
 
+// SupplyOfQuery returns the total supply of a denom on the chain
+type SupplyOfQuery struct {
+	Denom string `json:"denom"`
+}
+
+// SupplyOfResponse is the expected response to SupplyOfQuery
+type SupplyOfResponse struct {
+	Amount Coin `json:"amount"`
+}
+
+// SendEnabledQuery returns whether coins of the denoms can be sent, at most 100 at once
+type SendEnabledQuery struct {
+	Denoms []string `json:"denoms"`
+}
+
+// SendEnabledResponse is the expected response to SendEnabledQuery, in the order of the queried denoms
+type SendEnabledResponse struct {
+	SendEnabled []DenomSendEnabled `json:"send_enabled"`
+}
+
+// DenomSendEnabled is whether coins of Denom can be sent
+type DenomSendEnabled struct {
+	Denom   string `json:"denom"`
+	Enabled bool   `json:"enabled"`
+}
+
 type AllBalancesQuery struct {
 	Address string `json:"address"`
+	// StartAfter is the denom to continue after, as returned in AllBalancesResponse.NextKey. Optional
+	StartAfter string `json:"start_after,omitempty"`
+	// Limit is the most coins to return. Optional, it's capped by the node
+	Limit uint32 `json:"limit,omitempty"`
 }
 
 // AllBalancesResponse is the expected response to AllBalancesQuery
 type AllBalancesResponse struct {
+	// Amount is sorted by denom
 	Amount Coins `json:"amount"`
+	// NextKey is set if there are more coins. Pass it as StartAfter to get them
+	NextKey string `json:"next_key,omitempty"`
 }
 
 type StakingQuery struct {
-	Validators           *ValidatorsQuery         `json:"validators,omitempty"`
-	AllDelegations       *AllDelegationsQuery     `json:"all_delegations,omitempty"`
-	Delegation           *DelegationQuery         `json:"delegation,omitempty"`
-	UnBondingDelegations *UnbondingDeletionsQuery `json:"unbonding_delegations, omitempty"`
-	BondedDenom          *struct{}                `json:"bonded_denom,omitempty"`
+	Validators           *ValidatorsQuery           `json:"validators,omitempty"`
+	AllDelegations       *AllDelegationsQuery       `json:"all_delegations,omitempty"`
+	Delegation           *DelegationQuery           `json:"delegation,omitempty"`
+	UnBondingDelegations *UnbondingDelegationsQuery `json:"unbonding_delegations,omitempty"`
+	BondedDenom          *struct{}                  `json:"bonded_denom,omitempty"`
+	Validator            *ValidatorQuery            `json:"validator,omitempty"`
+	Params               *struct{}                  `json:"params,omitempty"`
 }
 
-type UnbondingDeletionsQuery struct {
+// UnbondingDelegationsQuery returns the pending unbondings of a delegator
+type UnbondingDelegationsQuery struct {
 	Delegator string `json:"delegator"`
 }
 
@@ -148,6 +187,27 @@ type Validator struct {
 	MaxChangeRate string `json:"max_change_rate"`
 }
 
+// ValidatorQuery returns a single validator, bonded or not
+type ValidatorQuery struct {
+	// Address is the operator address of the validator
+	Address string `json:"address"`
+}
+
+// ValidatorResponse is the expected response to ValidatorQuery
+type ValidatorResponse struct {
+	Validator FullValidator `json:"validator"`
+}
+
+// FullValidator is a Validator with its current state
+type FullValidator struct {
+	Validator
+	// one of "bonded", "unbonding" or "unbonded"
+	Status string `json:"status"`
+	// integer string of the bonded tokens, eg "1000000"
+	Tokens string `json:"tokens"`
+	Jailed bool   `json:"jailed"`
+}
+
 type AllDelegationsQuery struct {
 	Delegator string `json:"delegator"`
 }
@@ -206,17 +266,56 @@ type FullDelegation struct {
 	CanRedelegate      Coin   `json:"can_redelegate"`
 }
 
+// UnbondingDelegationsResponse is the expected response to UnbondingDelegationsQuery
 type UnbondingDelegationsResponse struct {
-	Delegations Delegations `json:"delegations"`
+	// Delegations has one item for every entry of an unbonding, so there can be several for the same validator
+	Delegations UnbondingDelegations `json:"delegations"`
+}
+
+// UnbondingDelegations must JSON encode empty array as []
+type UnbondingDelegations []UnbondingDelegation
+
+// MarshalJSON ensures that we get [] for empty arrays
+func (d UnbondingDelegations) MarshalJSON() ([]byte, error) {
+	if len(d) == 0 {
+		return []byte("[]"), nil
+	}
+	var raw []UnbondingDelegation = d
+	return json.Marshal(raw)
+}
+
+// UnbondingDelegation is a single entry of an unbonding
+type UnbondingDelegation struct {
+	Delegator string `json:"delegator"`
+	Validator string `json:"validator"`
+	// Amount is the balance that is still unbonding, which is less than the unbonded amount if the validator was slashed
+	Amount Coin `json:"amount"`
+	// CompletionTime is when the balance is returned to the delegator, in seconds since UNIX epoch like the block time
+	CompletionTime uint64 `json:"completion_time"`
+	CreationHeight int64  `json:"creation_height"`
 }
 
 type BondedDenomResponse struct {
 	Denom string `json:"denom"`
 }
 
+// StakingParamsResponse is the expected response to StakingQuery.Params
+type StakingParamsResponse struct {
+	// UnbondingTime is in seconds
+	UnbondingTime     uint64 `json:"unbonding_time"`
+	MaxValidators     uint32 `json:"max_validators"`
+	MaxEntries        uint32 `json:"max_entries"`
+	HistoricalEntries uint32 `json:"historical_entries"`
+	BondDenom         string `json:"bond_denom"`
+}
+
 type WasmQuery struct {
-	Smart *SmartQuery `json:"smart,omitempty"`
-	Raw   *RawQuery   `json:"raw,omitempty"`
+	Smart           *SmartQuery           `json:"smart,omitempty"`
+	Raw             *RawQuery             `json:"raw,omitempty"`
+	ContractInfo    *ContractInfoQuery    `json:"contract_info,omitempty"`
+	CodeInfo        *CodeInfoQuery        `json:"code_info,omitempty"`
+	IsContract      *IsContractQuery      `json:"is_contract,omitempty"`
+	ContractsByCode *ContractsByCodeQuery `json:"contracts_by_code,omitempty"`
 }
 
 // SmartQuery respone is raw bytes ([]byte)
@@ -231,13 +330,80 @@ type RawQuery struct {
 	Key          []byte `json:"key"`
 }
 
+// ContractInfoQuery returns the metadata of the contract at ContractAddr
+type ContractInfoQuery struct {
+	ContractAddr string `json:"contract_addr"`
+}
+
+// ContractInfoResponse is the expected response to ContractInfoQuery. Unlike upstream wasmd there is no admin field:
+// contracts on this chain are immutable, ContractInfo doesn't store an admin and there are no migrations
+type ContractInfoResponse struct {
+	CodeID  uint64 `json:"code_id"`
+	Creator string `json:"creator"`
+	Label   string `json:"label"`
+}
+
+// IsContractQuery returns whether Address is the address of a contract, rather than of a plain account
+type IsContractQuery struct {
+	Address string `json:"address"`
+}
+
+// IsContractResponse is the expected response to IsContractQuery
+type IsContractResponse struct {
+	IsContract bool `json:"is_contract"`
+}
+
+// ContractsByCodeQuery returns the addresses of the contracts instantiated from the code, ordered by address.
+// Limit defaults to, and is capped at, 100 contracts. StartAfter is the last address of the previous page.
+type ContractsByCodeQuery struct {
+	CodeID     uint64 `json:"code_id"`
+	StartAfter string `json:"start_after,omitempty"`
+	Limit      uint32 `json:"limit,omitempty"`
+}
+
+// ContractsByCodeResponse is the expected response to ContractsByCodeQuery
+type ContractsByCodeResponse struct {
+	Contracts []string `json:"contracts"`
+}
+
+// CodeInfoQuery returns the metadata of the code stored under CodeID
+type CodeInfoQuery struct {
+	CodeID uint64 `json:"code_id"`
+}
+
+// CodeInfoResponse is the expected response to CodeInfoQuery
+type CodeInfoResponse struct {
+	// CodeHash is hex encoded, the same way as CallbackCodeHash
+	CodeHash string `json:"code_hash"`
+	Creator  string `json:"creator"`
+	Source   string `json:"source"`
+	Builder  string `json:"builder"`
+}
+
 type DistQuery struct {
-	Rewards *RewardsQuery `json:"rewards,omitempty"`
+	Rewards           *RewardsQuery           `json:"rewards,omitempty"`
+	WithdrawAddress   *WithdrawAddressQuery   `json:"withdraw_address,omitempty"`
+	DelegationRewards *DelegationRewardsQuery `json:"delegation_rewards,omitempty"`
+	Params            *struct{}               `json:"params,omitempty"`
+}
+
+// DistParamsResponse is the expected response to DistQuery.Params. The rates are decimals with 18 digits.
+type DistParamsResponse struct {
+	CommunityTax        string `json:"community_tax"`
+	BaseProposerReward  string `json:"base_proposer_reward"`
+	BonusProposerReward string `json:"bonus_proposer_reward"`
+	WithdrawAddrEnabled bool   `json:"withdraw_addr_enabled"`
 }
 
 type GovQuery struct {
 	Proposals *ProposalsQuery `json:"proposals,omitempty"`
 }
+
+// EnvQuery returns the current block, encoded the same way as Env.Block. The response is a BlockInfo.
+// BlockInfo.Time is in seconds since the unix epoch, not nanoseconds: the enclave's env (cosmwasm 0.10)
+// only carries seconds, and a query that disagreed with the env a contract was called with would be a trap
+type EnvQuery struct{}
+
 type MintQuery struct {
 	Inflation   *MintingInflationQuery   `json:"inflation,omitempty"`
 	BondedRatio *MintingBondedRatioQuery `json:"bonded_ratio,omitempty"`
@@ -271,6 +437,28 @@ type RewardsQuery struct {
 	Delegator string `json:"delegator"`
 }
 
+// WithdrawAddressQuery asks for the address the staking rewards of Delegator are sent to
+type WithdrawAddressQuery struct {
+	Delegator string `json:"delegator"`
+}
+
+// WithdrawAddressResponse is the expected response to WithdrawAddressQuery
+type WithdrawAddressResponse struct {
+	WithdrawAddress string `json:"withdraw_address"`
+}
+
+// DelegationRewardsQuery asks for the rewards the delegation of Delegator to Validator accrued and didn't withdraw yet
+type DelegationRewardsQuery struct {
+	Delegator string `json:"delegator"`
+	Validator string `json:"validator"`
+}
+
+// DelegationRewardsResponse is the expected response to DelegationRewardsQuery
+type DelegationRewardsResponse struct {
+	// Rewards is empty if there is no such delegation
+	Rewards RewardCoins `json:"rewards"`
+}
+
 // DelegationResponse is the expected response to DelegationsQuery
 type RewardsResponse struct {
 	Rewards []Rewards   `json:"rewards,omitempty"`
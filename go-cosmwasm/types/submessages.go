@@ -0,0 +1,99 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ReplyOn defines when the contract that dispatched a SubMsg wants to be called back with the result
+type ReplyOn int
+
+const (
+	// ReplyNever never calls back, so the SubMsg behaves like a regular message. It is the zero value, so a SubMsg
+	// only gets a reply if it asks for one
+	ReplyNever ReplyOn = iota
+	// ReplyAlways calls back on success and on error
+	ReplyAlways
+	// ReplySuccess calls back only on success, an error aborts the whole execution
+	ReplySuccess
+	// ReplyError calls back only on error, the success result is dropped
+	ReplyError
+)
+
+var fromReplyOn = map[ReplyOn]string{
+	ReplyAlways:  "always",
+	ReplySuccess: "success",
+	ReplyError:   "error",
+	ReplyNever:   "never",
+}
+
+var toReplyOn = map[string]ReplyOn{
+	"always":  ReplyAlways,
+	"success": ReplySuccess,
+	"error":   ReplyError,
+	"never":   ReplyNever,
+}
+
+func (r ReplyOn) String() string {
+	return fromReplyOn[r]
+}
+
+func (r ReplyOn) MarshalJSON() ([]byte, error) {
+	s, ok := fromReplyOn[r]
+	if !ok {
+		return nil, fmt.Errorf("invalid reply_on value: %d", r)
+	}
+	return json.Marshal(s)
+}
+
+func (r *ReplyOn) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	replyOn, ok := toReplyOn[s]
+	if !ok {
+		return fmt.Errorf("invalid reply_on value '%s'", s)
+	}
+	*r = replyOn
+	return nil
+}
+
+// SubMsg wraps a CosmosMsg with some metadata for handling replies (ID) and optionally
+// limiting the gas usage (GasLimit)
+type SubMsg struct {
+	// ID is echoed back in the Reply, so the contract can tell which SubMsg it belongs to
+	ID  uint64    `json:"id"`
+	Msg CosmosMsg `json:"msg"`
+	// GasLimit caps the gas the message may use. If it runs out, only the SubMsg fails, not the whole execution
+	GasLimit *uint64 `json:"gas_limit,omitempty"`
+	ReplyOn  ReplyOn `json:"reply_on"`
+}
+
+// Reply is passed back to the contract once a SubMsg is executed
+type Reply struct {
+	ID     uint64        `json:"id"`
+	Result SubcallResult `json:"result"`
+}
+
+// SubcallResult is the raw response we return from the sdk -> reply after executing a SubMsg.
+// This mirrors Rust's ContractResult<SubcallResponse>.
+type SubcallResult struct {
+	Ok  *SubcallResponse `json:"ok,omitempty"`
+	Err string           `json:"error,omitempty"`
+}
+
+// SubcallResponse holds the events and data the SubMsg produced
+type SubcallResponse struct {
+	Events Events `json:"events"`
+	Data   []byte `json:"data,omitempty"`
+}
+
+// Events is a list of Event, as emitted by the sdk while executing a SubMsg
+type Events []Event
+
+// Event is an sdk event in a format a contract can parse
+type Event struct {
+	Type       string         `json:"type"`
+	Attributes []LogAttribute `json:"attributes"`
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplyOnDefaultsToNever(t *testing.T) {
+	var msg SubMsg
+	err := json.Unmarshal([]byte(`{"id":1,"msg":{"bank":{"send":{"from_address":"a","to_address":"b","amount":[]}}}}`), &msg)
+	require.NoError(t, err)
+	assert.Equal(t, ReplyNever, msg.ReplyOn)
+
+	bz, err := json.Marshal(SubMsg{})
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), `"reply_on":"never"`)
+}
+
+func TestReplyOnRoundTrip(t *testing.T) {
+	for _, replyOn := range []ReplyOn{ReplyNever, ReplyAlways, ReplySuccess, ReplyError} {
+		bz, err := json.Marshal(replyOn)
+		require.NoError(t, err)
+		var decoded ReplyOn
+		require.NoError(t, json.Unmarshal(bz, &decoded))
+		assert.Equal(t, replyOn, decoded)
+	}
+
+	var decoded ReplyOn
+	assert.Error(t, json.Unmarshal([]byte(`"sometimes"`), &decoded))
+	_, err := json.Marshal(ReplyOn(42))
+	assert.Error(t, err)
+}
@@ -18,6 +18,7 @@ const (
 	RouterKey                     = types.RouterKey
 	MaxWasmSize                   = types.MaxWasmSize
 	MaxLabelSize                  = types.MaxLabelSize
+	MaxSaltSize                   = types.MaxSaltSize
 	BuildTagRegexp                = types.BuildTagRegexp
 	MaxBuildTagSize               = types.MaxBuildTagSize
 	CustomEventType               = types.CustomEventType
@@ -42,64 +43,81 @@ const (
 var (
 	// functions aliases
 	// ConvertToProposals        = types.ConvertToProposals
-	// DefaultParams             = types.DefaultParams
-	RegisterCodec             = types.RegisterLegacyAminoCodec
-	RegisterInterfaces        = types.RegisterInterfaces
-	ValidateGenesis           = types.ValidateGenesis
-	GetCodeKey                = types.GetCodeKey
-	GetContractAddressKey     = types.GetContractAddressKey
-	GetContractStorePrefixKey = types.GetContractStorePrefixKey
-	NewCodeInfo               = types.NewCodeInfo
-	NewAbsoluteTxPosition     = types.NewAbsoluteTxPosition
-	NewContractInfo           = types.NewContractInfo
-	NewEnv                    = types.NewEnv
-	NewWasmCoins              = types.NewWasmCoins
-	ParseEvents               = types.ParseEvents
-	DefaultWasmConfig         = types.DefaultWasmConfig
-	IsEncryptedError          = types.IsEncryptedErrorCode
-	ErrContainsQueryError     = types.ErrContainsQueryError
-	GetConfig                 = types.GetConfig
-	InitGenesis               = keeper.InitGenesis
-	ExportGenesis             = keeper.ExportGenesis
-	NewMessageHandler         = keeper.NewMessageHandler
-	DefaultEncoders           = keeper.DefaultEncoders
-	EncodeBankMsg             = keeper.EncodeBankMsg
-	NoCustomMsg               = keeper.NoCustomMsg
-	EncodeStakingMsg          = keeper.EncodeStakingMsg
-	EncodeWasmMsg             = keeper.EncodeWasmMsg
-	NewKeeper                 = keeper.NewKeeper
-	NewQuerier                = keeper.NewQuerier
-	NewLegacyQuerier          = keeper.NewLegacyQuerier
-	DefaultQueryPlugins       = keeper.DefaultQueryPlugins
-	BankQuerier               = keeper.BankQuerier
-	NoCustomQuerier           = keeper.NoCustomQuerier
-	StakingQuerier            = keeper.StakingQuerier
-	WasmQuerier               = keeper.WasmQuerier
-	MakeTestCodec             = keeper.MakeTestCodec
-	CreateTestInput           = keeper.CreateTestInput
-	CreateFakeFundedAccount   = keeper.CreateFakeFundedAccount
-	TestHandler               = keeper.TestHandler
-	PrepareInitSignedTx       = keeper.PrepareInitSignedTx
-	PrepareExecSignedTx       = keeper.PrepareExecSignedTx
-	NewWasmSnapshotter        = keeper.NewWasmSnapshotter
+	DefaultParams              = types.DefaultParams
+	RegisterCodec              = types.RegisterLegacyAminoCodec
+	RegisterInterfaces         = types.RegisterInterfaces
+	ValidateGenesis            = types.ValidateGenesis
+	GetCodeKey                 = types.GetCodeKey
+	GetContractAddressKey      = types.GetContractAddressKey
+	GetContractStorePrefixKey  = types.GetContractStorePrefixKey
+	NewCodeInfo                = types.NewCodeInfo
+	NewAbsoluteTxPosition      = types.NewAbsoluteTxPosition
+	NewContractInfo            = types.NewContractInfo
+	NewEnv                     = types.NewEnv
+	NewWasmCoins               = types.NewWasmCoins
+	ParseEvents                = types.ParseEvents
+	DefaultWasmConfig          = types.DefaultWasmConfig
+	IsEncryptedError           = types.IsEncryptedErrorCode
+	ErrContainsQueryError      = types.ErrContainsQueryError
+	ErrorField                 = types.ErrorField
+	GetConfig                  = types.GetConfig
+	InitGenesis                = keeper.InitGenesis
+	ExportGenesis              = keeper.ExportGenesis
+	NewMessageHandler          = keeper.NewMessageHandler
+	DefaultEncoders            = keeper.DefaultEncoders
+	EncodeBankMsg              = keeper.EncodeBankMsg
+	NoCustomMsg                = keeper.NoCustomMsg
+	EncodeStakingMsg           = keeper.EncodeStakingMsg
+	EncodeWasmMsg              = keeper.EncodeWasmMsg
+	EncodeStargateMsg          = keeper.EncodeStargateMsg
+	Decode                     = keeper.Decode
+	NewSnip721TransferMsg      = keeper.NewSnip721TransferMsg
+	PredictableContractAddress = keeper.PredictableContractAddress
+	NewKeeper                  = keeper.NewKeeper
+	WithCustomEncoder          = keeper.WithCustomEncoder
+	WithCustomMsgHandler       = keeper.WithCustomMsgHandler
+	WithPortKeeper             = keeper.WithPortKeeper
+	NewQuerier                 = keeper.NewQuerier
+	NewLegacyQuerier           = keeper.NewLegacyQuerier
+	DefaultQueryPlugins        = keeper.DefaultQueryPlugins
+	BankQuerier                = keeper.BankQuerier
+	NoCustomQuerier            = keeper.NoCustomQuerier
+	StakingQuerier             = keeper.StakingQuerier
+	WasmQuerier                = keeper.WasmQuerier
+	MakeTestCodec              = keeper.MakeTestCodec
+	CreateTestInput            = keeper.CreateTestInput
+	CreateFakeFundedAccount    = keeper.CreateFakeFundedAccount
+	TestHandler                = keeper.TestHandler
+	PrepareInitSignedTx        = keeper.PrepareInitSignedTx
+	PrepareExecSignedTx        = keeper.PrepareExecSignedTx
+	NewWasmSnapshotter         = keeper.NewWasmSnapshotter
 
 	// variable aliases
-	ModuleCdc            = types.ModuleCdc
-	DefaultCodespace     = types.DefaultCodespace
-	ErrCreateFailed      = types.ErrCreateFailed
-	ErrAccountExists     = types.ErrAccountExists
-	ErrInstantiateFailed = types.ErrInstantiateFailed
-	ErrExecuteFailed     = types.ErrExecuteFailed
-	ErrGasLimit          = types.ErrGasLimit
-	ErrInvalidGenesis    = types.ErrInvalidGenesis
-	ErrNotFound          = types.ErrNotFound
-	ErrQueryFailed       = types.ErrQueryFailed
-	ErrInvalidMsg        = types.ErrInvalidMsg
-	KeyLastCodeID        = types.KeyLastCodeID
-	KeyLastInstanceID    = types.KeyLastInstanceID
-	CodeKeyPrefix        = types.CodeKeyPrefix
-	ContractKeyPrefix    = types.ContractKeyPrefix
-	ContractStorePrefix  = types.ContractStorePrefix
+	ModuleCdc              = types.ModuleCdc
+	DefaultCodespace       = types.DefaultCodespace
+	ErrCreateFailed        = types.ErrCreateFailed
+	ErrAccountExists       = types.ErrAccountExists
+	ErrInstantiateFailed   = types.ErrInstantiateFailed
+	ErrExecuteFailed       = types.ErrExecuteFailed
+	ErrGasLimit            = types.ErrGasLimit
+	ErrInvalidGenesis      = types.ErrInvalidGenesis
+	ErrNotFound            = types.ErrNotFound
+	ErrQueryFailed         = types.ErrQueryFailed
+	ErrInvalidMsg          = types.ErrInvalidMsg
+	ErrInvalidRecipient    = types.ErrInvalidRecipient
+	ErrInvalidAmount       = types.ErrInvalidAmount
+	ErrUnknownMsgVariant   = types.ErrUnknownMsgVariant
+	ErrMaxQueryStackSize   = types.ErrMaxQueryStackSize
+	ErrContractNotFound    = types.ErrContractNotFound
+	ErrTooManyContractMsgs = types.ErrTooManyContractMsgs
+	ErrInvalidEvent        = types.ErrInvalidEvent
+	ErrDispatchPaused      = types.ErrDispatchPaused
+	ErrDeadlineExceeded    = types.ErrDeadlineExceeded
+	KeyLastCodeID          = types.KeyLastCodeID
+	KeyLastInstanceID      = types.KeyLastInstanceID
+	CodeKeyPrefix          = types.CodeKeyPrefix
+	ContractKeyPrefix      = types.ContractKeyPrefix
+	ContractStorePrefix    = types.ContractStorePrefix
 	// EnableAllProposals   = types.EnableAllProposals
 	// DisableAllProposals  = types.DisableAllProposals
 )
@@ -112,6 +130,7 @@ type (
 	MsgStoreCode            = types.MsgStoreCode
 	MsgInstantiateContract  = types.MsgInstantiateContract
 	MsgExecuteContract      = types.MsgExecuteContract
+	MsgUpdateComputeParams  = types.MsgUpdateComputeParams
 	Model                   = types.Model
 	CodeInfo                = types.CodeInfo
 	ContractInfo            = types.ContractInfo
@@ -124,9 +143,15 @@ type (
 	StakingEncoder          = keeper.StakingEncoder
 	WasmEncoder             = keeper.WasmEncoder
 	GovEncoder              = keeper.GovEncoder
+	IBCEncoder              = keeper.IBCEncoder
+	DistributionEncoder     = keeper.DistributionEncoder
+	StargateEncoder         = keeper.StargateEncoder
 	MessageEncoders         = keeper.MessageEncoders
 	Keeper                  = keeper.Keeper
+	Option                  = keeper.Option
+	PortKeeper              = keeper.PortKeeper
 	ContractInfoWithAddress = types.ContractInfoWithAddress
+	FieldError              = types.FieldError
 	QueryHandler            = keeper.QueryHandler
 	CustomQuerier           = keeper.CustomQuerier
 	QueryPlugins            = keeper.QueryPlugins
@@ -20,6 +20,8 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/flags"
@@ -49,6 +51,7 @@ func GetQueryCmd() *cobra.Command {
 		GetCmdQueryLabel(),
 		GetCmdCodeHashByContract(),
 		CmdDecryptText(),
+		CmdEncodeMsg(),
 		// GetCmdGetContractHistory(cdc),
 	)
 	return queryCmd
@@ -303,6 +306,54 @@ func CmdDecryptText() *cobra.Command {
 	return cmd
 }
 
+// CmdEncodeMsg shows the sdk.Msgs a message returned by a contract is encoded into. It works offline.
+func CmdEncodeMsg() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encode-msg [sender] [json_encoded_cosmos_msg]",
+		Short: "Show the sdk messages a contract message is encoded into",
+		Long: "Run a CosmosMsg, as returned by a contract, through the default message encoders and print the resulting " +
+			"sdk messages as JSON. The sender is the address of the contract that returns the message. Nothing is sent to a node.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientCtx := client.GetClientContextFromCmd(cmd)
+
+			sender, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			var msg cosmwasmTypes.CosmosMsg
+			if err := json.Unmarshal([]byte(args[1]), &msg); err != nil {
+				return fmt.Errorf("invalid contract message: %w", err)
+			}
+
+			encoders := keeper.DefaultEncoders().Merge(&keeper.MessageEncoders{
+				Stargate: keeper.EncodeStargateMsg(clientCtx.InterfaceRegistry),
+			})
+			ctx := sdk.NewContext(nil, tmproto.Header{}, false, log.NewNopLogger())
+			sdkMsgs, err := encoders.Encode(ctx, sender, msg)
+			if err != nil {
+				return err
+			}
+
+			out := make([]json.RawMessage, len(sdkMsgs))
+			for i, sdkMsg := range sdkMsgs {
+				out[i], err = clientCtx.Codec.MarshalInterfaceJSON(sdkMsg)
+				if err != nil {
+					return err
+				}
+			}
+			bz, err := json.Marshal(out)
+			if err != nil {
+				return err
+			}
+			return clientCtx.PrintBytes(bz)
+		},
+	}
+
+	return cmd
+}
+
 // QueryDecryptTxCmd the default command for a tx query + IO decryption if I'm the tx sender.
 // Coppied from https://github.com/cosmos/cosmos-sdk/blob/v0.38.4/x/auth/client/cli/query.go#L157-L184 and added IO decryption (Could not wrap it because it prints directly to stdout)
 func GetQueryDecryptTxCmd() *cobra.Command {
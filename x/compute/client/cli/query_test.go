@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	clitestutil "github.com/cosmos/cosmos-sdk/testutil/cli"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/keeper"
+)
+
+func TestCmdEncodeMsg(t *testing.T) {
+	encodingConfig := keeper.MakeEncodingConfig()
+	clientCtx := client.Context{}.
+		WithCodec(encodingConfig.Marshaler).
+		WithInterfaceRegistry(encodingConfig.InterfaceRegistry)
+
+	sender := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	rcpt := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+	msg := fmt.Sprintf(`{"bank":{"send":{"from_address":"%s","to_address":"%s","amount":[{"denom":"uscrt","amount":"100"}]}}}`, sender, rcpt)
+
+	out, err := clitestutil.ExecTestCLICmd(clientCtx, CmdEncodeMsg(), []string{sender.String(), msg})
+	require.NoError(t, err)
+	expected := fmt.Sprintf(`[{"@type":"/cosmos.bank.v1beta1.MsgSend","from_address":"%s","to_address":"%s","amount":[{"denom":"uscrt","amount":"100"}]}]`, sender, rcpt)
+	require.JSONEq(t, expected, out.String())
+
+	_, err = clitestutil.ExecTestCLICmd(clientCtx, CmdEncodeMsg(), []string{sender.String(), `{"bank":{}}`})
+	require.Error(t, err)
+}
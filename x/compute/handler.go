@@ -22,6 +22,8 @@ func NewHandler(k Keeper) sdk.Handler {
 			return handleInstantiate(ctx, k, msg)
 		case *MsgExecuteContract:
 			return handleExecute(ctx, k, msg)
+		case *MsgUpdateComputeParams:
+			return handleUpdateComputeParams(ctx, k, msg)
 			/*
 				case MsgMigrateContract:
 					return handleMigration(ctx, k, &msg)
@@ -129,6 +131,25 @@ func handleExecute(ctx sdk.Context, k Keeper, msg *MsgExecuteContract) (*sdk.Res
 	return res, nil
 }
 
+func handleUpdateComputeParams(ctx sdk.Context, k Keeper, msg *MsgUpdateComputeParams) (*sdk.Result, error) {
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	if err := k.UpdateComputeParams(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvent(sdk.NewEvent(
+		sdk.EventTypeMessage,
+		sdk.NewAttribute(sdk.AttributeKeyModule, ModuleName),
+		sdk.NewAttribute(types.AttributeKeySigner, msg.Authority.String()),
+	))
+
+	return &sdk.Result{
+		Events: ctx.EventManager().ABCIEvents(),
+	}, nil
+}
+
 /*
 func handleMigration(ctx sdk.Context, k Keeper, msg *MsgMigrateContract) (*sdk.Result, error) {
 	res, err := k.Migrate(ctx, msg.Contract, msg.Sender, msg.CodeID, msg.MigrateMsg) // for MsgMigrateContract, there is only one signer which is msg.Sender (https://github.com/enigmampc/SecretNetwork/blob/d7813792fa07b93a10f0885eaa4c5e0a0a698854/x/compute/internal/types/msg.go#L228-L230)
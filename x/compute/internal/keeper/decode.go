@@ -0,0 +1,102 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+// Decode is the best-effort reverse of Encode, for testing encoders: it returns the contract message an sdk.Msg
+// is encoded from. Only bank sends, staking and the execute and instantiate wasm messages are supported.
+//
+// An sdk.Msg doesn't always say everything about the message it came from, e.g. a MsgWithdrawDelegatorReward
+// decodes into a Withdraw without a recipient even if it was encoded together with a MsgSetWithdrawAddress.
+func Decode(msg sdk.Msg) (wasmTypes.CosmosMsg, error) {
+	switch msg := msg.(type) {
+	case *banktypes.MsgSend:
+		return wasmTypes.CosmosMsg{
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress: msg.FromAddress,
+					ToAddress:   msg.ToAddress,
+					Amount:      types.NewWasmCoins(msg.Amount),
+				},
+			},
+		}, nil
+	case *stakingtypes.MsgDelegate:
+		return wasmTypes.CosmosMsg{
+			Staking: &wasmTypes.StakingMsg{
+				Delegate: &wasmTypes.DelegateMsg{
+					Validator: msg.ValidatorAddress,
+					Amount:    decodeCoin(msg.Amount),
+				},
+			},
+		}, nil
+	case *stakingtypes.MsgUndelegate:
+		return wasmTypes.CosmosMsg{
+			Staking: &wasmTypes.StakingMsg{
+				Undelegate: &wasmTypes.UndelegateMsg{
+					Validator: msg.ValidatorAddress,
+					Amount:    decodeCoin(msg.Amount),
+				},
+			},
+		}, nil
+	case *stakingtypes.MsgBeginRedelegate:
+		return wasmTypes.CosmosMsg{
+			Staking: &wasmTypes.StakingMsg{
+				Redelegate: &wasmTypes.RedelegateMsg{
+					SrcValidator: msg.ValidatorSrcAddress,
+					DstValidator: msg.ValidatorDstAddress,
+					Amount:       decodeCoin(msg.Amount),
+				},
+			},
+		}, nil
+	case *distrtypes.MsgWithdrawDelegatorReward:
+		return wasmTypes.CosmosMsg{
+			Staking: &wasmTypes.StakingMsg{
+				Withdraw: &wasmTypes.WithdrawMsg{
+					Validator: msg.ValidatorAddress,
+				},
+			},
+		}, nil
+	case *types.MsgExecuteContract:
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Execute: &wasmTypes.ExecuteMsg{
+					ContractAddr:      msg.Contract.String(),
+					CallbackCodeHash:  msg.CallbackCodeHash,
+					Msg:               msg.Msg,
+					Send:              types.NewWasmCoins(msg.SentFunds),
+					CallbackSignature: msg.CallbackSig,
+				},
+			},
+		}, nil
+	case *types.MsgInstantiateContract:
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Instantiate: &wasmTypes.InstantiateMsg{
+					CodeID:            msg.CodeID,
+					CallbackCodeHash:  msg.CallbackCodeHash,
+					Msg:               msg.InitMsg,
+					Label:             msg.Label,
+					Send:              types.NewWasmCoins(msg.InitFunds),
+					CallbackSignature: msg.CallbackSig,
+				},
+			},
+		}, nil
+	default:
+		return wasmTypes.CosmosMsg{}, sdkerrors.Wrapf(types.ErrUnknownMsgVariant, "can't decode %T", msg)
+	}
+}
+
+func decodeCoin(coin sdk.Coin) wasmTypes.Coin {
+	return wasmTypes.Coin{
+		Denom:  coin.Denom,
+		Amount: coin.Amount.String(),
+	}
+}
@@ -0,0 +1,109 @@
+package keeper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	valAddr := make(sdk.ValAddress, 20)
+	valAddr[0] = 12
+	valAddr2 := make(sdk.ValAddress, 20)
+	valAddr2[1] = 123
+	jsonMsg := json.RawMessage(`{"foo":123}`)
+	codeHash := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	cases := map[string]wasmTypes.CosmosMsg{
+		"bank send": {
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress: addr1.String(),
+					ToAddress:   addr2.String(),
+					Amount:      wasmTypes.Coins{wasmTypes.NewCoin(12345, "uatom"), wasmTypes.NewCoin(777, "uscrt")},
+				},
+			},
+		},
+		"staking delegate": {
+			Staking: &wasmTypes.StakingMsg{
+				Delegate: &wasmTypes.DelegateMsg{
+					Validator: valAddr.String(),
+					Amount:    wasmTypes.NewCoin(777, "stake"),
+				},
+			},
+		},
+		"staking undelegate": {
+			Staking: &wasmTypes.StakingMsg{
+				Undelegate: &wasmTypes.UndelegateMsg{
+					Validator: valAddr.String(),
+					Amount:    wasmTypes.NewCoin(555, "stake"),
+				},
+			},
+		},
+		"staking redelegate": {
+			Staking: &wasmTypes.StakingMsg{
+				Redelegate: &wasmTypes.RedelegateMsg{
+					SrcValidator: valAddr.String(),
+					DstValidator: valAddr2.String(),
+					Amount:       wasmTypes.NewCoin(222, "stake"),
+				},
+			},
+		},
+		"staking withdraw": {
+			Staking: &wasmTypes.StakingMsg{
+				Withdraw: &wasmTypes.WithdrawMsg{
+					Validator: valAddr.String(),
+				},
+			},
+		},
+		"wasm execute": {
+			Wasm: &wasmTypes.WasmMsg{
+				Execute: &wasmTypes.ExecuteMsg{
+					ContractAddr:      addr2.String(),
+					CallbackCodeHash:  codeHash,
+					Msg:               jsonMsg,
+					Send:              wasmTypes.Coins{wasmTypes.NewCoin(12, "eth")},
+					CallbackSignature: []byte("signature"),
+				},
+			},
+		},
+		"wasm instantiate": {
+			Wasm: &wasmTypes.WasmMsg{
+				Instantiate: &wasmTypes.InstantiateMsg{
+					CodeID:           7,
+					CallbackCodeHash: codeHash,
+					Msg:              jsonMsg,
+					Label:            "my contract",
+					Send:             wasmTypes.Coins{wasmTypes.NewCoin(123, "eth")},
+				},
+			},
+		},
+	}
+
+	encoder := DefaultEncoders()
+	for name, msg := range cases {
+		msg := msg
+		t.Run(name, func(t *testing.T) {
+			sdkMsgs, err := encoder.Encode(encodingTestContext(), addr1, msg)
+			require.NoError(t, err)
+			require.Len(t, sdkMsgs, 1)
+			decoded, err := Decode(sdkMsgs[0])
+			require.NoError(t, err)
+			assert.Equal(t, msg, decoded)
+		})
+	}
+
+	// unsupported messages are rejected
+	_, err := Decode(&govtypes.MsgDeposit{})
+	assert.True(t, types.ErrUnknownMsgVariant.Is(err), err)
+}
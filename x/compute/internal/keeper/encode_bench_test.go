@@ -0,0 +1,135 @@
+package keeper
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// The encoder benchmarks report the allocations and the gas charged per encoded contract message. Run them with
+// `go test -run=^$ -bench=BenchmarkEncode -benchmem` and compare the results with benchstat.
+
+func benchmarkEncode(b *testing.B, msgs []wasmTypes.CosmosMsg) {
+	encoder := DefaultEncoders()
+	contractAddr := sdk.AccAddress(make([]byte, 20))
+	ctx := encodingTestContext()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	startGas := ctx.GasMeter().GasConsumed()
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.EncodeBatch(ctx, contractAddr, msgs); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(ctx.GasMeter().GasConsumed()-startGas)/float64(b.N), "gas/op")
+}
+
+func benchmarkSend(coins wasmTypes.Coins) wasmTypes.CosmosMsg {
+	contractAddr := sdk.AccAddress(make([]byte, 20))
+	rcpt := sdk.AccAddress(append(make([]byte, 19), 1))
+	return wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: contractAddr.String(),
+				ToAddress:   rcpt.String(),
+				Amount:      coins,
+			},
+		},
+	}
+}
+
+func BenchmarkEncodeSend(b *testing.B) {
+	benchmarkEncode(b, []wasmTypes.CosmosMsg{benchmarkSend(wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")})})
+}
+
+func BenchmarkEncodeMultiCoinSend(b *testing.B) {
+	coins := wasmTypes.Coins{
+		wasmTypes.NewCoin(100, "uatom"),
+		wasmTypes.NewCoin(200, "uosmo"),
+		wasmTypes.NewCoin(300, "uscrt"),
+		wasmTypes.NewCoin(400, "ustars"),
+		wasmTypes.NewCoin(500, "uusdc"),
+	}
+	benchmarkEncode(b, []wasmTypes.CosmosMsg{benchmarkSend(coins)})
+}
+
+// BenchmarkEncodeWithdraw encodes a withdraw with a recipient, which expands into two sdk.Msgs
+func BenchmarkEncodeWithdraw(b *testing.B) {
+	rcpt := sdk.AccAddress(append(make([]byte, 19), 1))
+	withdraw := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Withdraw: &wasmTypes.WithdrawMsg{
+				Validator: sdk.ValAddress(rcpt).String(),
+				Recipient: rcpt.String(),
+			},
+		},
+	}
+	benchmarkEncode(b, []wasmTypes.CosmosMsg{withdraw})
+}
+
+func BenchmarkEncodeLargeBatch(b *testing.B) {
+	msgs := make([]wasmTypes.CosmosMsg, 100)
+	for i := range msgs {
+		msgs[i] = benchmarkSend(wasmTypes.Coins{wasmTypes.NewCoin(uint64(i+1), "uscrt")})
+	}
+	benchmarkEncode(b, msgs)
+}
+
+func benchmarkStargateSends(b *testing.B, n int) []wasmTypes.CosmosMsg {
+	contractAddr := sdk.AccAddress(make([]byte, 20))
+	rcpt := sdk.AccAddress(append(make([]byte, 19), 1))
+	send := &banktypes.MsgSend{
+		FromAddress: contractAddr.String(),
+		ToAddress:   rcpt.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uscrt", 100)),
+	}
+	bz, err := send.Marshal()
+	if err != nil {
+		b.Fatal(err)
+	}
+	msgs := make([]wasmTypes.CosmosMsg, n)
+	for i := range msgs {
+		msgs[i] = wasmTypes.CosmosMsg{Stargate: &wasmTypes.StargateMsg{TypeURL: sdk.MsgTypeURL(send), Value: bz}}
+	}
+	return msgs
+}
+
+// BenchmarkEncodeStargateBatch encodes 1000 Stargate messages of the same type, which resolve their type URL once
+func BenchmarkEncodeStargateBatch(b *testing.B) {
+	stargate := EncodeStargateMsg(MakeEncodingConfig().InterfaceRegistry)
+	msgs := benchmarkStargateSends(b, 1000)
+	contractAddr := sdk.AccAddress(make([]byte, 20))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, msg := range msgs {
+			if _, err := stargate(contractAddr, msg.Stargate); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkEncodeStargateBatchUncached is BenchmarkEncodeStargateBatch with every message resolving its type URL
+// through the interface registry, as a baseline for the speedup of the cache
+func BenchmarkEncodeStargateBatchUncached(b *testing.B) {
+	registry := MakeEncodingConfig().InterfaceRegistry
+	msgs := benchmarkStargateSends(b, 1000)
+	contractAddr := sdk.AccAddress(make([]byte, 20))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, msg := range msgs {
+			if _, err := EncodeStargateMsg(registry)(contractAddr, msg.Stargate); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+//go:build go1.18
+// +build go1.18
+
+package keeper
+
+import (
+	"encoding/json"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// FuzzEncode checks that no message a contract can emit makes Encode panic, however malformed it is.
+// Run it with `go test -run=^$ -fuzz=FuzzEncode`, without -fuzz only the seeds are checked.
+func FuzzEncode(f *testing.F) {
+	addr := sdk.AccAddress(make([]byte, 20)).String()
+	valAddr := sdk.ValAddress(make([]byte, 20)).String()
+	for _, seed := range []string{
+		`{}`,
+		`{"bank":{"send":{"from_address":"` + addr + `","to_address":"` + addr + `","amount":[{"denom":"uscrt","amount":"100"}]}}}`,
+		`{"bank":{"send":{"from_address":"` + addr + `","to_address":"` + addr + `","amount":[{"denom":"uscrt","amount":"-100"}]}}}`,
+		`{"bank":{"burn":{"amount":[{"denom":"uscrt","amount":"100"}]}}}`,
+		`{"staking":{"delegate":{"validator":"` + valAddr + `","amount":{"denom":"stake","amount":"777"}}}}`,
+		`{"staking":{"redelegate":{"src_validator":"` + valAddr + `","dst_validator":"` + valAddr + `","amount":{"denom":"stake","amount":"1"}}}}`,
+		`{"staking":{"withdraw":{"validator":"` + valAddr + `","recipient":"` + addr + `"}}}`,
+		`{"wasm":{"execute":{"contract_addr":"` + addr + `","msg":"e30=","send":[],"gas_limit":100}}}`,
+		`{"wasm":{"instantiate":{"code_id":1,"msg":"e30=","label":"label","send":[{"denom":"uscrt","amount":"1"}]}}}`,
+		`{"wasm":{"store_code":{"wasm_bytes":"AGFzbQ=="}}}`,
+		`{"gov":{"vote":{"proposal_id":1,"vote":"Yes"}}}`,
+		`{"ibc":{"transfer":{"channel_id":"channel-0","to_address":"cosmos1","amount":{"denom":"uscrt","amount":"1"},"timeout":{"relative_blocks":10}}}}`,
+		`{"distribution":{"fund_community_pool":{"amount":[{"denom":"uscrt","amount":"1"}]}}}`,
+		`{"vesting":{"create_vesting_account":{"to":"` + addr + `","amount":[{"denom":"uscrt","amount":"1"}],"end_time":1}}}`,
+		`{"feegrant":{"grant_allowance":{"grantee":"` + addr + `","allowance":{"periodic":{"basic":{"spend_limit":[]},"period":3600,"period_spend_limit":[{"denom":"uscrt","amount":"1"}]}}}}}`,
+		`{"authz":{"exec":{"granter":"` + addr + `","msgs":[{"bank":{"send":{"from_address":"` + addr + `","to_address":"` + addr + `","amount":[]}}}]}}}`,
+		`{"slashing":{"unjail":{"validator":"` + valAddr + `"}}}`,
+		`{"stargate":{"type_url":"/cosmos.bank.v1beta1.MsgSend","value":""}}`,
+		`{"custom":{"oracle":{}}}`,
+		`{"bank":{"send":{}},"staking":{"delegate":{}}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	encoder := DefaultEncoders()
+	f.Fuzz(func(t *testing.T, bz []byte) {
+		var msg wasmTypes.CosmosMsg
+		if err := json.Unmarshal(bz, &msg); err != nil {
+			// the contract runtime rejects messages that aren't a CosmosMsg
+			return
+		}
+		// an error is fine, only a panic fails
+		_, _ = encoder.Encode(encodingTestContext(), sdk.AccAddress(make([]byte, 20)), msg)
+	})
+}
@@ -1,17 +1,39 @@
 package keeper
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
 	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v3/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v3/modules/core/02-client/types"
+	host "github.com/cosmos/ibc-go/v3/modules/core/24-host"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
 	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+	"github.com/gogo/protobuf/proto"
 )
 
 type MessageHandler struct {
@@ -19,8 +41,14 @@ type MessageHandler struct {
 	encoders MessageEncoders
 }
 
-func NewMessageHandler(router sdk.Router, customEncoders *MessageEncoders) MessageHandler {
-	encoders := DefaultEncoders().Merge(customEncoders)
+// NewMessageHandler creates the handler for messages dispatched by contracts.
+// The unpacker is used to decode Stargate messages, which are disabled when it is nil.
+func NewMessageHandler(router sdk.Router, customEncoders *MessageEncoders, unpacker codectypes.AnyUnpacker) MessageHandler {
+	encoders := DefaultEncoders()
+	if unpacker != nil {
+		encoders.Stargate = EncodeStargateMsg(unpacker)
+	}
+	encoders = encoders.Merge(customEncoders)
 	return MessageHandler{
 		router:   router,
 		encoders: encoders,
@@ -32,22 +60,43 @@ type CustomEncoder func(sender sdk.AccAddress, msg json.RawMessage) ([]sdk.Msg,
 type StakingEncoder func(sender sdk.AccAddress, msg *wasmTypes.StakingMsg) ([]sdk.Msg, error)
 type WasmEncoder func(sender sdk.AccAddress, msg *wasmTypes.WasmMsg) ([]sdk.Msg, error)
 type GovEncoder func(sender sdk.AccAddress, msg *wasmTypes.GovMsg) ([]sdk.Msg, error)
+type IBCEncoder func(sender sdk.AccAddress, msg *wasmTypes.IBCMsg) ([]sdk.Msg, error)
+type DistributionEncoder func(sender sdk.AccAddress, msg *wasmTypes.DistributionMsg) ([]sdk.Msg, error)
+type StargateEncoder func(sender sdk.AccAddress, msg *wasmTypes.StargateMsg) ([]sdk.Msg, error)
+type VestingEncoder func(sender sdk.AccAddress, msg *wasmTypes.VestingMsg) ([]sdk.Msg, error)
+type FeegrantEncoder func(sender sdk.AccAddress, msg *wasmTypes.FeegrantMsg) ([]sdk.Msg, error)
+type SlashingEncoder func(sender sdk.AccAddress, msg *wasmTypes.SlashingMsg) ([]sdk.Msg, error)
 
 type MessageEncoders struct {
-	Bank    BankEncoder
-	Custom  CustomEncoder
-	Staking StakingEncoder
-	Wasm    WasmEncoder
-	Gov     GovEncoder
+	Bank         BankEncoder
+	Custom       CustomEncoder
+	Staking      StakingEncoder
+	Wasm         WasmEncoder
+	Gov          GovEncoder
+	IBC          IBCEncoder
+	Distribution DistributionEncoder
+	// Stargate needs the interface registry, so it is not part of DefaultEncoders and is set up by NewMessageHandler
+	Stargate StargateEncoder
+	Vesting  VestingEncoder
+	Feegrant FeegrantEncoder
+	Slashing SlashingEncoder
+	// CustomEncoders are looked up by the name of the custom variant (the single top-level key
+	// of the custom JSON object). If no named encoder matches, the message is handed to Custom.
+	CustomEncoders map[string]CustomEncoder
 }
 
 func DefaultEncoders() MessageEncoders {
 	return MessageEncoders{
-		Bank:    EncodeBankMsg,
-		Custom:  NoCustomMsg,
-		Staking: EncodeStakingMsg,
-		Wasm:    EncodeWasmMsg,
-		Gov:     EncodeGovMsg,
+		Bank:         EncodeBankMsg,
+		Custom:       NoCustomMsg,
+		Staking:      EncodeStakingMsg,
+		Wasm:         EncodeWasmMsg,
+		Gov:          EncodeGovMsg,
+		IBC:          EncodeIBCMsg,
+		Distribution: EncodeDistributionMsg,
+		Vesting:      EncodeVestingMsg,
+		Feegrant:     EncodeFeegrantMsg,
+		Slashing:     EncodeSlashingMsg,
 	}
 }
 
@@ -70,24 +119,217 @@ func (e MessageEncoders) Merge(o *MessageEncoders) MessageEncoders {
 	if o.Gov != nil {
 		e.Gov = o.Gov
 	}
+	if o.IBC != nil {
+		e.IBC = o.IBC
+	}
+	if o.Distribution != nil {
+		e.Distribution = o.Distribution
+	}
+	if o.Stargate != nil {
+		e.Stargate = o.Stargate
+	}
+	if o.Vesting != nil {
+		e.Vesting = o.Vesting
+	}
+	if o.Feegrant != nil {
+		e.Feegrant = o.Feegrant
+	}
+	if o.Slashing != nil {
+		e.Slashing = o.Slashing
+	}
+	if len(o.CustomEncoders) != 0 {
+		// copy so we never mutate a map shared with another MessageEncoders
+		merged := make(map[string]CustomEncoder, len(e.CustomEncoders)+len(o.CustomEncoders))
+		for name, encoder := range e.CustomEncoders {
+			merged[name] = encoder
+		}
+		for name, encoder := range o.CustomEncoders {
+			merged[name] = encoder
+		}
+		e.CustomEncoders = merged
+	}
 	return e
 }
 
-func (e MessageEncoders) Encode(contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg) ([]sdk.Msg, error) {
+// Encode converts a message emitted by a contract into the sdk.Msgs that execute it.
+// The work is charged to the gas meter of ctx: a flat cost per message and a cost per byte of the resulting sdk.Msgs.
+// A message that fails to encode is only charged the flat cost, as no sdk.Msgs were built for it.
+//
+// The sdk.Msgs are executed in the order they are returned in, so that order is part of consensus. An encoder that
+// expands one message into several must always return them in the same order, e.g. Staking.Withdraw with a
+// recipient returns the MsgSetWithdrawAddress before the MsgWithdrawDelegatorReward, so the rewards already go
+// to the new address.
+func (e MessageEncoders) Encode(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg) ([]sdk.Msg, error) {
+	ctx.GasMeter().ConsumeGas(types.EncodeMsgCost, "encode contract message")
+
+	if err := sdk.VerifyAddressFormat(contractAddr); err != nil {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "sender: %s", err)
+	}
+	if err := validateSingleVariant(msg); err != nil {
+		return nil, err
+	}
+	msg, err := resolveRelativeIBCTimeout(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateVestingEndTime(ctx, msg); err != nil {
+		return nil, err
+	}
+	if err := validateSendDeadline(ctx, msg); err != nil {
+		return nil, err
+	}
+	var sdkMsgs []sdk.Msg
+	if msg.Authz != nil {
+		sdkMsgs, err = e.encodeAuthzMsg(ctx, contractAddr, msg.Authz)
+	} else {
+		sdkMsgs, err = e.encode(contractAddr, msg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sdkMsg := range sdkMsgs {
+		ctx.GasMeter().ConsumeGas(types.EncodeByteCost*uint64(proto.Size(sdkMsg)), "encode contract message bytes")
+	}
+	return sdkMsgs, nil
+}
+
+// EncodeBatch encodes all messages emitted by a contract and returns the resulting sdk.Msgs in order.
+// It stops at the first message that fails to encode and reports its index in the error.
+//
+// Gas pays for the encoding work done, not for dispatching: if the message at index N fails, the messages before it
+// are still charged in full and the failed one as described in Encode, but the messages after it aren't charged.
+// Nothing is refunded, even though none of the sdk.Msgs are dispatched.
+func (e MessageEncoders) EncodeBatch(ctx sdk.Context, contractAddr sdk.AccAddress, msgs []wasmTypes.CosmosMsg) ([]sdk.Msg, error) {
+	encoded, err := e.EncodeWithMapping(ctx, contractAddr, msgs)
+	if err != nil {
+		return nil, err
+	}
+	sdkMsgs := make([]sdk.Msg, len(encoded))
+	for i, m := range encoded {
+		sdkMsgs[i] = m.Msg
+	}
+	return sdkMsgs, nil
+}
+
+// EncodedMsg is an sdk.Msg together with the index of the contract message it was encoded from
+type EncodedMsg struct {
+	SourceIndex int
+	Msg         sdk.Msg
+}
+
+// EncodeWithMapping is like EncodeBatch, but keeps track of which contract message each sdk.Msg came from,
+// as one contract message can be encoded into several sdk.Msgs.
+func (e MessageEncoders) EncodeWithMapping(ctx sdk.Context, contractAddr sdk.AccAddress, msgs []wasmTypes.CosmosMsg) ([]EncodedMsg, error) {
+	res := make([]EncodedMsg, 0, len(msgs))
+	for i, msg := range msgs {
+		sdkMsgs, err := e.Encode(ctx, contractAddr, msg)
+		if err != nil {
+			return nil, sdkerrors.Wrapf(err, "message at index %d", i)
+		}
+		for _, sdkMsg := range sdkMsgs {
+			res = append(res, EncodedMsg{SourceIndex: i, Msg: sdkMsg})
+		}
+	}
+	return res, nil
+}
+
+// EstimateEncodeGas returns the gas Encode charges a contract for emitting msgs, assuming each one was encoded from
+// its own contract message. It doesn't include the gas the messages use when they are handled.
+func EstimateEncodeGas(msgs []sdk.Msg) uint64 {
+	var gas uint64
+	for _, msg := range msgs {
+		gas += types.EncodeMsgCost + types.EncodeByteCost*uint64(proto.Size(msg))
+	}
+	return gas
+}
+
+// EstimateEncodeFee returns the fee for EstimateEncodeGas(msgs) at the minimum gas prices of the node, rounded up.
+// It is empty if the node accepts any gas price.
+func EstimateEncodeFee(ctx sdk.Context, msgs []sdk.Msg) sdk.Coins {
+	gas := sdk.NewDec(int64(EstimateEncodeGas(msgs)))
+	fee := sdk.NewCoins()
+	for _, gp := range ctx.MinGasPrices() {
+		fee = fee.Add(sdk.NewCoin(gp.Denom, gp.Amount.Mul(gas).Ceil().RoundInt()))
+	}
+	return fee
+}
+
+// validateSingleVariant rejects a message with more than one variant set, rather than
+// silently executing whichever variant happens to be checked first
+func validateSingleVariant(msg wasmTypes.CosmosMsg) error {
+	set := 0
+	for _, isSet := range []bool{
+		msg.Bank != nil,
+		msg.Custom != nil,
+		msg.Staking != nil,
+		msg.Wasm != nil,
+		msg.Gov != nil,
+		msg.IBC != nil,
+		msg.Distribution != nil,
+		msg.Stargate != nil,
+		msg.Vesting != nil,
+		msg.Feegrant != nil,
+		msg.Authz != nil,
+		msg.Slashing != nil,
+	} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return sdkerrors.Wrapf(types.ErrInvalidMsg, "message must set exactly one variant, got %d", set)
+	}
+	return nil
+}
+
+func (e MessageEncoders) encode(contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg) ([]sdk.Msg, error) {
 	switch {
 	case msg.Bank != nil:
 		return e.Bank(contractAddr, msg.Bank)
 	case msg.Custom != nil:
-		return e.Custom(contractAddr, msg.Custom)
+		return e.encodeCustom(contractAddr, msg.Custom)
 	case msg.Staking != nil:
 		return e.Staking(contractAddr, msg.Staking)
 	case msg.Wasm != nil:
 		return e.Wasm(contractAddr, msg.Wasm)
 	case msg.Gov != nil:
 		return e.Gov(contractAddr, msg.Gov)
+	case msg.IBC != nil:
+		return e.IBC(contractAddr, msg.IBC)
+	case msg.Distribution != nil:
+		return e.Distribution(contractAddr, msg.Distribution)
+	case msg.Stargate != nil:
+		if e.Stargate == nil {
+			return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Stargate variant not supported")
+		}
+		return e.Stargate(contractAddr, msg.Stargate)
+	case msg.Vesting != nil:
+		return e.Vesting(contractAddr, msg.Vesting)
+	case msg.Feegrant != nil:
+		return e.Feegrant(contractAddr, msg.Feegrant)
+	case msg.Slashing != nil:
+		return e.Slashing(contractAddr, msg.Slashing)
 	}
 
-	return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Unknown variant of Wasm")
+	// no variant is set, e.g. the contract sent `{}` or only variants this version doesn't know about
+	return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "empty message, no known variant is set")
+}
+
+// encodeCustom dispatches a custom message of the form `{"<variant>": <payload>}` to the encoder registered
+// for that variant, passing it the raw payload. Anything else falls through to the generic Custom encoder.
+func (e MessageEncoders) encodeCustom(sender sdk.AccAddress, msg json.RawMessage) ([]sdk.Msg, error) {
+	if len(e.CustomEncoders) != 0 {
+		var variants map[string]json.RawMessage
+		if err := json.Unmarshal(msg, &variants); err == nil && len(variants) == 1 {
+			for name, payload := range variants {
+				if encoder, ok := e.CustomEncoders[name]; ok {
+					return encoder(sender, payload)
+				}
+			}
+		}
+	}
+	return e.Custom(sender, msg)
 }
 
 var VoteOptionMap = map[string]string{
@@ -98,28 +340,48 @@ var VoteOptionMap = map[string]string{
 }
 
 func EncodeGovMsg(sender sdk.AccAddress, msg *wasmTypes.GovMsg) ([]sdk.Msg, error) {
-	if msg.Vote == nil {
-		return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Unknown variant of Gov")
-	}
+	switch {
+	case msg.Vote != nil:
+		opt, exists := VoteOptionMap[msg.Vote.VoteOption]
+		if !exists {
+			// if it's not found, let the `VoteOptionFromString` below fail
+			opt = msg.Vote.VoteOption
+		}
 
-	opt, exists := VoteOptionMap[msg.Vote.VoteOption]
-	if !exists {
-		// if it's not found, let the `VoteOptionFromString` below fail
-		opt = msg.Vote.VoteOption
-	}
+		option, err := govtypes.VoteOptionFromString(opt)
+		if err != nil {
+			return nil, err
+		}
 
-	option, err := govtypes.VoteOptionFromString(opt)
-	if err != nil {
-		return nil, err
-	}
+		sdkMsg := govtypes.NewMsgVote(sender, msg.Vote.Proposal, option)
+		return []sdk.Msg{sdkMsg}, nil
+	case msg.SubmitProposal != nil:
+		deposit, err := convertWasmCoins(msg.SubmitProposal.InitialDeposit)
+		if err != nil {
+			return nil, err
+		}
 
-	sdkMsg := govtypes.NewMsgVote(sender, msg.Vote.Proposal, option)
-	return []sdk.Msg{sdkMsg}, nil
+		content := govtypes.NewTextProposal(msg.SubmitProposal.Title, msg.SubmitProposal.Description)
+		sdkMsg, err := govtypes.NewMsgSubmitProposal(content, deposit, sender)
+		if err != nil {
+			return nil, sdkerrors.Wrap(types.ErrInvalidMsg, err.Error())
+		}
+		return []sdk.Msg{sdkMsg}, nil
+	default:
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Gov")
+	}
 }
 
 func EncodeBankMsg(sender sdk.AccAddress, msg *wasmTypes.BankMsg) ([]sdk.Msg, error) {
+	if msg.Burn != nil {
+		// there is no burn sdk.Msg in this SDK version, Burn is executed directly by Keeper.Dispatch
+		return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Burn cannot be encoded into an sdk.Msg")
+	}
+	if msg.MultiSend != nil {
+		return encodeMultiSend(msg.MultiSend)
+	}
 	if msg.Send == nil {
-		return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Unknown variant of Bank")
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Bank")
 	}
 	if len(msg.Send.Amount) == 0 {
 		return nil, nil
@@ -127,14 +389,14 @@ func EncodeBankMsg(sender sdk.AccAddress, msg *wasmTypes.BankMsg) ([]sdk.Msg, er
 	// validate that the addresses are valid
 	_, stderr := sdk.AccAddressFromBech32(msg.Send.FromAddress)
 	if stderr != nil {
-		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Send.FromAddress)
+		return nil, invalidAccAddress(sdkerrors.ErrInvalidAddress, "bank.send.from_address", msg.Send.FromAddress)
 	}
 	_, stderr = sdk.AccAddressFromBech32(msg.Send.ToAddress)
 	if stderr != nil {
-		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Send.ToAddress)
+		return nil, invalidAccAddress(types.ErrInvalidRecipient, "bank.send.to_address", msg.Send.ToAddress)
 	}
 
-	toSend, err := convertWasmCoinsToSdkCoins(msg.Send.Amount)
+	toSend, err := convertWasmCoins(msg.Send.Amount)
 	if err != nil {
 		return nil, err
 	}
@@ -146,8 +408,48 @@ func EncodeBankMsg(sender sdk.AccAddress, msg *wasmTypes.BankMsg) ([]sdk.Msg, er
 	return []sdk.Msg{&sdkMsg}, nil
 }
 
+func encodeMultiSend(msg *wasmTypes.MultiSendMsg) ([]sdk.Msg, error) {
+	if len(msg.Inputs) == 0 {
+		return nil, banktypes.ErrNoInputs
+	}
+	if len(msg.Outputs) == 0 {
+		return nil, banktypes.ErrNoOutputs
+	}
+	var sdkMsg banktypes.MsgMultiSend
+	var totalIn, totalOut sdk.Coins
+	for i, input := range msg.Inputs {
+		addr, err := sdk.AccAddressFromBech32(input.Address)
+		if err != nil {
+			return nil, invalidAccAddress(sdkerrors.ErrInvalidAddress, fmt.Sprintf("bank.multi_send.inputs[%d].address", i), input.Address)
+		}
+		coins, err := convertWasmCoins(input.Coins)
+		if err != nil {
+			return nil, err
+		}
+		sdkMsg.Inputs = append(sdkMsg.Inputs, banktypes.NewInput(addr, coins))
+		totalIn = totalIn.Add(coins...)
+	}
+	for i, output := range msg.Outputs {
+		addr, err := sdk.AccAddressFromBech32(output.Address)
+		if err != nil {
+			return nil, invalidAccAddress(types.ErrInvalidRecipient, fmt.Sprintf("bank.multi_send.outputs[%d].address", i), output.Address)
+		}
+		coins, err := convertWasmCoins(output.Coins)
+		if err != nil {
+			return nil, err
+		}
+		sdkMsg.Outputs = append(sdkMsg.Outputs, banktypes.NewOutput(addr, coins))
+		totalOut = totalOut.Add(coins...)
+	}
+	if !totalIn.IsEqual(totalOut) {
+		return nil, sdkerrors.Wrapf(banktypes.ErrInputOutputMismatch, "inputs %s, outputs %s", totalIn, totalOut)
+	}
+	return []sdk.Msg{&sdkMsg}, nil
+}
+
+// NoCustomMsg is the default Custom encoder. It rejects every custom message, rather than silently dropping it.
 func NoCustomMsg(sender sdk.AccAddress, msg json.RawMessage) ([]sdk.Msg, error) {
-	return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Custom variant not supported")
+	return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "no custom handler registered")
 }
 
 func EncodeStakingMsg(sender sdk.AccAddress, msg *wasmTypes.StakingMsg) ([]sdk.Msg, error) {
@@ -157,12 +459,15 @@ func EncodeStakingMsg(sender sdk.AccAddress, msg *wasmTypes.StakingMsg) ([]sdk.M
 		// Check that the address belongs to a validator.
 		validator, err := sdk.ValAddressFromBech32(msg.Delegate.Validator)
 		if err != nil {
-			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Delegate.Validator)
+			return nil, invalidValAddress("staking.delegate.validator", msg.Delegate.Validator)
 		}
 		coin, err := convertWasmCoinToSdkCoin(msg.Delegate.Amount)
 		if err != nil {
 			return nil, err
 		}
+		if !coin.Amount.IsPositive() {
+			return nil, sdkerrors.Wrap(types.ErrInvalidAmount, "delegation amount must be positive")
+		}
 		//sdkMsg := stakingtypes.MsgDelegate{
 		//	DelegatorAddress: sender.String(),
 		//	ValidatorAddress: msg.Delegate.Validator,
@@ -175,16 +480,22 @@ func EncodeStakingMsg(sender sdk.AccAddress, msg *wasmTypes.StakingMsg) ([]sdk.M
 		// Check that the addresses belong to validators.
 		_, err = sdk.ValAddressFromBech32(msg.Redelegate.SrcValidator)
 		if err != nil {
-			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Redelegate.SrcValidator)
+			return nil, invalidValAddress("staking.redelegate.src_validator", msg.Redelegate.SrcValidator)
 		}
 		_, err = sdk.ValAddressFromBech32(msg.Redelegate.DstValidator)
 		if err != nil {
-			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Redelegate.DstValidator)
+			return nil, invalidValAddress("staking.redelegate.dst_validator", msg.Redelegate.DstValidator)
+		}
+		if msg.Redelegate.SrcValidator == msg.Redelegate.DstValidator {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "cannot redelegate to the same validator")
 		}
 		coin, err := convertWasmCoinToSdkCoin(msg.Redelegate.Amount)
 		if err != nil {
 			return nil, err
 		}
+		if !coin.Amount.IsPositive() {
+			return nil, sdkerrors.Wrap(types.ErrInvalidAmount, "redelegation amount must be positive")
+		}
 		sdkMsg := stakingtypes.MsgBeginRedelegate{
 			DelegatorAddress:    sender.String(),
 			ValidatorSrcAddress: msg.Redelegate.SrcValidator,
@@ -196,12 +507,15 @@ func EncodeStakingMsg(sender sdk.AccAddress, msg *wasmTypes.StakingMsg) ([]sdk.M
 		// Check that the address belongs to a validator.
 		_, err = sdk.ValAddressFromBech32(msg.Undelegate.Validator)
 		if err != nil {
-			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Undelegate.Validator)
+			return nil, invalidValAddress("staking.undelegate.validator", msg.Undelegate.Validator)
 		}
 		coin, err := convertWasmCoinToSdkCoin(msg.Undelegate.Amount)
 		if err != nil {
 			return nil, err
 		}
+		if !coin.Amount.IsPositive() {
+			return nil, sdkerrors.Wrap(types.ErrInvalidAmount, "undelegation amount must be positive")
+		}
 		sdkMsg := stakingtypes.MsgUndelegate{
 			DelegatorAddress: sender.String(),
 			ValidatorAddress: msg.Undelegate.Validator,
@@ -210,31 +524,250 @@ func EncodeStakingMsg(sender sdk.AccAddress, msg *wasmTypes.StakingMsg) ([]sdk.M
 		return []sdk.Msg{&sdkMsg}, nil
 	case msg.Withdraw != nil:
 		senderAddr := sender.String()
-		rcpt := senderAddr
+		var msgs []sdk.Msg
+		// Only change the withdraw address if the contract asked for a recipient. Without one the rewards go to
+		// whatever withdraw address is currently set. A recipient equal to the contract itself is still set, as the
+		// current withdraw address may point elsewhere. The address is changed first, so these rewards already go
+		// to the recipient (see Encode).
 		if len(msg.Withdraw.Recipient) != 0 {
 			// Check that the address belongs to a real account.
 			_, err = sdk.AccAddressFromBech32(msg.Withdraw.Recipient)
 			if err != nil {
-				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Withdraw.Recipient)
+				return nil, invalidAccAddress(types.ErrInvalidRecipient, "staking.withdraw.recipient", msg.Withdraw.Recipient)
 			}
-			rcpt = msg.Withdraw.Recipient
+			msgs = append(msgs, &distrtypes.MsgSetWithdrawAddress{
+				DelegatorAddress: senderAddr,
+				WithdrawAddress:  msg.Withdraw.Recipient,
+			})
 		}
 		// Check that the address belongs to a validator.
 		_, err = sdk.ValAddressFromBech32(msg.Withdraw.Validator)
 		if err != nil {
-			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Withdraw.Validator)
-		}
-		setMsg := distrtypes.MsgSetWithdrawAddress{
-			DelegatorAddress: senderAddr,
-			WithdrawAddress:  rcpt,
+			return nil, invalidValAddress("staking.withdraw.validator", msg.Withdraw.Validator)
 		}
 		withdrawMsg := distrtypes.MsgWithdrawDelegatorReward{
 			DelegatorAddress: senderAddr,
 			ValidatorAddress: msg.Withdraw.Validator,
 		}
-		return []sdk.Msg{&setMsg, &withdrawMsg}, nil
+		return append(msgs, &withdrawMsg), nil
+	default:
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Staking")
+	}
+}
+
+func EncodeVestingMsg(sender sdk.AccAddress, msg *wasmTypes.VestingMsg) ([]sdk.Msg, error) {
+	switch {
+	case msg.CreateVestingAccount != nil:
+		to, err := sdk.AccAddressFromBech32(msg.CreateVestingAccount.To)
+		if err != nil {
+			return nil, invalidAccAddress(types.ErrInvalidRecipient, "vesting.create_vesting_account.to", msg.CreateVestingAccount.To)
+		}
+		amount, err := normalizeFunds(msg.CreateVestingAccount.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if amount.Empty() {
+			return nil, sdkerrors.Wrap(types.ErrInvalidAmount, "vesting amount must not be empty")
+		}
+		// Encode checks that it is in the future, which needs the block time
+		if msg.CreateVestingAccount.EndTime <= 0 {
+			return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "vesting end time must be positive")
+		}
+		sdkMsg := vestingtypes.NewMsgCreateVestingAccount(sender, to, amount, msg.CreateVestingAccount.EndTime, msg.CreateVestingAccount.Delayed)
+		return []sdk.Msg{sdkMsg}, nil
+	default:
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Vesting")
+	}
+}
+
+// encodeAuthzMsg encodes the messages of an Exec as if the granter sent them, and wraps them into a MsgExec the
+// contract sends as the grantee. The authz module checks that the granter authorized the contract to send each of them.
+// It needs the other encoders for that, which is why it isn't one of MessageEncoders.
+func (e MessageEncoders) encodeAuthzMsg(ctx sdk.Context, contractAddr sdk.AccAddress, msg *wasmTypes.AuthzMsg) ([]sdk.Msg, error) {
+	if msg.Exec == nil {
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Authz")
+	}
+	granter, err := sdk.AccAddressFromBech32(msg.Exec.Granter)
+	if err != nil {
+		return nil, invalidAccAddress(sdkerrors.ErrInvalidAddress, "authz.exec.granter", msg.Exec.Granter)
+	}
+	if len(msg.Exec.Msgs) == 0 {
+		return nil, sdkerrors.Wrap(types.ErrEmpty, "authz exec messages")
+	}
+	var msgs []sdk.Msg
+	for i, inner := range msg.Exec.Msgs {
+		if inner.Authz != nil {
+			return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "authz exec can't be nested")
+		}
+		sdkMsgs, err := e.Encode(ctx, granter, inner)
+		if err != nil {
+			return nil, sdkerrors.Wrapf(err, "authz exec message at index %d", i)
+		}
+		msgs = append(msgs, sdkMsgs...)
+	}
+	exec := authz.NewMsgExec(contractAddr, msgs)
+	return []sdk.Msg{&exec}, nil
+}
+
+func EncodeFeegrantMsg(sender sdk.AccAddress, msg *wasmTypes.FeegrantMsg) ([]sdk.Msg, error) {
+	switch {
+	case msg.GrantAllowance != nil:
+		grantee, err := sdk.AccAddressFromBech32(msg.GrantAllowance.Grantee)
+		if err != nil {
+			return nil, invalidAccAddress(sdkerrors.ErrInvalidAddress, "feegrant.grant_allowance.grantee", msg.GrantAllowance.Grantee)
+		}
+		allowance, err := convertFeeAllowance(msg.GrantAllowance.Allowance)
+		if err != nil {
+			return nil, err
+		}
+		if err := allowance.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		sdkMsg, err := feegrant.NewMsgGrantAllowance(allowance, sender, grantee)
+		if err != nil {
+			return nil, err
+		}
+		return []sdk.Msg{sdkMsg}, nil
+	default:
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Feegrant")
+	}
+}
+
+// maxAllowancePeriod is the longest period of a periodic fee allowance that fits in a time.Duration
+const maxAllowancePeriod = uint64(math.MaxInt64 / int64(time.Second))
+
+func convertFeeAllowance(allowance wasmTypes.FeeAllowance) (feegrant.FeeAllowanceI, error) {
+	switch {
+	case allowance.Basic != nil && allowance.Periodic != nil:
+		return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "fee allowance must be either basic or periodic")
+	case allowance.Basic != nil:
+		basic, err := convertBasicAllowance(*allowance.Basic)
+		if err != nil {
+			return nil, err
+		}
+		return &basic, nil
+	case allowance.Periodic != nil:
+		basic, err := convertBasicAllowance(allowance.Periodic.Basic)
+		if err != nil {
+			return nil, err
+		}
+		if allowance.Periodic.Period == 0 || allowance.Periodic.Period > maxAllowancePeriod {
+			return nil, sdkerrors.Wrapf(types.ErrInvalidMsg, "invalid allowance period of %d seconds", allowance.Periodic.Period)
+		}
+		periodLimit, err := normalizeFunds(allowance.Periodic.PeriodSpendLimit)
+		if err != nil {
+			return nil, err
+		}
+		if periodLimit.Empty() {
+			return nil, sdkerrors.Wrap(types.ErrInvalidAmount, "period spend limit must not be empty")
+		}
+		// the period starts when the grantee first uses the allowance
+		return &feegrant.PeriodicAllowance{
+			Basic:            basic,
+			Period:           time.Duration(allowance.Periodic.Period) * time.Second,
+			PeriodSpendLimit: periodLimit,
+			PeriodCanSpend:   periodLimit,
+		}, nil
+	default:
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of fee allowance")
+	}
+}
+
+func convertBasicAllowance(allowance wasmTypes.BasicAllowance) (feegrant.BasicAllowance, error) {
+	spendLimit, err := normalizeFunds(allowance.SpendLimit)
+	if err != nil {
+		return feegrant.BasicAllowance{}, err
+	}
+	basic := feegrant.BasicAllowance{SpendLimit: spendLimit}
+	if allowance.Expiration < 0 {
+		return feegrant.BasicAllowance{}, sdkerrors.Wrap(types.ErrInvalidMsg, "allowance expiration must not be negative")
+	}
+	if allowance.Expiration != 0 {
+		expiration := time.Unix(allowance.Expiration, 0).UTC()
+		basic.Expiration = &expiration
+	}
+	return basic, nil
+}
+
+// validateVestingEndTime rejects a vesting account that would have vested already, as the encoders don't know
+// the block time.
+func validateVestingEndTime(ctx sdk.Context, msg wasmTypes.CosmosMsg) error {
+	if msg.Vesting == nil || msg.Vesting.CreateVestingAccount == nil {
+		return nil
+	}
+	if endTime := msg.Vesting.CreateVestingAccount.EndTime; endTime <= ctx.BlockTime().Unix() {
+		return sdkerrors.Wrapf(types.ErrInvalidMsg, "vesting end time %d is not after the block time", endTime)
+	}
+	return nil
+}
+
+// validateSendDeadline rejects a bank send after its deadline height, as the encoders don't know the block height
+func validateSendDeadline(ctx sdk.Context, msg wasmTypes.CosmosMsg) error {
+	if msg.Bank == nil || msg.Bank.Send == nil || msg.Bank.Send.DeadlineHeight == 0 {
+		return nil
+	}
+	if deadline := msg.Bank.Send.DeadlineHeight; uint64(ctx.BlockHeight()) > deadline {
+		return sdkerrors.Wrapf(types.ErrDeadlineExceeded, "bank send deadline height %d is before the block height %d", deadline, ctx.BlockHeight())
+	}
+	return nil
+}
+
+func EncodeDistributionMsg(sender sdk.AccAddress, msg *wasmTypes.DistributionMsg) ([]sdk.Msg, error) {
+	switch {
+	case msg.SetWithdrawAddress != nil:
+		// Check that the address belongs to a real account.
+		_, err := sdk.AccAddressFromBech32(msg.SetWithdrawAddress.Address)
+		if err != nil {
+			return nil, invalidAccAddress(types.ErrInvalidRecipient, "distribution.set_withdraw_address.address", msg.SetWithdrawAddress.Address)
+		}
+		sdkMsg := distrtypes.MsgSetWithdrawAddress{
+			DelegatorAddress: sender.String(),
+			WithdrawAddress:  msg.SetWithdrawAddress.Address,
+		}
+		return []sdk.Msg{&sdkMsg}, nil
+	case msg.FundCommunityPool != nil:
+		amount, err := normalizeFunds(msg.FundCommunityPool.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if amount.Empty() {
+			return nil, sdkerrors.Wrap(types.ErrInvalidAmount, "community pool funding must not be empty")
+		}
+		sdkMsg := distrtypes.MsgFundCommunityPool{
+			Amount:    amount,
+			Depositor: sender.String(),
+		}
+		return []sdk.Msg{&sdkMsg}, nil
+	case msg.WithdrawValidatorCommission != nil:
+		validator, err := sdk.ValAddressFromBech32(msg.WithdrawValidatorCommission.Validator)
+		if err != nil {
+			return nil, invalidValAddress("distribution.withdraw_validator_commission.validator", msg.WithdrawValidatorCommission.Validator)
+		}
+		// only the operator can withdraw the commission, so the contract has to be the validator operator
+		if !validator.Equals(sdk.ValAddress(sender)) {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "contract is not the operator of validator %s", validator)
+		}
+		sdkMsg := distrtypes.NewMsgWithdrawValidatorCommission(validator)
+		return []sdk.Msg{sdkMsg}, nil
+	default:
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Distribution")
+	}
+}
+
+func EncodeSlashingMsg(sender sdk.AccAddress, msg *wasmTypes.SlashingMsg) ([]sdk.Msg, error) {
+	switch {
+	case msg.Unjail != nil:
+		validator, err := sdk.ValAddressFromBech32(msg.Unjail.Validator)
+		if err != nil {
+			return nil, invalidValAddress("slashing.unjail.validator", msg.Unjail.Validator)
+		}
+		// only the operator can unjail its validator
+		if !validator.Equals(sdk.ValAddress(sender)) {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "contract is not the operator of validator %s", validator)
+		}
+		return []sdk.Msg{slashingtypes.NewMsgUnjail(validator)}, nil
 	default:
-		return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Unknown variant of Staking")
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Slashing")
 	}
 }
 
@@ -243,9 +776,12 @@ func EncodeWasmMsg(sender sdk.AccAddress, msg *wasmTypes.WasmMsg) ([]sdk.Msg, er
 	case msg.Execute != nil:
 		contractAddr, err := sdk.AccAddressFromBech32(msg.Execute.ContractAddr)
 		if err != nil {
-			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, msg.Execute.ContractAddr)
+			return nil, invalidAccAddress(sdkerrors.ErrInvalidAddress, "wasm.execute.contract_addr", msg.Execute.ContractAddr)
+		}
+		if err := validateCodeHashFormat(msg.Execute.CallbackCodeHash); err != nil {
+			return nil, err
 		}
-		coins, err := convertWasmCoinsToSdkCoins(msg.Execute.Send)
+		coins, err := normalizeFunds(msg.Execute.Send)
 		if err != nil {
 			return nil, err
 		}
@@ -260,15 +796,17 @@ func EncodeWasmMsg(sender sdk.AccAddress, msg *wasmTypes.WasmMsg) ([]sdk.Msg, er
 		}
 		return []sdk.Msg{&sdkMsg}, nil
 	case msg.Instantiate != nil:
-		coins, err := convertWasmCoinsToSdkCoins(msg.Instantiate.Send)
+		if err := types.ValidateLabel(msg.Instantiate.Label); err != nil {
+			return nil, err
+		}
+		coins, err := normalizeFunds(msg.Instantiate.Send)
 		if err != nil {
 			return nil, err
 		}
 
 		sdkMsg := types.MsgInstantiateContract{
-			Sender: sender,
-			CodeID: msg.Instantiate.CodeID,
-			// TODO: add this to CosmWasm
+			Sender:           sender,
+			CodeID:           msg.Instantiate.CodeID,
 			Label:            msg.Instantiate.Label,
 			CallbackCodeHash: msg.Instantiate.CallbackCodeHash,
 			InitMsg:          msg.Instantiate.Msg,
@@ -276,25 +814,800 @@ func EncodeWasmMsg(sender sdk.AccAddress, msg *wasmTypes.WasmMsg) ([]sdk.Msg, er
 			CallbackSig:      msg.Instantiate.CallbackSignature,
 		}
 		return []sdk.Msg{&sdkMsg}, nil
+	case msg.Instantiate2 != nil:
+		if err := types.ValidateLabel(msg.Instantiate2.Label); err != nil {
+			return nil, err
+		}
+		if err := types.ValidateSalt(msg.Instantiate2.Salt); err != nil {
+			return nil, err
+		}
+		if _, err := normalizeFunds(msg.Instantiate2.Send); err != nil {
+			return nil, err
+		}
+		// MsgInstantiateContract has no salt in this version, Instantiate2 is executed directly by Keeper.Dispatch
+		return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Instantiate2 cannot be encoded into an sdk.Msg")
+	case msg.StoreCode != nil:
+		sdkMsg := types.MsgStoreCode{
+			Sender:       sender,
+			WASMByteCode: msg.StoreCode.WasmBytes,
+			Source:       msg.StoreCode.Source,
+			Builder:      msg.StoreCode.Builder,
+		}
+		// checks the code against MaxWasmSize before the contract pays for storing it
+		if err := sdkMsg.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		return []sdk.Msg{&sdkMsg}, nil
 	default:
-		return nil, sdkerrors.Wrap(types.ErrInvalidMsg, "Unknown variant of Wasm")
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of Wasm")
+	}
+}
+
+func EncodeIBCMsg(sender sdk.AccAddress, msg *wasmTypes.IBCMsg) ([]sdk.Msg, error) {
+	switch {
+	case msg.Transfer != nil:
+		sourcePort := msg.Transfer.SourcePort
+		if sourcePort == "" {
+			sourcePort = ibctransfertypes.PortID
+		}
+		if err := host.PortIdentifierValidator(sourcePort); err != nil {
+			return nil, sdkerrors.Wrap(types.ErrInvalidMsg, err.Error())
+		}
+		if err := host.ChannelIdentifierValidator(msg.Transfer.ChannelID); err != nil {
+			return nil, sdkerrors.Wrap(types.ErrInvalidMsg, err.Error())
+		}
+		if len(msg.Transfer.ToAddress) == 0 {
+			return nil, sdkerrors.Wrap(types.ErrInvalidRecipient, "empty transfer recipient")
+		}
+		coin, err := convertWasmCoinToSdkCoin(msg.Transfer.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		timeoutHeight, err := convertIBCTimeout(msg.Transfer.Timeout)
+		if err != nil {
+			return nil, sdkerrors.Wrap(err, "IBC transfer")
+		}
+
+		sdkMsg := ibctransfertypes.MsgTransfer{
+			SourcePort:       sourcePort,
+			SourceChannel:    msg.Transfer.ChannelID,
+			Token:            coin,
+			Sender:           sender.String(),
+			Receiver:         msg.Transfer.ToAddress,
+			TimeoutHeight:    timeoutHeight,
+			TimeoutTimestamp: msg.Transfer.Timeout.Timestamp,
+		}
+		return []sdk.Msg{&sdkMsg}, nil
+	default:
+		return nil, sdkerrors.Wrap(types.ErrUnknownMsgVariant, "Unknown variant of IBC")
+	}
+}
+
+// resolveRelativeIBCTimeout returns msg with a relative IBC timeout replaced by the absolute timeout height it is
+// relative to ctx. The encoders don't know the current height, so this is done before encoding.
+//
+// Note the height is that of this chain, while the counterparty chain checks the timeout against its own height,
+// so a relative timeout only makes sense if the heights of the chains are close enough.
+func resolveRelativeIBCTimeout(ctx sdk.Context, msg wasmTypes.CosmosMsg) (wasmTypes.CosmosMsg, error) {
+	if msg.IBC == nil || msg.IBC.Transfer == nil {
+		return msg, nil
+	}
+	ibcMsg := *msg.IBC
+	transfer := *ibcMsg.Transfer
+	ibcMsg.Transfer = &transfer
+	timeout := &transfer.Timeout
+	if timeout.RelativeBlocks == 0 {
+		return msg, nil
+	}
+	if timeout.Block != nil {
+		return msg, sdkerrors.Wrap(types.ErrInvalidMsg, "IBC timeout can't set both an absolute and a relative block height")
+	}
+	timeout.Block = &wasmTypes.IBCTimeoutBlock{
+		Revision: clienttypes.ParseChainID(ctx.ChainID()),
+		Height:   uint64(ctx.BlockHeight()) + timeout.RelativeBlocks,
+	}
+	timeout.RelativeBlocks = 0
+	// don't modify the message of the caller
+	msg.IBC = &ibcMsg
+	return msg, nil
+}
+
+// convertIBCTimeout returns the timeout height of timeout, and an error if it would never time out
+func convertIBCTimeout(timeout wasmTypes.IBCTimeout) (clienttypes.Height, error) {
+	if timeout.RelativeBlocks != 0 {
+		// Encode resolves relative timeouts, so the encoder was called directly
+		return clienttypes.Height{}, sdkerrors.Wrap(types.ErrInvalidMsg, "relative timeout must be resolved against the current height")
+	}
+	var timeoutHeight clienttypes.Height
+	if timeout.Block != nil {
+		timeoutHeight = clienttypes.NewHeight(timeout.Block.Revision, timeout.Block.Height)
+	}
+	// a zero height and a zero timestamp both mean "no timeout", so the packet could never time out
+	if timeoutHeight.IsZero() && timeout.Timestamp == 0 {
+		return timeoutHeight, sdkerrors.Wrap(types.ErrInvalidMsg, "requires a non-zero timeout height or timestamp")
+	}
+	return timeoutHeight, nil
+}
+
+// EncodeStargateMsg decodes the protobuf Any carried by the message into the sdk.Msg registered for its type URL.
+// The fields are taken as encoded by the contract, e.g. coin amounts aren't converted from and to strings like the
+// amounts of the other variants are.
+//
+// The concrete type of a type URL is only looked up in the interface registry the first time the encoder sees it,
+// so a batch of messages of the same type resolves once.
+func EncodeStargateMsg(unpacker codectypes.AnyUnpacker) StargateEncoder {
+	// type URL => reflect.Type of the sdk.Msg struct. The registry doesn't change once the app is set up, so the
+	// types stay valid as long as the encoder lives. Queries may encode concurrently with the block, hence sync.Map.
+	var msgTypes sync.Map
+	return func(sender sdk.AccAddress, msg *wasmTypes.StargateMsg) ([]sdk.Msg, error) {
+		var sdkMsg sdk.Msg
+		if typ, ok := msgTypes.Load(msg.TypeURL); ok {
+			sdkMsg = reflect.New(typ.(reflect.Type)).Interface().(sdk.Msg)
+			if err := proto.Unmarshal(msg.Value, sdkMsg); err != nil {
+				return nil, sdkerrors.Wrapf(types.ErrInvalidMsg, "cannot unpack proto message with type URL %s: %s", msg.TypeURL, err.Error())
+			}
+		} else {
+			any := codectypes.Any{
+				TypeUrl: msg.TypeURL,
+				Value:   msg.Value,
+			}
+			if err := unpacker.UnpackAny(&any, &sdkMsg); err != nil {
+				return nil, sdkerrors.Wrapf(types.ErrInvalidMsg, "cannot unpack proto message with type URL %s: %s", msg.TypeURL, err.Error())
+			}
+			// UnpackAny leaves sdkMsg nil for an empty type URL
+			if sdkMsg != nil {
+				msgTypes.Store(msg.TypeURL, reflect.TypeOf(sdkMsg).Elem())
+			}
+		}
+		if err := codectypes.UnpackInterfaces(sdkMsg, unpacker); err != nil {
+			return nil, sdkerrors.Wrapf(types.ErrInvalidMsg, "cannot unpack interfaces inside proto message with type URL %s: %s", msg.TypeURL, err.Error())
+		}
+		return []sdk.Msg{sdkMsg}, nil
 	}
 }
 
+// SimulateEncodedMsg dispatches msg as if the contract at sender had returned it, on a branch of ctx that is thrown
+// away afterwards, and returns the gas that took. A nil error means the message would succeed. The gas used is
+// charged to ctx as well, so a simulation costs as much as the real thing. It can't use more than the gas left in
+// ctx, a simulation that runs out of it fails with ErrOutOfGas.
+func (k Keeper) SimulateEncodedMsg(ctx sdk.Context, sender sdk.AccAddress, msg wasmTypes.CosmosMsg) (gasUsed uint64, err error) {
+	// the cache is never written, so nothing the messages do is persisted
+	cacheCtx, _ := ctx.CacheContext()
+	// an infinite gas meter has no limit
+	simulationMeter := sdk.NewInfiniteGasMeter()
+	if limit := ctx.GasMeter().Limit(); limit != 0 {
+		simulationMeter = sdk.NewGasMeter(limit - ctx.GasMeter().GasConsumed())
+	}
+	cacheCtx = cacheCtx.WithGasMeter(simulationMeter)
+
+	defer func() {
+		if r := recover(); r != nil {
+			// only catch out of gas of the simulation meter, everything else is a real panic
+			if _, ok := r.(sdk.ErrorOutOfGas); !ok || !simulationMeter.IsOutOfGas() {
+				panic(r)
+			}
+			gasUsed = simulationMeter.Limit()
+			ctx.GasMeter().ConsumeGas(gasUsed, "simulate contract message out of gas")
+			err = sdkerrors.Wrap(sdkerrors.ErrOutOfGas, "simulate contract message")
+		}
+	}()
+	_, _, err = k.Dispatch(cacheCtx, sender, msg)
+	gasUsed = simulationMeter.GasConsumed()
+
+	ctx.GasMeter().ConsumeGas(gasUsed, "simulate contract message")
+	return gasUsed, err
+}
+
 func (k Keeper) Dispatch(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg) (events sdk.Events, data []byte, err error) {
+	return k.dispatch(ctx, contractAddr, msg, k.GetParams(ctx))
+}
 
-	sdkMsgs, err := k.messenger.encoders.Encode(contractAddr, msg)
+// dispatch is Dispatch with the params of the module, which are only read once per message
+func (k Keeper) dispatch(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg, params types.Params) (events sdk.Events, data []byte, err error) {
+	if msg.Wasm != nil && msg.Wasm.Execute != nil && msg.Wasm.Execute.GasLimit != nil {
+		return k.dispatchExecuteWithGasLimit(ctx, contractAddr, *msg.Wasm.Execute, params)
+	}
+	if !params.DispatchEnabled {
+		return nil, nil, sdkerrors.Wrap(types.ErrDispatchPaused, "contract message dispatch is paused by governance")
+	}
+	ctx.GasMeter().ConsumeGas(params.MsgDispatchCost, "dispatch contract message")
+	if err := k.countBlockDispatch(ctx, contractAddr, params.MaxDispatchesPerBlock); err != nil {
+		return nil, nil, err
+	}
+	if err := k.validateMsgVariantAllowed(ctx, contractAddr, msg); err != nil {
+		return nil, nil, err
+	}
+
+	if msg.Bank != nil && msg.Bank.Burn != nil {
+		if err := validateSingleVariant(msg); err != nil {
+			return nil, nil, err
+		}
+		emitEncodedMsg(ctx, contractAddr, msg)
+		return nil, nil, k.burnCoins(ctx, contractAddr, msg.Bank.Burn)
+	}
+
+	// before encoding, so an oversized payload isn't copied into an sdk.Msg
+	if err := validateWasmMsgSize(msg, params); err != nil {
+		return nil, nil, err
+	}
+	if msg.Wasm != nil && msg.Wasm.Instantiate2 != nil {
+		if err := validateSingleVariant(msg); err != nil {
+			return nil, nil, err
+		}
+		emitEncodedMsg(ctx, contractAddr, msg)
+		data, err = k.dispatchInstantiate2(ctx, contractAddr, msg, params)
+		return nil, data, err
+	}
+	sdkMsgs, err := k.messenger.encoders.Encode(ctx, contractAddr, msg)
 	if err != nil {
+		// the callers add the index of the message to the logger of ctx
+		ctx.Logger().Info("failed to encode contract message", "contract", contractAddr.String(), "variant", cosmosMsgVariant(msg), "err", err)
 		return nil, nil, err
 	}
+	if err := validateSelfReference(ctx, contractAddr, msg, params); err != nil {
+		return nil, nil, err
+	}
+	if err := validateSendDenoms(sdkMsgs, params); err != nil {
+		return nil, nil, err
+	}
+	if err := k.validateModuleAccountSend(ctx, sdkMsgs, params); err != nil {
+		return nil, nil, err
+	}
+	if err := validateInstantiateFunds(msg, params); err != nil {
+		return nil, nil, err
+	}
+	if err := k.verifyTargetContractExists(ctx, msg); err != nil {
+		return nil, nil, err
+	}
+	if err := k.verifyCallbackCodeHash(ctx, msg); err != nil {
+		return nil, nil, err
+	}
+	if err := k.verifyIBCSourcePort(ctx, msg); err != nil {
+		return nil, nil, err
+	}
+	if err := k.verifyValidatorsExist(ctx, msg); err != nil {
+		return nil, nil, err
+	}
+	if err := k.validateAuthzMsgs(ctx, contractAddr, msg, params); err != nil {
+		return nil, nil, err
+	}
+	if len(sdkMsgs) != 0 {
+		emitEncodedMsg(ctx, contractAddr, msg)
+	}
 	for _, sdkMsg := range sdkMsgs {
-		_, _, err := k.handleSdkMessage(ctx, contractAddr, sdkMsg)
+		_, data, err = k.handleSdkMessage(ctx, contractAddr, sdkMsg)
 		if err != nil {
 			return nil, nil, err
 		}
-		//return sdkEvents, msgData, err
 	}
-	return nil, nil, nil
+	// if a message was encoded into several sdk.Msgs, the data of the last one is returned
+	return nil, data, nil
+}
+
+// countBlockDispatch counts a message dispatched by the contract in the transient store, and rejects it if the
+// contract already dispatched max messages this block. 0 is no limit.
+func (k Keeper) countBlockDispatch(ctx sdk.Context, contractAddr sdk.AccAddress, max uint32) error {
+	if max == 0 {
+		return nil
+	}
+	store := ctx.TransientStore(k.tStoreKey)
+	key := types.GetDispatchCountKey(contractAddr)
+	var count uint64
+	if bz := store.Get(key); bz != nil {
+		count = sdk.BigEndianToUint64(bz)
+	}
+	if count >= uint64(max) {
+		return sdkerrors.Wrapf(types.ErrTooManyContractMsgs, "contract %s dispatched %d messages this block, the limit is %d", contractAddr, count, max)
+	}
+	store.Set(key, sdk.Uint64ToBigEndian(count+1))
+	return nil
+}
+
+// verifyTargetContractExists rejects a message to a contract that doesn't exist, so a mistyped address fails before
+// the message is dispatched. The encoders only know the address is well-formed.
+func (k Keeper) verifyTargetContractExists(ctx sdk.Context, msg wasmTypes.CosmosMsg) error {
+	if msg.Wasm == nil || msg.Wasm.Execute == nil {
+		return nil
+	}
+	target := msg.Wasm.Execute.ContractAddr
+	contractAddr, err := sdk.AccAddressFromBech32(target)
+	if err != nil {
+		return invalidAccAddress(sdkerrors.ErrInvalidAddress, "wasm.execute.contract_addr", target)
+	}
+	if k.GetContractInfo(ctx, contractAddr) == nil {
+		return sdkerrors.Wrap(types.ErrContractNotFound, target)
+	}
+	return nil
+}
+
+// verifyIBCSourcePort rejects an IBC transfer from a port other than the one of the transfer module, unless the port
+// is bound. Without a port keeper, see WithPortKeeper, only the transfer port is allowed.
+func (k Keeper) verifyIBCSourcePort(ctx sdk.Context, msg wasmTypes.CosmosMsg) error {
+	if msg.IBC == nil || msg.IBC.Transfer == nil {
+		return nil
+	}
+	port := msg.IBC.Transfer.SourcePort
+	if port == "" || port == ibctransfertypes.PortID {
+		return nil
+	}
+	if k.portKeeper == nil || !k.portKeeper.IsBound(ctx, port) {
+		return sdkerrors.Wrapf(types.ErrInvalidMsg, "IBC port %s is not bound", port)
+	}
+	return nil
+}
+
+// validateAuthzMsgs runs the checks Dispatch runs on a message of the contract on each message of an authz Exec, so
+// wrapping a message into one doesn't get around them. The granter sends the messages, so a self reference is one
+// to the granter. The checks on the funds are done on the encoded sdk.Msgs, which include the inner messages.
+func (k Keeper) validateAuthzMsgs(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg, params types.Params) error {
+	if msg.Authz == nil || msg.Authz.Exec == nil {
+		return nil
+	}
+	// Encode already validated the address
+	granter, err := sdk.AccAddressFromBech32(msg.Authz.Exec.Granter)
+	if err != nil {
+		return invalidAccAddress(sdkerrors.ErrInvalidAddress, "authz.exec.granter", msg.Authz.Exec.Granter)
+	}
+	for i, inner := range msg.Authz.Exec.Msgs {
+		if err := k.validateAuthzMsg(ctx, contractAddr, granter, inner, params); err != nil {
+			return sdkerrors.Wrapf(err, "authz exec message at index %d", i)
+		}
+	}
+	return nil
+}
+
+// validateAuthzMsg runs the checks of validateAuthzMsgs on one message of an authz Exec
+func (k Keeper) validateAuthzMsg(ctx sdk.Context, contractAddr sdk.AccAddress, granter sdk.AccAddress, msg wasmTypes.CosmosMsg, params types.Params) error {
+	// the limit of a gas limited execute is enforced by Dispatch, which doesn't see the inner messages
+	if msg.Wasm != nil && msg.Wasm.Execute != nil && msg.Wasm.Execute.GasLimit != nil {
+		return sdkerrors.Wrap(types.ErrInvalidMsg, "an execute in an authz exec can't have a gas limit")
+	}
+	if err := k.validateMsgVariantAllowed(ctx, contractAddr, msg); err != nil {
+		return err
+	}
+	if err := validateWasmMsgSize(msg, params); err != nil {
+		return err
+	}
+	if err := validateSelfReference(ctx, granter, msg, params); err != nil {
+		return err
+	}
+	if err := validateInstantiateFunds(msg, params); err != nil {
+		return err
+	}
+	if err := k.verifyTargetContractExists(ctx, msg); err != nil {
+		return err
+	}
+	if err := k.verifyCallbackCodeHash(ctx, msg); err != nil {
+		return err
+	}
+	if err := k.verifyIBCSourcePort(ctx, msg); err != nil {
+		return err
+	}
+	return k.verifyValidatorsExist(ctx, msg)
+}
+
+// verifyValidatorsExist rejects a delegation, undelegation or redelegation with a validator that doesn't exist, so it
+// fails with a clear error rather than deep inside the staking module. The encoders only know the address is
+// well-formed.
+func (k Keeper) verifyValidatorsExist(ctx sdk.Context, msg wasmTypes.CosmosMsg) error {
+	if msg.Staking == nil {
+		return nil
+	}
+	var validators []string
+	switch {
+	case msg.Staking.Delegate != nil:
+		validators = []string{msg.Staking.Delegate.Validator}
+	case msg.Staking.Undelegate != nil:
+		validators = []string{msg.Staking.Undelegate.Validator}
+	case msg.Staking.Redelegate != nil:
+		validators = []string{msg.Staking.Redelegate.SrcValidator, msg.Staking.Redelegate.DstValidator}
+	}
+	for _, validator := range validators {
+		valAddr, err := sdk.ValAddressFromBech32(validator)
+		if err != nil {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "%s is not a validator address", validator)
+		}
+		if _, found := k.stakingKeeper.GetValidator(ctx, valAddr); !found {
+			return sdkerrors.Wrapf(stakingtypes.ErrNoValidatorFound, "%s", validator)
+		}
+	}
+	return nil
+}
+
+// validateSelfReference rejects messages a contract addresses to itself, if the params of the module ask for it.
+// They are allowed by default, as existing contracts may rely on them.
+func validateSelfReference(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg, params types.Params) error {
+	if msg.Bank != nil && msg.Bank.Send != nil {
+		// Encode already validated the address
+		to, err := sdk.AccAddressFromBech32(msg.Bank.Send.ToAddress)
+		if err == nil && to.Equals(contractAddr) && params.RejectSelfSends {
+			return sdkerrors.Wrap(types.ErrInvalidRecipient, "contract can't send funds to itself")
+		}
+	}
+	if msg.Wasm != nil && msg.Wasm.Execute != nil && len(msg.Wasm.Execute.Send) != 0 {
+		target, err := sdk.AccAddressFromBech32(msg.Wasm.Execute.ContractAddr)
+		if err == nil && target.Equals(contractAddr) {
+			if params.RejectSelfExecutes {
+				return sdkerrors.Wrap(types.ErrInvalidMsg, "contract can't execute itself with funds")
+			}
+			ctx.Logger().Info("contract executes itself with funds", "contract", contractAddr.String())
+		}
+	}
+	return nil
+}
+
+// validateMsgVariantAllowed rejects a message of a variant the code of the contract isn't allowed to dispatch,
+// see SetCodeAllowedMsgVariants
+func (k Keeper) validateMsgVariantAllowed(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg) error {
+	contractInfo := k.GetContractInfo(ctx, contractAddr)
+	if contractInfo == nil {
+		return nil
+	}
+	allowed := k.GetCodeAllowedMsgVariants(ctx, contractInfo.CodeID)
+	if len(allowed) == 0 {
+		return nil
+	}
+	variant := cosmosMsgVariant(msg)
+	for _, a := range allowed {
+		if variant == a || strings.HasPrefix(variant, a+"_") {
+			return nil
+		}
+	}
+	return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "contracts of code %d may not dispatch %s", contractInfo.CodeID, variant)
+}
+
+// wasmMsgEnvelopeSize is how much the enclave adds to the inner msg of a wasm message when it encrypts it: the 32 byte
+// nonce and 32 byte public key of the SecretMessage, the 16 byte AES-SIV tag, and the 64 byte hex code hash of the
+// called contract, which is encrypted along with the msg
+const wasmMsgEnvelopeSize = 32 + 32 + 16 + 64
+
+// validateWasmMsgSize rejects a wasm message with an inner msg larger than the MaxWasmMsgSize param allows.
+// Dispatch only sees the msg encrypted, so the size of the plaintext is that minus wasmMsgEnvelopeSize.
+func validateWasmMsgSize(msg wasmTypes.CosmosMsg, params types.Params) error {
+	if msg.Wasm == nil {
+		return nil
+	}
+	max := params.MaxWasmMsgSize
+	if max == 0 {
+		return nil
+	}
+	var size int
+	switch {
+	case msg.Wasm.Execute != nil:
+		size = len(msg.Wasm.Execute.Msg)
+	case msg.Wasm.Instantiate != nil:
+		size = len(msg.Wasm.Instantiate.Msg)
+	case msg.Wasm.Instantiate2 != nil:
+		size = len(msg.Wasm.Instantiate2.Msg)
+	}
+	size -= wasmMsgEnvelopeSize
+	if size > int(max) {
+		return sdkerrors.Wrapf(types.ErrLimit, "wasm msg of %d bytes exceeds the limit of %d", size, max)
+	}
+	return nil
+}
+
+// validateInstantiateFunds rejects an instantiation that doesn't send along at least the MinInstantiateFunds param
+func validateInstantiateFunds(msg wasmTypes.CosmosMsg, params types.Params) error {
+	if msg.Wasm == nil {
+		return nil
+	}
+	var send wasmTypes.Coins
+	switch {
+	case msg.Wasm.Instantiate != nil:
+		send = msg.Wasm.Instantiate.Send
+	case msg.Wasm.Instantiate2 != nil:
+		send = msg.Wasm.Instantiate2.Send
+	default:
+		return nil
+	}
+	min := params.MinInstantiateFunds
+	if min.Empty() {
+		return nil
+	}
+	// Encode already validated the funds
+	funds, err := normalizeFunds(send)
+	if err != nil {
+		return err
+	}
+	if !funds.IsAllGTE(min) {
+		return sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "instantiate funds %s are less than the minimum of %s", funds, min)
+	}
+	return nil
+}
+
+// sdkMsgsOutputs returns the accounts of this chain the sdk.Msgs send coins to, and all the coins they move out of
+// the sender. It looks at what the messages do rather than at the variant they were encoded from, so e.g. a MsgSend
+// of a Stargate message or inside an authz MsgExec counts the same as a bank send.
+func sdkMsgsOutputs(msgs []sdk.Msg) (recipients []string, coins sdk.Coins, err error) {
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case *banktypes.MsgSend:
+			recipients = append(recipients, msg.ToAddress)
+			coins = append(coins, msg.Amount...)
+		case *banktypes.MsgMultiSend:
+			for _, output := range msg.Outputs {
+				recipients = append(recipients, output.Address)
+				coins = append(coins, output.Coins...)
+			}
+		case *vestingtypes.MsgCreateVestingAccount:
+			recipients = append(recipients, msg.ToAddress)
+			coins = append(coins, msg.Amount...)
+		case *distrtypes.MsgFundCommunityPool:
+			coins = append(coins, msg.Amount...)
+		case *ibctransfertypes.MsgTransfer:
+			coins = append(coins, msg.Token)
+		case *types.MsgExecuteContract:
+			coins = append(coins, msg.SentFunds...)
+		case *types.MsgInstantiateContract:
+			coins = append(coins, msg.InitFunds...)
+		case *authz.MsgExec:
+			inner, err := msg.GetMessages()
+			if err != nil {
+				return nil, nil, err
+			}
+			innerRecipients, innerCoins, err := sdkMsgsOutputs(inner)
+			if err != nil {
+				return nil, nil, err
+			}
+			recipients = append(recipients, innerRecipients...)
+			coins = append(coins, innerCoins...)
+		}
+	}
+	return recipients, coins, nil
+}
+
+// validateSendDenoms rejects messages that send a denom that isn't in the SendDenomAllowlist param to another
+// account, see sdkMsgsOutputs for the messages that count. An empty allowlist allows all denoms.
+func validateSendDenoms(sdkMsgs []sdk.Msg, params types.Params) error {
+	allowlist := params.SendDenomAllowlist
+	if len(allowlist) == 0 {
+		return nil
+	}
+	_, coins, err := sdkMsgsOutputs(sdkMsgs)
+	if err != nil {
+		return err
+	}
+	for _, coin := range coins {
+		allowed := false
+		for _, denom := range allowlist {
+			if coin.Denom == denom {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "contracts may not send %s", coin.Denom)
+		}
+	}
+	return nil
+}
+
+// validateModuleAccountSend rejects messages that send funds to a module account, like the bonded pool, if the
+// RejectModuleAccountSends param is set. See sdkMsgsOutputs for the messages that count.
+func (k Keeper) validateModuleAccountSend(ctx sdk.Context, sdkMsgs []sdk.Msg, params types.Params) error {
+	if !params.RejectModuleAccountSends {
+		return nil
+	}
+	recipients, _, err := sdkMsgsOutputs(sdkMsgs)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		// Encode already validated the address
+		to, err := sdk.AccAddressFromBech32(recipient)
+		if err != nil {
+			continue
+		}
+		if _, ok := k.accountKeeper.GetAccount(ctx, to).(authtypes.ModuleAccountI); ok {
+			return sdkerrors.Wrap(types.ErrInvalidRecipient, "contract can't send funds to a module account")
+		}
+	}
+	return nil
+}
+
+// validateCodeHashFormat checks that a code hash passed along by a contract is empty or a hex encoded sha256 hash
+func validateCodeHashFormat(codeHash string) error {
+	if codeHash == "" {
+		return nil
+	}
+	if len(codeHash) != 2*sha256.Size {
+		return sdkerrors.Wrapf(types.ErrInvalidMsg, "callback code hash must be %d hex characters", 2*sha256.Size)
+	}
+	if _, err := hex.DecodeString(codeHash); err != nil {
+		return sdkerrors.Wrapf(types.ErrInvalidMsg, "callback code hash is not hex: %s", err)
+	}
+	return nil
+}
+
+// verifyCallbackCodeHash checks the callback code hash of a contract execution against the code of the target
+// contract, so a wrong hash fails with a clear error here rather than deep inside the enclave
+func (k Keeper) verifyCallbackCodeHash(ctx sdk.Context, msg wasmTypes.CosmosMsg) error {
+	if msg.Wasm == nil || msg.Wasm.Execute == nil || msg.Wasm.Execute.CallbackCodeHash == "" {
+		return nil
+	}
+	// the address was already validated by the encoder
+	contractAddr, err := sdk.AccAddressFromBech32(msg.Wasm.Execute.ContractAddr)
+	if err != nil {
+		return invalidAccAddress(sdkerrors.ErrInvalidAddress, "wasm.execute.contract_addr", msg.Wasm.Execute.ContractAddr)
+	}
+	contractInfo := k.GetContractInfo(ctx, contractAddr)
+	if contractInfo == nil {
+		return sdkerrors.Wrapf(types.ErrNotFound, "contract %s", contractAddr)
+	}
+	codeInfo := k.GetCodeInfo(ctx, contractInfo.CodeID)
+	if codeInfo == nil {
+		return sdkerrors.Wrapf(types.ErrNotFound, "code %d", contractInfo.CodeID)
+	}
+	if !strings.EqualFold(hex.EncodeToString(codeInfo.CodeHash), msg.Wasm.Execute.CallbackCodeHash) {
+		return sdkerrors.Wrapf(types.ErrInvalidMsg, "callback code hash %s doesn't match the code of contract %s", msg.Wasm.Execute.CallbackCodeHash, contractAddr)
+	}
+	return nil
+}
+
+// emitEncodedMsg emits the encodedMsgEvent of a message dispatched by a contract and counts it by variant. Burn and
+// Instantiate2 aren't encoded into sdk.Msgs, but emit it too, so indexers see every message.
+func emitEncodedMsg(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg) {
+	ctx.EventManager().EmitEvent(encodedMsgEvent(contractAddr, msg))
+	telemetry.IncrCounterWithLabels(
+		[]string{"compute", "keeper", "encoded_msg"},
+		1,
+		[]metrics.Label{telemetry.NewLabel("variant", cosmosMsgVariant(msg))},
+	)
+}
+
+// encodedMsgEvent describes a message dispatched by a contract, so indexers can tell what a contract asked for
+func encodedMsgEvent(contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg) sdk.Event {
+	// marshaling a struct we just unmarshaled can't fail, and the field order is fixed so the hash is deterministic
+	bz, _ := json.Marshal(msg)
+	hash := sha256.Sum256(bz)
+	return sdk.NewEvent(
+		types.EventTypeEncodedMsg,
+		sdk.NewAttribute(types.AttributeKeyContract, contractAddr.String()),
+		sdk.NewAttribute(types.AttributeKeyMsgVariant, cosmosMsgVariant(msg)),
+		sdk.NewAttribute(types.AttributeKeyMsgHash, hex.EncodeToString(hash[:])),
+	)
+}
+
+// cosmosMsgVariant returns a short name for the variant of the message, e.g. "bank_send"
+func cosmosMsgVariant(msg wasmTypes.CosmosMsg) string {
+	switch {
+	case msg.Bank != nil:
+		switch {
+		case msg.Bank.Send != nil:
+			return "bank_send"
+		case msg.Bank.Burn != nil:
+			return "bank_burn"
+		case msg.Bank.MultiSend != nil:
+			return "bank_multi_send"
+		}
+		return "bank"
+	case msg.Custom != nil:
+		return "custom"
+	case msg.Staking != nil:
+		switch {
+		case msg.Staking.Delegate != nil:
+			return "staking_delegate"
+		case msg.Staking.Undelegate != nil:
+			return "staking_undelegate"
+		case msg.Staking.Redelegate != nil:
+			return "staking_redelegate"
+		case msg.Staking.Withdraw != nil:
+			return "staking_withdraw"
+		}
+		return "staking"
+	case msg.Wasm != nil:
+		switch {
+		case msg.Wasm.Execute != nil:
+			return "wasm_execute"
+		case msg.Wasm.Instantiate != nil:
+			return "wasm_instantiate"
+		case msg.Wasm.StoreCode != nil:
+			return "wasm_store_code"
+		case msg.Wasm.Instantiate2 != nil:
+			return "wasm_instantiate2"
+		}
+		return "wasm"
+	case msg.Gov != nil:
+		switch {
+		case msg.Gov.Vote != nil:
+			return "gov_vote"
+		case msg.Gov.SubmitProposal != nil:
+			return "gov_submit_proposal"
+		}
+		return "gov"
+	case msg.IBC != nil:
+		switch {
+		case msg.IBC.Transfer != nil:
+			return "ibc_transfer"
+		}
+		return "ibc"
+	case msg.Distribution != nil:
+		switch {
+		case msg.Distribution.SetWithdrawAddress != nil:
+			return "distribution_set_withdraw_address"
+		case msg.Distribution.FundCommunityPool != nil:
+			return "distribution_fund_community_pool"
+		case msg.Distribution.WithdrawValidatorCommission != nil:
+			return "distribution_withdraw_validator_commission"
+		}
+		return "distribution"
+	case msg.Stargate != nil:
+		return "stargate"
+	case msg.Vesting != nil:
+		if msg.Vesting.CreateVestingAccount != nil {
+			return "vesting_create_vesting_account"
+		}
+		return "vesting"
+	case msg.Feegrant != nil:
+		if msg.Feegrant.GrantAllowance != nil {
+			return "feegrant_grant_allowance"
+		}
+		return "feegrant"
+	case msg.Authz != nil:
+		if msg.Authz.Exec != nil {
+			return "authz_exec"
+		}
+		return "authz"
+	case msg.Slashing != nil:
+		if msg.Slashing.Unjail != nil {
+			return "slashing_unjail"
+		}
+		return "slashing"
+	}
+	return "unknown"
+}
+
+// burnCoins moves the coins from the contract to the compute module account and burns them from there,
+// since the bank module only allows burning from module accounts
+func (k Keeper) burnCoins(ctx sdk.Context, contractAddr sdk.AccAddress, msg *wasmTypes.BurnMsg) error {
+	coins, err := convertWasmCoins(msg.Amount)
+	if err != nil {
+		return err
+	}
+	if coins.Empty() || !coins.IsValid() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, coins.String())
+	}
+
+	if err := k.bankKeeper.SendCoinsFromAccountToModule(ctx, contractAddr, types.ModuleName, coins); err != nil {
+		return err
+	}
+	return k.bankKeeper.BurnCoins(ctx, types.ModuleName, coins)
+}
+
+// dispatchInstantiate2 instantiates the contract of an Instantiate2 message at its predicted address, and returns
+// that address. No sdk.Msg can carry the salt in this version, so like a burn it isn't encoded.
+func (k Keeper) dispatchInstantiate2(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg, params types.Params) ([]byte, error) {
+	inst := msg.Wasm.Instantiate2
+	funds, err := normalizeFunds(inst.Send)
+	if err != nil {
+		return nil, err
+	}
+	// the checks of the MsgInstantiateContract Instantiate is encoded into
+	sdkMsg := types.MsgInstantiateContract{
+		Sender:           contractAddr,
+		CodeID:           inst.CodeID,
+		Label:            inst.Label,
+		CallbackCodeHash: inst.CallbackCodeHash,
+		InitMsg:          inst.Msg,
+		InitFunds:        funds,
+		CallbackSig:      inst.CallbackSignature,
+	}
+	if err := sdkMsg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	if err := validateSendDenoms([]sdk.Msg{&sdkMsg}, params); err != nil {
+		return nil, err
+	}
+	if err := validateInstantiateFunds(msg, params); err != nil {
+		return nil, err
+	}
+	newAddr, err := k.Instantiate2(ctx, inst.CodeID, contractAddr, inst.Msg, inst.Label, funds, inst.CallbackSignature, inst.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return newAddr, nil
 }
 
 func (k Keeper) handleSdkMessage(ctx sdk.Context, contractAddr sdk.Address, msg sdk.Msg) (sdk.Events, []byte, error) {
@@ -309,31 +1622,23 @@ func (k Keeper) handleSdkMessage(ctx sdk.Context, contractAddr sdk.Address, msg
 		}
 	}
 
-	var res *sdk.Result
-	var err error
+	var handler sdk.Handler
 	if legacyMsg, ok := msg.(legacytx.LegacyMsg); ok {
-		msgRoute := legacyMsg.Route()
-		handler := k.messenger.router.Route(ctx, msgRoute)
-		if handler == nil {
-			return nil, nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unrecognized message route: %s", msgRoute)
-		}
-
-		res, err = handler(ctx, msg)
-		if err != nil {
-			return nil, nil, err
+		handler = k.messenger.router.Route(ctx, legacyMsg.Route())
+	}
+	// authz and feegrant only have a Msg service, their legacy routes have no handler
+	if handler == nil {
+		if serviceHandler := k.serviceRouter.Handler(msg); serviceHandler != nil {
+			handler = sdk.Handler(serviceHandler)
 		}
-	} else {
-		return nil, nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unrecognized legacy message route: %s", sdk.MsgTypeURL(msg))
+	}
+	if handler == nil {
+		return nil, nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unrecognized message route: %s", sdk.MsgTypeURL(msg))
+	}
 
-		// todo: grpc routing
-		//handler := k.serviceRouter.Handler(msg)
-		//if handler == nil {
-		//	return nil, nil, sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, sdk.MsgTypeURL(msg))
-		//}
-		//res, err := handler(ctx, msg)
-		//if err != nil {
-		//	return nil, nil, err
-		//}
+	res, err := handler(ctx, msg)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// todo: remove this when adding submessages
@@ -345,41 +1650,109 @@ func (k Keeper) handleSdkMessage(ctx sdk.Context, contractAddr sdk.Address, msg
 	// redispatch all events, (type sdk.EventTypeMessage will be filtered out in the handler)
 	ctx.EventManager().EmitEvents(events)
 
-	// todo: add this when adding submessages
-	//data = make([]byte, len(res.Data))
-	//copy(data, res.Data)
-	//
-	//// convert Tendermint.Events to sdk.Event
-	//sdkEvents := make(sdk.Events, len(res.Events))
-	//for i := range res.Events {
-	//	sdkEvents[i] = sdk.Event(res.Events[i])
-	//}
-
-	// append message action attribute
-	//events = append(events, sdkEvents...)
+	// the data is handed to the contract in the reply to a submessage
+	data := make([]byte, len(res.Data))
+	copy(data, res.Data)
 
-	return nil, nil, nil
+	return nil, data, nil
 }
 
-func convertWasmCoinsToSdkCoins(coins []wasmTypes.Coin) (sdk.Coins, error) {
-	var toSend sdk.Coins
+// convertWasmCoins converts coins emitted by a contract into sdk.Coins sorted by denom.
+// Malformed denoms, malformed or negative amounts and duplicate denoms are rejected here, rather than letting them
+// fail later with an opaque error from the module handling the message. Duplicates are an error rather than being
+// merged, so contract authors notice the bug early. Zero amounts are kept.
+func convertWasmCoins(coins []wasmTypes.Coin) (sdk.Coins, error) {
+	res := make(sdk.Coins, 0, len(coins))
+	seenDenoms := make(map[string]bool, len(coins))
 	for _, coin := range coins {
+		if err := sdk.ValidateDenom(coin.Denom); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, err.Error())
+		}
+		if seenDenoms[coin.Denom] {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "duplicate denom %s", coin.Denom)
+		}
+		seenDenoms[coin.Denom] = true
+
 		c, err := convertWasmCoinToSdkCoin(coin)
 		if err != nil {
 			return nil, err
 		}
-		toSend = append(toSend, c)
+		if c.Amount.IsNegative() {
+			return nil, sdkerrors.Wrapf(types.ErrInvalidAmount, "negative amount %s", c)
+		}
+		res = append(res, c)
 	}
-	return toSend, nil
+	return res.Sort(), nil
 }
 
+// invalidAccAddress wraps err for an account address that failed to parse, as a FieldError of the field of the
+// contract message the address is in. If it has another bech32 prefix than this chain's, e.g. because it is an
+// address of another chain, the error names the expected prefix.
+func invalidAccAddress(err error, field string, addr string) error {
+	return types.NewFieldError(field, wrapAddressPrefixErr(err, addr, sdk.GetConfig().GetBech32AccountAddrPrefix(), addr))
+}
+
+// invalidValAddress is like invalidAccAddress, for validator operator addresses
+func invalidValAddress(field string, addr string) error {
+	return types.NewFieldError(field, wrapAddressPrefixErr(sdkerrors.ErrInvalidAddress, addr, sdk.GetConfig().GetBech32ValidatorAddrPrefix(), addr+" is not a validator address"))
+}
+
+func wrapAddressPrefixErr(err error, addr string, expectedPrefix string, description string) error {
+	if prefix, _, decodeErr := bech32.DecodeAndConvert(addr); decodeErr == nil && prefix != expectedPrefix {
+		return sdkerrors.Wrapf(err, "%s has the bech32 prefix %s, expected %s", addr, prefix, expectedPrefix)
+	}
+	return sdkerrors.Wrap(err, description)
+}
+
+// normalizeFunds converts the funds attached to a contract call the same way sdk.NewCoins would:
+// sorted by denom, with zero amounts dropped. So the order the contract listed them in is not preserved.
+// Unlike sdk.NewCoins it returns an error instead of panicking on negative amounts or invalid/duplicate denoms.
+func normalizeFunds(funds wasmTypes.Coins) (sdk.Coins, error) {
+	coins, err := convertWasmCoins(funds)
+	if err != nil {
+		return nil, err
+	}
+	var nonZero sdk.Coins
+	for _, coin := range coins {
+		if !coin.Amount.IsZero() {
+			nonZero = append(nonZero, coin)
+		}
+	}
+	return nonZero, nil
+}
+
+// maxCoinAmountLength is the longest amount string we parse. sdk.Int is bounded to 256 bits,
+// which is at most 78 decimal digits, so anything longer can be rejected without parsing it.
+const maxCoinAmountLength = 78
+
 func convertWasmCoinToSdkCoin(coin wasmTypes.Coin) (sdk.Coin, error) {
+	if err := validateCoinAmount(coin.Amount); err != nil {
+		return sdk.Coin{}, err
+	}
 	amount, ok := sdk.NewIntFromString(coin.Amount)
 	if !ok {
-		return sdk.Coin{}, sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, coin.Amount+coin.Denom)
+		return sdk.Coin{}, sdkerrors.Wrap(types.ErrInvalidAmount, coin.Amount+coin.Denom)
 	}
 	return sdk.Coin{
 		Denom:  coin.Denom,
 		Amount: amount,
 	}, nil
 }
+
+// validateCoinAmount checks that an amount is a plain base 10 integer, optionally negative, of bounded length.
+// Negative amounts are allowed here so callers can report them with a clearer error.
+func validateCoinAmount(amount string) error {
+	digits := strings.TrimPrefix(amount, "-")
+	if len(digits) == 0 {
+		return sdkerrors.Wrap(types.ErrInvalidAmount, "empty amount")
+	}
+	if len(digits) > maxCoinAmountLength {
+		return sdkerrors.Wrapf(types.ErrInvalidAmount, "amount longer than %d digits", maxCoinAmountLength)
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return sdkerrors.Wrapf(types.ErrInvalidAmount, "amount %q is not an integer", amount)
+		}
+	}
+	return nil
+}
@@ -1,16 +1,34 @@
 package keeper
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	distributiontypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	ibctransfertypes "github.com/cosmos/ibc-go/v3/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v3/modules/core/02-client/types"
 
 	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
 	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
@@ -27,6 +45,32 @@ func TestEncoding(t *testing.T) {
 
 	jsonMsg := json.RawMessage(`{"foo": 123}`)
 
+	stargateSend := &banktypes.MsgSend{
+		FromAddress: addr1.String(),
+		ToAddress:   addr2.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uscrt", 12345)),
+	}
+	stargateSendBz, err := stargateSend.Marshal()
+	require.NoError(t, err)
+
+	expiration := time.Unix(1700000000, 0).UTC()
+	basicGrant, err := feegrant.NewMsgGrantAllowance(&feegrant.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("uscrt", 1000)),
+		Expiration: &expiration,
+	}, addr1, addr2)
+	require.NoError(t, err)
+	authzSend := authz.NewMsgExec(addr1, []sdk.Msg{&banktypes.MsgSend{
+		FromAddress: addr2.String(),
+		ToAddress:   addr1.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uscrt", 100)),
+	}})
+	periodicGrant, err := feegrant.NewMsgGrantAllowance(&feegrant.PeriodicAllowance{
+		Period:           time.Hour,
+		PeriodSpendLimit: sdk.NewCoins(sdk.NewInt64Coin("uscrt", 10)),
+		PeriodCanSpend:   sdk.NewCoins(sdk.NewInt64Coin("uscrt", 10)),
+	}, addr1, addr2)
+	require.NoError(t, err)
+
 	cases := map[string]struct {
 		sender sdk.AccAddress
 		input  wasmTypes.CosmosMsg
@@ -34,6 +78,8 @@ func TestEncoding(t *testing.T) {
 		output []sdk.Msg
 		// set if invalid
 		isError bool
+		// set to check the error kind
+		expErr error
 	}{
 		"simple send": {
 			sender: addr1,
@@ -83,6 +129,116 @@ func TestEncoding(t *testing.T) {
 				},
 			},
 			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"send with overlong amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					Send: &wasmTypes.SendMsg{
+						FromAddress: addr1.String(),
+						ToAddress:   addr2.String(),
+						Amount: []wasmTypes.Coin{
+							{
+								Denom:  "uatom",
+								Amount: strings.Repeat("9", 10000),
+							},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"send with plus sign amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					Send: &wasmTypes.SendMsg{
+						FromAddress: addr1.String(),
+						ToAddress:   addr2.String(),
+						Amount: []wasmTypes.Coin{
+							{
+								Denom:  "uatom",
+								Amount: "+7890",
+							},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"staking delegate overlong amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Delegate: &wasmTypes.DelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.Coin{Denom: "stake", Amount: strings.Repeat("9", 10000)},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"send with empty denom": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					Send: &wasmTypes.SendMsg{
+						FromAddress: addr1.String(),
+						ToAddress:   addr2.String(),
+						Amount: []wasmTypes.Coin{
+							{
+								Denom:  "",
+								Amount: "7890",
+							},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		"send with invalid denom": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					Send: &wasmTypes.SendMsg{
+						FromAddress: addr1.String(),
+						ToAddress:   addr2.String(),
+						Amount: []wasmTypes.Coin{
+							{
+								Denom:  "123abc",
+								Amount: "7890",
+							},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		"send with duplicate denoms": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					Send: &wasmTypes.SendMsg{
+						FromAddress: addr1.String(),
+						ToAddress:   addr2.String(),
+						Amount: []wasmTypes.Coin{
+							{
+								Denom:  "uatom",
+								Amount: "100",
+							},
+							{
+								Denom:  "uatom",
+								Amount: "200",
+							},
+						},
+					},
+				},
+			},
+			isError: true,
 		},
 		"invalid address": {
 			sender: addr1,
@@ -101,6 +257,83 @@ func TestEncoding(t *testing.T) {
 				},
 			},
 			isError: true,
+			expErr:  types.ErrInvalidRecipient,
+		},
+		"multi send": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					MultiSend: &wasmTypes.MultiSendMsg{
+						Inputs: []wasmTypes.BankInput{
+							{Address: addr1.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(300, "uscrt")}},
+						},
+						Outputs: []wasmTypes.BankOutput{
+							{Address: addr2.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")}},
+							{Address: addr1.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(200, "uscrt")}},
+						},
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&banktypes.MsgMultiSend{
+					Inputs: []banktypes.Input{
+						banktypes.NewInput(addr1, sdk.NewCoins(sdk.NewInt64Coin("uscrt", 300))),
+					},
+					Outputs: []banktypes.Output{
+						banktypes.NewOutput(addr2, sdk.NewCoins(sdk.NewInt64Coin("uscrt", 100))),
+						banktypes.NewOutput(addr1, sdk.NewCoins(sdk.NewInt64Coin("uscrt", 200))),
+					},
+				},
+			},
+		},
+		"unbalanced multi send": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					MultiSend: &wasmTypes.MultiSendMsg{
+						Inputs: []wasmTypes.BankInput{
+							{Address: addr1.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(300, "uscrt")}},
+						},
+						Outputs: []wasmTypes.BankOutput{
+							{Address: addr2.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")}},
+							{Address: addr1.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt"), wasmTypes.NewCoin(100, "uatom")}},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  banktypes.ErrInputOutputMismatch,
+		},
+		"multi send without inputs": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					MultiSend: &wasmTypes.MultiSendMsg{
+						Outputs: []wasmTypes.BankOutput{
+							{Address: addr2.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")}},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  banktypes.ErrNoInputs,
+		},
+		"multi send to invalid address": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					MultiSend: &wasmTypes.MultiSendMsg{
+						Inputs: []wasmTypes.BankInput{
+							{Address: addr1.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")}},
+						},
+						Outputs: []wasmTypes.BankOutput{
+							{Address: invalidAddr, Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")}},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidRecipient,
 		},
 		"wasm execute": {
 			sender: addr1,
@@ -126,6 +359,91 @@ func TestEncoding(t *testing.T) {
 				},
 			},
 		},
+		"wasm execute with malformed callback code hash": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					Execute: &wasmTypes.ExecuteMsg{
+						ContractAddr:     addr2.String(),
+						Msg:              jsonMsg,
+						CallbackCodeHash: "not a hash",
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidMsg,
+		},
+		"wasm execute with non-hex callback code hash": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					Execute: &wasmTypes.ExecuteMsg{
+						ContractAddr:     addr2.String(),
+						Msg:              jsonMsg,
+						CallbackCodeHash: strings.Repeat("z", 64),
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidMsg,
+		},
+		"wasm execute with multiple coins": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					Execute: &wasmTypes.ExecuteMsg{
+						ContractAddr: addr2.String(),
+						Msg:          jsonMsg,
+						Send: []wasmTypes.Coin{
+							wasmTypes.NewCoin(12, "eth"),
+							wasmTypes.NewCoin(0, "btc"),
+							wasmTypes.NewCoin(34, "atom"),
+						},
+					},
+				},
+			},
+			// sorted by denom and zero amounts dropped
+			output: []sdk.Msg{
+				&types.MsgExecuteContract{
+					Sender:    addr1,
+					Contract:  addr2,
+					Msg:       jsonMsg,
+					SentFunds: sdk.Coins{sdk.NewInt64Coin("atom", 34), sdk.NewInt64Coin("eth", 12)},
+				},
+			},
+		},
+		"wasm execute with negative amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					Execute: &wasmTypes.ExecuteMsg{
+						ContractAddr: addr2.String(),
+						Msg:          jsonMsg,
+						Send: []wasmTypes.Coin{
+							{Denom: "eth", Amount: "-12"},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"wasm execute with duplicate denoms": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					Execute: &wasmTypes.ExecuteMsg{
+						ContractAddr: addr2.String(),
+						Msg:          jsonMsg,
+						Send: []wasmTypes.Coin{
+							wasmTypes.NewCoin(12, "eth"),
+							wasmTypes.NewCoin(34, "eth"),
+						},
+					},
+				},
+			},
+			isError: true,
+		},
 		"wasm instantiate": {
 			sender: addr1,
 			input: wasmTypes.CosmosMsg{
@@ -134,6 +452,7 @@ func TestEncoding(t *testing.T) {
 						CodeID:           7,
 						CallbackCodeHash: "",
 						Msg:              jsonMsg,
+						Label:            "my contract",
 						Send: []wasmTypes.Coin{
 							wasmTypes.NewCoin(123, "eth"),
 						},
@@ -144,135 +463,2520 @@ func TestEncoding(t *testing.T) {
 				&types.MsgInstantiateContract{
 					Sender:    addr1,
 					CodeID:    7,
-					Label:     "",
+					Label:     "my contract",
 					InitMsg:   jsonMsg,
 					InitFunds: sdk.NewCoins(sdk.NewInt64Coin("eth", 123)),
 				},
 			},
 		},
-		"staking delegate": {
+		"wasm instantiate without label": {
 			sender: addr1,
 			input: wasmTypes.CosmosMsg{
-				Staking: &wasmTypes.StakingMsg{
-					Delegate: &wasmTypes.DelegateMsg{
-						Validator: valAddr.String(),
-						Amount:    wasmTypes.NewCoin(777, "stake"),
+				Wasm: &wasmTypes.WasmMsg{
+					Instantiate: &wasmTypes.InstantiateMsg{
+						CodeID: 7,
+						Msg:    jsonMsg,
 					},
 				},
 			},
-			output: []sdk.Msg{
-				&stakingtypes.MsgDelegate{
-					DelegatorAddress: addr1.String(),
-					ValidatorAddress: valAddr.String(),
-					Amount:           sdk.NewInt64Coin("stake", 777),
+			isError: true,
+			expErr:  types.ErrEmpty,
+		},
+		"wasm instantiate with over-length label": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					Instantiate: &wasmTypes.InstantiateMsg{
+						CodeID: 7,
+						Msg:    jsonMsg,
+						Label:  strings.Repeat("a", types.MaxLabelSize+1),
+					},
 				},
 			},
+			isError: true,
+			expErr:  types.ErrLimit,
 		},
-		"staking delegate to non-validator": {
+		"wasm instantiate2 is executed by Dispatch": {
 			sender: addr1,
 			input: wasmTypes.CosmosMsg{
-				Staking: &wasmTypes.StakingMsg{
-					Delegate: &wasmTypes.DelegateMsg{
-						Validator: addr2.String(),
-						Amount:    wasmTypes.NewCoin(777, "stake"),
+				Wasm: &wasmTypes.WasmMsg{
+					Instantiate2: &wasmTypes.Instantiate2Msg{
+						CodeID: 7,
+						Msg:    jsonMsg,
+						Label:  "my contract",
+						Salt:   []byte("salt"),
 					},
 				},
 			},
 			isError: true,
+			expErr:  types.ErrInvalidMsg,
 		},
-		"staking undelegate": {
+		"wasm instantiate2 without salt": {
 			sender: addr1,
 			input: wasmTypes.CosmosMsg{
-				Staking: &wasmTypes.StakingMsg{
-					Undelegate: &wasmTypes.UndelegateMsg{
-						Validator: valAddr.String(),
-						Amount:    wasmTypes.NewCoin(555, "stake"),
+				Wasm: &wasmTypes.WasmMsg{
+					Instantiate2: &wasmTypes.Instantiate2Msg{
+						CodeID: 7,
+						Msg:    jsonMsg,
+						Label:  "my contract",
 					},
 				},
 			},
-			output: []sdk.Msg{
-				&stakingtypes.MsgUndelegate{
-					DelegatorAddress: addr1.String(),
-					ValidatorAddress: valAddr.String(),
-					Amount:           sdk.NewInt64Coin("stake", 555),
+			isError: true,
+			expErr:  types.ErrEmpty,
+		},
+		"wasm instantiate2 with too long salt": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					Instantiate2: &wasmTypes.Instantiate2Msg{
+						CodeID: 7,
+						Msg:    jsonMsg,
+						Label:  "my contract",
+						Salt:   make([]byte, types.MaxSaltSize+1),
+					},
 				},
 			},
+			isError: true,
+			expErr:  types.ErrLimit,
 		},
-		"staking redelegate": {
+		"wasm store code": {
 			sender: addr1,
 			input: wasmTypes.CosmosMsg{
-				Staking: &wasmTypes.StakingMsg{
-					Redelegate: &wasmTypes.RedelegateMsg{
-						SrcValidator: valAddr.String(),
-						DstValidator: valAddr2.String(),
-						Amount:       wasmTypes.NewCoin(222, "stake"),
+				Wasm: &wasmTypes.WasmMsg{
+					StoreCode: &wasmTypes.StoreCodeMsg{
+						WasmBytes: []byte("\x00asm"),
+						Source:    "https://example.com/contract",
+						Builder:   "enigmampc/secret-contract-optimizer:1.0.0",
 					},
 				},
 			},
 			output: []sdk.Msg{
-				&stakingtypes.MsgBeginRedelegate{
-					DelegatorAddress:    addr1.String(),
-					ValidatorSrcAddress: valAddr.String(),
-					ValidatorDstAddress: valAddr2.String(),
-					Amount:              sdk.NewInt64Coin("stake", 222),
+				&types.MsgStoreCode{
+					Sender:       addr1,
+					WASMByteCode: []byte("\x00asm"),
+					Source:       "https://example.com/contract",
+					Builder:      "enigmampc/secret-contract-optimizer:1.0.0",
 				},
 			},
 		},
-		"staking withdraw (implicit recipient)": {
+		"wasm store code over max size": {
 			sender: addr1,
 			input: wasmTypes.CosmosMsg{
-				Staking: &wasmTypes.StakingMsg{
-					Withdraw: &wasmTypes.WithdrawMsg{
-						Validator: valAddr2.String(),
+				Wasm: &wasmTypes.WasmMsg{
+					StoreCode: &wasmTypes.StoreCodeMsg{
+						WasmBytes: make([]byte, types.MaxWasmSize+1),
+					},
+				},
+			},
+			isError: true,
+			expErr:  sdkerrors.ErrInvalidRequest,
+		},
+		"wasm store code without code": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Wasm: &wasmTypes.WasmMsg{
+					StoreCode: &wasmTypes.StoreCodeMsg{},
+				},
+			},
+			isError: true,
+			expErr:  sdkerrors.ErrInvalidRequest,
+		},
+		"vesting create vesting account": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Vesting: &wasmTypes.VestingMsg{
+					CreateVestingAccount: &wasmTypes.CreateVestingAccountMsg{
+						To:      addr2.String(),
+						Amount:  wasmTypes.Coins{wasmTypes.NewCoin(1000, "uscrt")},
+						EndTime: 1700000000,
+						Delayed: true,
 					},
 				},
 			},
 			output: []sdk.Msg{
-				&distributiontypes.MsgSetWithdrawAddress{
-					DelegatorAddress: addr1.String(),
-					WithdrawAddress:  addr1.String(),
+				&vestingtypes.MsgCreateVestingAccount{
+					FromAddress: addr1.String(),
+					ToAddress:   addr2.String(),
+					Amount:      sdk.NewCoins(sdk.NewInt64Coin("uscrt", 1000)),
+					EndTime:     1700000000,
+					Delayed:     true,
 				},
-				&distributiontypes.MsgWithdrawDelegatorReward{
-					DelegatorAddress: addr1.String(),
-					ValidatorAddress: valAddr2.String(),
+			},
+		},
+		"vesting create vesting account without amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Vesting: &wasmTypes.VestingMsg{
+					CreateVestingAccount: &wasmTypes.CreateVestingAccountMsg{
+						To:      addr2.String(),
+						Amount:  wasmTypes.Coins{wasmTypes.NewCoin(0, "uscrt")},
+						EndTime: 1700000000,
+					},
 				},
 			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
 		},
-		"staking withdraw (explicit recipient)": {
+		"vesting create vesting account with invalid recipient": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Vesting: &wasmTypes.VestingMsg{
+					CreateVestingAccount: &wasmTypes.CreateVestingAccountMsg{
+						To:      invalidAddr,
+						Amount:  wasmTypes.Coins{wasmTypes.NewCoin(1000, "uscrt")},
+						EndTime: 1700000000,
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidRecipient,
+		},
+		"vesting create vesting account without end time": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Vesting: &wasmTypes.VestingMsg{
+					CreateVestingAccount: &wasmTypes.CreateVestingAccountMsg{
+						To:     addr2.String(),
+						Amount: wasmTypes.Coins{wasmTypes.NewCoin(1000, "uscrt")},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidMsg,
+		},
+		"feegrant basic allowance": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Feegrant: &wasmTypes.FeegrantMsg{
+					GrantAllowance: &wasmTypes.GrantAllowanceMsg{
+						Grantee: addr2.String(),
+						Allowance: wasmTypes.FeeAllowance{
+							Basic: &wasmTypes.BasicAllowance{
+								SpendLimit: wasmTypes.Coins{wasmTypes.NewCoin(1000, "uscrt")},
+								Expiration: 1700000000,
+							},
+						},
+					},
+				},
+			},
+			output: []sdk.Msg{basicGrant},
+		},
+		"feegrant periodic allowance": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Feegrant: &wasmTypes.FeegrantMsg{
+					GrantAllowance: &wasmTypes.GrantAllowanceMsg{
+						Grantee: addr2.String(),
+						Allowance: wasmTypes.FeeAllowance{
+							Periodic: &wasmTypes.PeriodicAllowance{
+								Period:           3600,
+								PeriodSpendLimit: wasmTypes.Coins{wasmTypes.NewCoin(10, "uscrt")},
+							},
+						},
+					},
+				},
+			},
+			output: []sdk.Msg{periodicGrant},
+		},
+		"feegrant with negative spend limit": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Feegrant: &wasmTypes.FeegrantMsg{
+					GrantAllowance: &wasmTypes.GrantAllowanceMsg{
+						Grantee: addr2.String(),
+						Allowance: wasmTypes.FeeAllowance{
+							Basic: &wasmTypes.BasicAllowance{
+								SpendLimit: wasmTypes.Coins{{Denom: "uscrt", Amount: "-1000"}},
+							},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"feegrant with invalid grantee": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Feegrant: &wasmTypes.FeegrantMsg{
+					GrantAllowance: &wasmTypes.GrantAllowanceMsg{
+						Grantee:   invalidAddr,
+						Allowance: wasmTypes.FeeAllowance{Basic: &wasmTypes.BasicAllowance{}},
+					},
+				},
+			},
+			isError: true,
+			expErr:  sdkerrors.ErrInvalidAddress,
+		},
+		"feegrant without period": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Feegrant: &wasmTypes.FeegrantMsg{
+					GrantAllowance: &wasmTypes.GrantAllowanceMsg{
+						Grantee: addr2.String(),
+						Allowance: wasmTypes.FeeAllowance{
+							Periodic: &wasmTypes.PeriodicAllowance{
+								PeriodSpendLimit: wasmTypes.Coins{wasmTypes.NewCoin(10, "uscrt")},
+							},
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidMsg,
+		},
+		"feegrant without allowance": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Feegrant: &wasmTypes.FeegrantMsg{
+					GrantAllowance: &wasmTypes.GrantAllowanceMsg{Grantee: addr2.String()},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrUnknownMsgVariant,
+		},
+		"authz exec": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Authz: &wasmTypes.AuthzMsg{
+					Exec: &wasmTypes.AuthzExecMsg{
+						Granter: addr2.String(),
+						Msgs: []wasmTypes.CosmosMsg{{
+							Bank: &wasmTypes.BankMsg{
+								Send: &wasmTypes.SendMsg{
+									FromAddress: addr2.String(),
+									ToAddress:   addr1.String(),
+									Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")},
+								},
+							},
+						}},
+					},
+				},
+			},
+			output: []sdk.Msg{&authzSend},
+		},
+		"authz exec with invalid message": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Authz: &wasmTypes.AuthzMsg{
+					Exec: &wasmTypes.AuthzExecMsg{
+						Granter: addr2.String(),
+						Msgs: []wasmTypes.CosmosMsg{{
+							Bank: &wasmTypes.BankMsg{
+								Send: &wasmTypes.SendMsg{
+									FromAddress: addr2.String(),
+									ToAddress:   invalidAddr,
+									Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")},
+								},
+							},
+						}},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidRecipient,
+		},
+		"authz exec without messages": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Authz: &wasmTypes.AuthzMsg{
+					Exec: &wasmTypes.AuthzExecMsg{Granter: addr2.String()},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrEmpty,
+		},
+		"nested authz exec": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Authz: &wasmTypes.AuthzMsg{
+					Exec: &wasmTypes.AuthzExecMsg{
+						Granter: addr2.String(),
+						Msgs: []wasmTypes.CosmosMsg{{
+							Authz: &wasmTypes.AuthzMsg{Exec: &wasmTypes.AuthzExecMsg{Granter: addr1.String()}},
+						}},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidMsg,
+		},
+		"vesting empty": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Vesting: &wasmTypes.VestingMsg{},
+			},
+			isError: true,
+			expErr:  types.ErrUnknownMsgVariant,
+		},
+		"empty message": {
+			sender:  addr1,
+			input:   wasmTypes.CosmosMsg{},
+			isError: true,
+			expErr:  types.ErrUnknownMsgVariant,
+		},
+		"multiple variants set": {
 			sender: addr1,
 			input: wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{
+					Send: &wasmTypes.SendMsg{
+						FromAddress: addr1.String(),
+						ToAddress:   addr2.String(),
+						Amount:      []wasmTypes.Coin{wasmTypes.NewCoin(12345, "uatom")},
+					},
+				},
 				Staking: &wasmTypes.StakingMsg{
-					Withdraw: &wasmTypes.WithdrawMsg{
-						Validator: valAddr2.String(),
-						Recipient: addr2.String(),
+					Delegate: &wasmTypes.DelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.NewCoin(777, "stake"),
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidMsg,
+		},
+		"staking delegate": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Delegate: &wasmTypes.DelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.NewCoin(777, "stake"),
 					},
 				},
 			},
 			output: []sdk.Msg{
-				&distributiontypes.MsgSetWithdrawAddress{
+				&stakingtypes.MsgDelegate{
 					DelegatorAddress: addr1.String(),
-					WithdrawAddress:  addr2.String(),
+					ValidatorAddress: valAddr.String(),
+					Amount:           sdk.NewInt64Coin("stake", 777),
 				},
-				&distributiontypes.MsgWithdrawDelegatorReward{
-					DelegatorAddress: addr1.String(),
-					ValidatorAddress: valAddr2.String(),
+			},
+		},
+		"staking delegate zero amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Delegate: &wasmTypes.DelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.NewCoin(0, "stake"),
+					},
 				},
 			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
 		},
-	}
-
-	encoder := DefaultEncoders()
-	for name, tc := range cases {
-		tc := tc
-		t.Run(name, func(t *testing.T) {
-			res, err := encoder.Encode(tc.sender, tc.input)
-			if tc.isError {
-				require.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				assert.Equal(t, tc.output, res)
-			}
-		})
-	}
-
+		"staking delegate negative amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Delegate: &wasmTypes.DelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.Coin{Denom: "stake", Amount: "-777"},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"staking undelegate zero amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Undelegate: &wasmTypes.UndelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.NewCoin(0, "stake"),
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"staking undelegate negative amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Undelegate: &wasmTypes.UndelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.Coin{Denom: "stake", Amount: "-777"},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"staking redelegate zero amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Redelegate: &wasmTypes.RedelegateMsg{
+						SrcValidator: valAddr.String(),
+						DstValidator: valAddr2.String(),
+						Amount:       wasmTypes.NewCoin(0, "stake"),
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"staking redelegate negative amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Redelegate: &wasmTypes.RedelegateMsg{
+						SrcValidator: valAddr.String(),
+						DstValidator: valAddr2.String(),
+						Amount:       wasmTypes.Coin{Denom: "stake", Amount: "-777"},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"staking delegate empty amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Delegate: &wasmTypes.DelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.Coin{Denom: "stake"},
+					},
+				},
+			},
+			isError: true,
+		},
+		"staking delegate to non-validator": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Delegate: &wasmTypes.DelegateMsg{
+						Validator: addr2.String(),
+						Amount:    wasmTypes.NewCoin(777, "stake"),
+					},
+				},
+			},
+			isError: true,
+		},
+		"staking undelegate": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Undelegate: &wasmTypes.UndelegateMsg{
+						Validator: valAddr.String(),
+						Amount:    wasmTypes.NewCoin(555, "stake"),
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&stakingtypes.MsgUndelegate{
+					DelegatorAddress: addr1.String(),
+					ValidatorAddress: valAddr.String(),
+					Amount:           sdk.NewInt64Coin("stake", 555),
+				},
+			},
+		},
+		"staking undelegate from non-validator": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Undelegate: &wasmTypes.UndelegateMsg{
+						Validator: addr2.String(),
+						Amount:    wasmTypes.NewCoin(555, "stake"),
+					},
+				},
+			},
+			isError: true,
+		},
+		"staking redelegate": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Redelegate: &wasmTypes.RedelegateMsg{
+						SrcValidator: valAddr.String(),
+						DstValidator: valAddr2.String(),
+						Amount:       wasmTypes.NewCoin(222, "stake"),
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&stakingtypes.MsgBeginRedelegate{
+					DelegatorAddress:    addr1.String(),
+					ValidatorSrcAddress: valAddr.String(),
+					ValidatorDstAddress: valAddr2.String(),
+					Amount:              sdk.NewInt64Coin("stake", 222),
+				},
+			},
+		},
+		"staking redelegate to same validator": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Redelegate: &wasmTypes.RedelegateMsg{
+						SrcValidator: valAddr.String(),
+						DstValidator: valAddr.String(),
+						Amount:       wasmTypes.NewCoin(222, "stake"),
+					},
+				},
+			},
+			isError: true,
+		},
+		"staking redelegate from non-validator": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Redelegate: &wasmTypes.RedelegateMsg{
+						SrcValidator: addr2.String(),
+						DstValidator: valAddr2.String(),
+						Amount:       wasmTypes.NewCoin(222, "stake"),
+					},
+				},
+			},
+			isError: true,
+		},
+		"staking redelegate to non-validator": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Redelegate: &wasmTypes.RedelegateMsg{
+						SrcValidator: valAddr.String(),
+						DstValidator: addr2.String(),
+						Amount:       wasmTypes.NewCoin(222, "stake"),
+					},
+				},
+			},
+			isError: true,
+		},
+		"staking withdraw (implicit recipient)": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Withdraw: &wasmTypes.WithdrawMsg{
+						Validator: valAddr2.String(),
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&distributiontypes.MsgWithdrawDelegatorReward{
+					DelegatorAddress: addr1.String(),
+					ValidatorAddress: valAddr2.String(),
+				},
+			},
+		},
+		"staking withdraw (recipient is the delegator)": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Withdraw: &wasmTypes.WithdrawMsg{
+						Validator: valAddr2.String(),
+						Recipient: addr1.String(),
+					},
+				},
+			},
+			// the withdraw address may have been set elsewhere before, so it's reset to the delegator
+			output: []sdk.Msg{
+				&distributiontypes.MsgSetWithdrawAddress{
+					DelegatorAddress: addr1.String(),
+					WithdrawAddress:  addr1.String(),
+				},
+				&distributiontypes.MsgWithdrawDelegatorReward{
+					DelegatorAddress: addr1.String(),
+					ValidatorAddress: valAddr2.String(),
+				},
+			},
+		},
+		"staking withdraw (explicit recipient)": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Staking: &wasmTypes.StakingMsg{
+					Withdraw: &wasmTypes.WithdrawMsg{
+						Validator: valAddr2.String(),
+						Recipient: addr2.String(),
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&distributiontypes.MsgSetWithdrawAddress{
+					DelegatorAddress: addr1.String(),
+					WithdrawAddress:  addr2.String(),
+				},
+				&distributiontypes.MsgWithdrawDelegatorReward{
+					DelegatorAddress: addr1.String(),
+					ValidatorAddress: valAddr2.String(),
+				},
+			},
+		},
+		"distribution set withdraw address": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Distribution: &wasmTypes.DistributionMsg{
+					SetWithdrawAddress: &wasmTypes.SetWithdrawAddressMsg{
+						Address: addr2.String(),
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&distributiontypes.MsgSetWithdrawAddress{
+					DelegatorAddress: addr1.String(),
+					WithdrawAddress:  addr2.String(),
+				},
+			},
+		},
+		"distribution set withdraw address to invalid address": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Distribution: &wasmTypes.DistributionMsg{
+					SetWithdrawAddress: &wasmTypes.SetWithdrawAddressMsg{
+						Address: invalidAddr,
+					},
+				},
+			},
+			isError: true,
+		},
+		"distribution fund community pool": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Distribution: &wasmTypes.DistributionMsg{
+					FundCommunityPool: &wasmTypes.FundCommunityPoolMsg{
+						Amount: wasmTypes.Coins{wasmTypes.NewCoin(200, "uscrt")},
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&distributiontypes.MsgFundCommunityPool{
+					Amount:    sdk.NewCoins(sdk.NewInt64Coin("uscrt", 200)),
+					Depositor: addr1.String(),
+				},
+			},
+		},
+		"distribution fund community pool with empty amount": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Distribution: &wasmTypes.DistributionMsg{
+					FundCommunityPool: &wasmTypes.FundCommunityPoolMsg{},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidAmount,
+		},
+		"distribution withdraw validator commission": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Distribution: &wasmTypes.DistributionMsg{
+					WithdrawValidatorCommission: &wasmTypes.WithdrawValidatorCommissionMsg{
+						Validator: sdk.ValAddress(addr1).String(),
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&distributiontypes.MsgWithdrawValidatorCommission{
+					ValidatorAddress: sdk.ValAddress(addr1).String(),
+				},
+			},
+		},
+		"distribution withdraw validator commission with invalid address": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Distribution: &wasmTypes.DistributionMsg{
+					WithdrawValidatorCommission: &wasmTypes.WithdrawValidatorCommissionMsg{
+						Validator: addr1.String(),
+					},
+				},
+			},
+			isError: true,
+			expErr:  sdkerrors.ErrInvalidAddress,
+		},
+		"distribution withdraw validator commission of another validator": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Distribution: &wasmTypes.DistributionMsg{
+					WithdrawValidatorCommission: &wasmTypes.WithdrawValidatorCommissionMsg{
+						Validator: valAddr.String(),
+					},
+				},
+			},
+			isError: true,
+			expErr:  sdkerrors.ErrUnauthorized,
+		},
+		"slashing unjail": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Slashing: &wasmTypes.SlashingMsg{
+					Unjail: &wasmTypes.UnjailMsg{
+						Validator: sdk.ValAddress(addr1).String(),
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&slashingtypes.MsgUnjail{
+					ValidatorAddr: sdk.ValAddress(addr1).String(),
+				},
+			},
+		},
+		"slashing unjail of another validator": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Slashing: &wasmTypes.SlashingMsg{
+					Unjail: &wasmTypes.UnjailMsg{
+						Validator: valAddr.String(),
+					},
+				},
+			},
+			isError: true,
+			expErr:  sdkerrors.ErrUnauthorized,
+		},
+		"slashing unjail with invalid address": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Slashing: &wasmTypes.SlashingMsg{
+					Unjail: &wasmTypes.UnjailMsg{
+						Validator: addr1.String(),
+					},
+				},
+			},
+			isError: true,
+			expErr:  sdkerrors.ErrInvalidAddress,
+		},
+		"gov vote": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Gov: &wasmTypes.GovMsg{
+					Vote: &wasmTypes.VoteMsg{
+						Proposal:   1,
+						VoteOption: wasmTypes.NoWithVeto,
+					},
+				},
+			},
+			output: []sdk.Msg{
+				govtypes.NewMsgVote(addr1, 1, govtypes.OptionNoWithVeto),
+			},
+		},
+		"gov vote with unknown option": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Gov: &wasmTypes.GovMsg{
+					Vote: &wasmTypes.VoteMsg{
+						Proposal:   1,
+						VoteOption: "Maybe",
+					},
+				},
+			},
+			isError: true,
+		},
+		"gov submit proposal": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Gov: &wasmTypes.GovMsg{
+					SubmitProposal: &wasmTypes.SubmitProposalMsg{
+						Title:       "Fund the DAO",
+						Description: "Proposal submitted by a contract",
+						InitialDeposit: []wasmTypes.Coin{
+							wasmTypes.NewCoin(100, "uscrt"),
+						},
+					},
+				},
+			},
+			output: []sdk.Msg{
+				mustNewMsgSubmitProposal(t,
+					govtypes.NewTextProposal("Fund the DAO", "Proposal submitted by a contract"),
+					sdk.NewCoins(sdk.NewInt64Coin("uscrt", 100)),
+					addr1,
+				),
+			},
+		},
+		"gov submit proposal with invalid deposit": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Gov: &wasmTypes.GovMsg{
+					SubmitProposal: &wasmTypes.SubmitProposalMsg{
+						Title:       "Fund the DAO",
+						Description: "Proposal submitted by a contract",
+						InitialDeposit: []wasmTypes.Coin{
+							{Denom: "uscrt", Amount: "1.5"},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		"stargate bank send": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Stargate: &wasmTypes.StargateMsg{
+					TypeURL: sdk.MsgTypeURL(stargateSend),
+					Value:   stargateSendBz,
+				},
+			},
+			output: []sdk.Msg{stargateSend},
+		},
+		"stargate unknown type url": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				Stargate: &wasmTypes.StargateMsg{
+					TypeURL: "/cosmos.bank.v1beta1.MsgDoesNotExist",
+					Value:   stargateSendBz,
+				},
+			},
+			isError: true,
+		},
+		"ibc transfer": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				IBC: &wasmTypes.IBCMsg{
+					Transfer: &wasmTypes.TransferMsg{
+						ChannelID: "channel-0",
+						ToAddress: "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+						Amount:    wasmTypes.NewCoin(1000, "uscrt"),
+						Timeout: wasmTypes.IBCTimeout{
+							Block: &wasmTypes.IBCTimeoutBlock{
+								Revision: 1,
+								Height:   12345,
+							},
+							Timestamp: 1640000000000000000,
+						},
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&ibctransfertypes.MsgTransfer{
+					SourcePort:       "transfer",
+					SourceChannel:    "channel-0",
+					Token:            sdk.NewInt64Coin("uscrt", 1000),
+					Sender:           addr1.String(),
+					Receiver:         "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+					TimeoutHeight:    clienttypes.NewHeight(1, 12345),
+					TimeoutTimestamp: 1640000000000000000,
+				},
+			},
+		},
+		"ibc transfer from a custom port": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				IBC: &wasmTypes.IBCMsg{
+					Transfer: &wasmTypes.TransferMsg{
+						SourcePort: "middleware",
+						ChannelID:  "channel-0",
+						ToAddress:  "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+						Amount:     wasmTypes.NewCoin(1000, "uscrt"),
+						Timeout: wasmTypes.IBCTimeout{
+							Timestamp: 1640000000000000000,
+						},
+					},
+				},
+			},
+			output: []sdk.Msg{
+				&ibctransfertypes.MsgTransfer{
+					SourcePort:       "middleware",
+					SourceChannel:    "channel-0",
+					Token:            sdk.NewInt64Coin("uscrt", 1000),
+					Sender:           addr1.String(),
+					Receiver:         "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+					TimeoutTimestamp: 1640000000000000000,
+				},
+			},
+		},
+		"ibc transfer from an invalid port": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				IBC: &wasmTypes.IBCMsg{
+					Transfer: &wasmTypes.TransferMsg{
+						SourcePort: "port/0",
+						ChannelID:  "channel-0",
+						ToAddress:  "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+						Amount:     wasmTypes.NewCoin(1000, "uscrt"),
+						Timeout: wasmTypes.IBCTimeout{
+							Timestamp: 1640000000000000000,
+						},
+					},
+				},
+			},
+			isError: true,
+			expErr:  types.ErrInvalidMsg,
+		},
+		"ibc transfer with empty channel": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				IBC: &wasmTypes.IBCMsg{
+					Transfer: &wasmTypes.TransferMsg{
+						ChannelID: "",
+						ToAddress: "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+						Amount:    wasmTypes.NewCoin(1000, "uscrt"),
+						Timeout: wasmTypes.IBCTimeout{
+							Timestamp: 1640000000000000000,
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		"ibc transfer without timeout": {
+			sender: addr1,
+			input: wasmTypes.CosmosMsg{
+				IBC: &wasmTypes.IBCMsg{
+					Transfer: &wasmTypes.TransferMsg{
+						ChannelID: "channel-0",
+						ToAddress: "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+						Amount:    wasmTypes.NewCoin(1000, "uscrt"),
+					},
+				},
+			},
+			isError: true,
+		},
+	}
+
+	encoder := NewMessageHandler(nil, nil, MakeEncodingConfig().InterfaceRegistry).encoders
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			res, err := encoder.Encode(encodingTestContext(), tc.sender, tc.input)
+			if tc.isError {
+				require.Error(t, err)
+				if tc.expErr != nil {
+					assert.True(t, errors.Is(err, tc.expErr), "expected %s, got %s", tc.expErr, err)
+				}
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.output, res)
+			}
+		})
+	}
+
+}
+
+func TestEncodeEmptySender(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	valAddr := make(sdk.ValAddress, 20)
+	valAddr[0] = 12
+
+	msgs := map[string]wasmTypes.CosmosMsg{
+		"bank": {
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress: addr1.String(),
+					ToAddress:   addr1.String(),
+					Amount:      []wasmTypes.Coin{wasmTypes.NewCoin(12345, "uatom")},
+				},
+			},
+		},
+		"staking": {
+			Staking: &wasmTypes.StakingMsg{
+				Delegate: &wasmTypes.DelegateMsg{
+					Validator: valAddr.String(),
+					Amount:    wasmTypes.NewCoin(777, "stake"),
+				},
+			},
+		},
+		"unknown variant": {},
+	}
+
+	encoder := DefaultEncoders()
+	for name, msg := range msgs {
+		msg := msg
+		t.Run(name, func(t *testing.T) {
+			_, err := encoder.Encode(encodingTestContext(), sdk.AccAddress{}, msg)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, sdkerrors.ErrInvalidAddress), err.Error())
+		})
+	}
+}
+
+func TestEncodeBatch(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	valAddr := make(sdk.ValAddress, 20)
+	valAddr[0] = 12
+	jsonMsg := json.RawMessage(`{"foo": 123}`)
+
+	bankMsg := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: addr1.String(),
+				ToAddress:   addr2.String(),
+				Amount:      []wasmTypes.Coin{wasmTypes.NewCoin(12345, "uatom")},
+			},
+		},
+	}
+	stakingMsg := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Delegate: &wasmTypes.DelegateMsg{
+				Validator: valAddr.String(),
+				Amount:    wasmTypes.NewCoin(777, "stake"),
+			},
+		},
+	}
+	wasmMsg := wasmTypes.CosmosMsg{
+		Wasm: &wasmTypes.WasmMsg{
+			Execute: &wasmTypes.ExecuteMsg{
+				ContractAddr: addr2.String(),
+				Msg:          jsonMsg,
+			},
+		},
+	}
+	invalidMsg := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Delegate: &wasmTypes.DelegateMsg{
+				Validator: addr2.String(),
+				Amount:    wasmTypes.NewCoin(777, "stake"),
+			},
+		},
+	}
+
+	encoder := DefaultEncoders()
+
+	res, err := encoder.EncodeBatch(encodingTestContext(), addr1, []wasmTypes.CosmosMsg{bankMsg, stakingMsg, wasmMsg})
+	require.NoError(t, err)
+	assert.Equal(t, []sdk.Msg{
+		&banktypes.MsgSend{
+			FromAddress: addr1.String(),
+			ToAddress:   addr2.String(),
+			Amount:      sdk.NewCoins(sdk.NewInt64Coin("uatom", 12345)),
+		},
+		stakingtypes.NewMsgDelegate(addr1, valAddr, sdk.NewInt64Coin("stake", 777)),
+		&types.MsgExecuteContract{
+			Sender:   addr1,
+			Contract: addr2,
+			Msg:      jsonMsg,
+		},
+	}, res)
+
+	_, err = encoder.EncodeBatch(encodingTestContext(), addr1, []wasmTypes.CosmosMsg{bankMsg, stakingMsg, invalidMsg, wasmMsg})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 2")
+	assert.True(t, errors.Is(err, sdkerrors.ErrInvalidAddress))
+}
+
+func TestEncodeBatchGasOnFailure(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	valAddr := make(sdk.ValAddress, 20)
+	valAddr[0] = 12
+
+	bankMsg := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: addr1.String(),
+				ToAddress:   addr2.String(),
+				Amount:      []wasmTypes.Coin{wasmTypes.NewCoin(12345, "uatom")},
+			},
+		},
+	}
+	stakingMsg := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Delegate: &wasmTypes.DelegateMsg{
+				Validator: valAddr.String(),
+				Amount:    wasmTypes.NewCoin(777, "stake"),
+			},
+		},
+	}
+	invalidMsg := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Delegate: &wasmTypes.DelegateMsg{
+				Validator: addr2.String(),
+				Amount:    wasmTypes.NewCoin(777, "stake"),
+			},
+		},
+	}
+
+	encoder := DefaultEncoders()
+	encodeGas := func(msg wasmTypes.CosmosMsg) uint64 {
+		ctx := encodingTestContext()
+		_, err := encoder.Encode(ctx, addr1, msg)
+		require.NoError(t, err)
+		return ctx.GasMeter().GasConsumed()
+	}
+
+	// the messages before the failed one are charged in full, the failed one the flat cost, and the rest nothing
+	ctx := encodingTestContext()
+	_, err := encoder.EncodeBatch(ctx, addr1, []wasmTypes.CosmosMsg{bankMsg, stakingMsg, invalidMsg, bankMsg})
+	require.Error(t, err)
+	assert.Equal(t, encodeGas(bankMsg)+encodeGas(stakingMsg)+types.EncodeMsgCost, ctx.GasMeter().GasConsumed())
+}
+
+func TestEncodeCustomVariant(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+
+	var calledWith json.RawMessage
+	var calledBy sdk.AccAddress
+	fakeMsg := &banktypes.MsgSend{
+		FromAddress: addr1.String(),
+		ToAddress:   addr2.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uscrt", 1)),
+	}
+	fakeEncoder := func(sender sdk.AccAddress, msg json.RawMessage) ([]sdk.Msg, error) {
+		calledBy = sender
+		calledWith = msg
+		return []sdk.Msg{fakeMsg}, nil
+	}
+
+	keeper := Keeper{messenger: NewMessageHandler(nil, nil, nil)}
+	WithCustomEncoder("oracle", fakeEncoder).apply(&keeper)
+	encoder := keeper.messenger.encoders
+
+	res, err := encoder.Encode(encodingTestContext(), addr1, wasmTypes.CosmosMsg{
+		Custom: json.RawMessage(`{"oracle":{"price":{"denom":"uscrt"}}}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []sdk.Msg{fakeMsg}, res)
+	assert.Equal(t, addr1, calledBy)
+	assert.JSONEq(t, `{"price":{"denom":"uscrt"}}`, string(calledWith))
+
+	// unregistered variants fall through to the default custom handler
+	_, err = encoder.Encode(encodingTestContext(), addr1, wasmTypes.CosmosMsg{
+		Custom: json.RawMessage(`{"unknown":{}}`),
+	})
+	require.Error(t, err)
+
+	// registering an encoder must not leak into the defaults
+	require.Empty(t, DefaultEncoders().CustomEncoders)
+}
+
+func TestEncodeForeignBech32Prefix(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	cosmosAddr, err := bech32.ConvertAndEncode("cosmos", addr2)
+	require.NoError(t, err)
+	encoder := DefaultEncoders()
+
+	send := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: addr1.String(),
+				ToAddress:   cosmosAddr,
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")},
+			},
+		},
+	}
+	_, err = encoder.Encode(encodingTestContext(), addr1, send)
+	assert.True(t, types.ErrInvalidRecipient.Is(err), err)
+	prefix := sdk.GetConfig().GetBech32AccountAddrPrefix()
+	assert.Contains(t, err.Error(), "has the bech32 prefix cosmos, expected "+prefix)
+
+	// an account address is the wrong kind of address for a validator
+	delegate := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Delegate: &wasmTypes.DelegateMsg{
+				Validator: addr2.String(),
+				Amount:    wasmTypes.NewCoin(100, "stake"),
+			},
+		},
+	}
+	_, err = encoder.Encode(encodingTestContext(), addr1, delegate)
+	assert.True(t, sdkerrors.ErrInvalidAddress.Is(err), err)
+	assert.Contains(t, err.Error(), "expected "+sdk.GetConfig().GetBech32ValidatorAddrPrefix())
+
+	// an address that isn't bech32 at all keeps the generic error
+	send.Bank.Send.ToAddress = "invalid"
+	_, err = encoder.Encode(encodingTestContext(), addr1, send)
+	assert.True(t, types.ErrInvalidRecipient.Is(err), err)
+	assert.NotContains(t, err.Error(), "bech32 prefix")
+}
+
+func TestEncodeCustomMsgHandler(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	// the whitespace is kept
+	custom := json.RawMessage(`{"fork_msg": {"a": 1,  "b": [2]}}`)
+
+	// without a handler custom messages fail
+	encoder := NewMessageHandler(nil, nil, nil).encoders
+	_, err := encoder.Encode(encodingTestContext(), addr1, wasmTypes.CosmosMsg{Custom: custom})
+	assert.True(t, errors.Is(err, types.ErrInvalidMsg), err)
+	assert.Contains(t, err.Error(), "no custom handler registered")
+
+	var calledWith json.RawMessage
+	var calledBy sdk.AccAddress
+	fakeMsg := &banktypes.MsgSend{
+		FromAddress: addr1.String(),
+		ToAddress:   addr2.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uscrt", 1)),
+	}
+	keeper := Keeper{messenger: NewMessageHandler(nil, nil, nil)}
+	WithCustomMsgHandler(func(sender sdk.AccAddress, msg json.RawMessage) ([]sdk.Msg, error) {
+		calledBy = sender
+		calledWith = msg
+		return []sdk.Msg{fakeMsg}, nil
+	}).apply(&keeper)
+
+	res, err := keeper.messenger.encoders.Encode(encodingTestContext(), addr1, wasmTypes.CosmosMsg{Custom: custom})
+	require.NoError(t, err)
+	assert.Equal(t, []sdk.Msg{fakeMsg}, res)
+	assert.Equal(t, addr1, calledBy)
+	assert.Equal(t, custom, calledWith)
+
+	// the handler must not leak into the defaults
+	_, err = DefaultEncoders().Encode(encodingTestContext(), addr1, wasmTypes.CosmosMsg{Custom: custom})
+	require.Error(t, err)
+}
+
+func mustNewMsgSubmitProposal(t *testing.T, content govtypes.Content, deposit sdk.Coins, proposer sdk.AccAddress) *govtypes.MsgSubmitProposal {
+	msg, err := govtypes.NewMsgSubmitProposal(content, deposit, proposer)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestDispatchBurn(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	supplyBefore := bankKeeper.GetSupply(ctx, "denom")
+
+	cases := map[string]struct {
+		amount  wasmTypes.Coins
+		isError bool
+	}{
+		"valid burn": {
+			amount: wasmTypes.Coins{wasmTypes.NewCoin(400, "denom")},
+		},
+		"negative amount": {
+			amount:  wasmTypes.Coins{{Denom: "denom", Amount: "-400"}},
+			isError: true,
+		},
+		"invalid amount": {
+			amount:  wasmTypes.Coins{{Denom: "denom", Amount: "4.5"}},
+			isError: true,
+		},
+		"more than the balance": {
+			amount:  wasmTypes.Coins{wasmTypes.NewCoin(100000, "denom")},
+			isError: true,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			cacheCtx, _ := ctx.CacheContext()
+			_, _, err := keeper.Dispatch(cacheCtx, contractAddr, wasmTypes.CosmosMsg{
+				Bank: &wasmTypes.BankMsg{Burn: &wasmTypes.BurnMsg{Amount: tc.amount}},
+			})
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, sdk.NewInt64Coin("denom", 600), bankKeeper.GetBalance(cacheCtx, contractAddr, "denom"))
+			assert.Equal(t, supplyBefore.SubAmount(sdk.NewInt(400)), bankKeeper.GetSupply(cacheCtx, "denom"))
+		})
+	}
+}
+
+func TestDispatchInstantiate2(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	codeHash := sha256.Sum256([]byte("some wasm code"))
+	codeInfo := types.NewCodeInfo(codeHash[:], contractAddr, "", "")
+	ctx.KVStore(keeper.storeKey).Set(types.GetCodeKey(1), keeper.cdc.MustMarshal(&codeInfo))
+
+	instantiate2 := func(codeID uint64, salt []byte) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Instantiate2: &wasmTypes.Instantiate2Msg{
+					CodeID: codeID,
+					Msg:    []byte(`{}`),
+					Label:  "my contract",
+					Send:   wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+					Salt:   salt,
+					// set by the enclave for messages of contracts
+					CallbackSignature: []byte("signature"),
+				},
+			},
+		}
+	}
+
+	// an account at the predicted address collides with the new contract, before the contract is run or funded
+	predicted := PredictableContractAddress(codeHash[:], contractAddr, []byte("taken"))
+	accKeeper.SetAccount(ctx, accKeeper.NewAccountWithAddress(ctx, predicted))
+	_, _, err := keeper.Dispatch(ctx, contractAddr, instantiate2(1, []byte("taken")))
+	require.Error(t, err)
+	assert.True(t, types.ErrAccountExists.Is(err), err)
+	assert.Contains(t, err.Error(), predicted.String())
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	_, _, err = keeper.Dispatch(ctx, contractAddr, instantiate2(2, []byte("salt")))
+	require.Error(t, err)
+	assert.True(t, types.ErrNotFound.Is(err), err)
+
+	_, _, err = keeper.Dispatch(ctx, contractAddr, instantiate2(1, nil))
+	require.Error(t, err)
+	assert.True(t, types.ErrEmpty.Is(err), err)
+
+	// it can't be wrapped into other messages, as there is no sdk.Msg for it
+	_, _, err = keeper.Dispatch(ctx, contractAddr, wasmTypes.CosmosMsg{
+		Authz: &wasmTypes.AuthzMsg{Exec: &wasmTypes.AuthzExecMsg{Granter: contractAddr.String(), Msgs: []wasmTypes.CosmosMsg{instantiate2(1, []byte("salt"))}}},
+	})
+	require.Error(t, err)
+	assert.True(t, types.ErrInvalidMsg.Is(err), err)
+}
+
+func TestEncodeVestingEndTime(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	now := time.Unix(1700000000, 0)
+	createVestingAccount := func(endTime int64) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Vesting: &wasmTypes.VestingMsg{
+				CreateVestingAccount: &wasmTypes.CreateVestingAccountMsg{
+					To:      addr2.String(),
+					Amount:  wasmTypes.Coins{wasmTypes.NewCoin(1000, "uscrt")},
+					EndTime: endTime,
+				},
+			},
+		}
+	}
+
+	encoder := DefaultEncoders()
+	ctx := encodingTestContext().WithBlockTime(now)
+	_, err := encoder.Encode(ctx, addr1, createVestingAccount(now.Unix()+1))
+	require.NoError(t, err)
+
+	for name, endTime := range map[string]int64{
+		"now":  now.Unix(),
+		"past": now.Unix() - 3600,
+	} {
+		_, err := encoder.Encode(ctx, addr1, createVestingAccount(endTime))
+		assert.True(t, errors.Is(err, types.ErrInvalidMsg), "%s: %v", name, err)
+	}
+}
+
+// encodingTestContext is enough of a context for the encoders, which don't touch the store
+func encodingTestContext() sdk.Context {
+	return sdk.NewContext(nil, tmproto.Header{}, false, log.NewNopLogger())
+}
+
+func TestEncodeGasConsumption(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+
+	send := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: addr1.String(),
+				ToAddress:   addr2.String(),
+				Amount:      []wasmTypes.Coin{wasmTypes.NewCoin(12345, "uscrt")},
+			},
+		},
+	}
+
+	encoder := DefaultEncoders()
+	gasForMsgs := func(count int) uint64 {
+		ctx := encodingTestContext()
+		for i := 0; i < count; i++ {
+			_, err := encoder.Encode(ctx, addr1, send)
+			require.NoError(t, err)
+		}
+		return ctx.GasMeter().GasConsumed()
+	}
+
+	single := gasForMsgs(1)
+	require.Greater(t, single, types.EncodeMsgCost)
+	assert.Equal(t, 10*single, gasForMsgs(10))
+	assert.Equal(t, 1000*single, gasForMsgs(1000))
+}
+
+func TestDispatchEmitsEncodedMsgEvent(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("denom", 1000)))
+	_, _, rcpt := keyPubAddr()
+
+	specs := map[string]wasmTypes.CosmosMsg{
+		"bank_send": {
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress: contractAddr.String(),
+					ToAddress:   rcpt.String(),
+					Amount:      []wasmTypes.Coin{wasmTypes.NewCoin(100, "denom")},
+				},
+			},
+		},
+		// isn't encoded into an sdk.Msg, but emits the event too
+		"bank_burn": {
+			Bank: &wasmTypes.BankMsg{Burn: &wasmTypes.BurnMsg{Amount: []wasmTypes.Coin{wasmTypes.NewCoin(100, "denom")}}},
+		},
+	}
+	for variant, msg := range specs {
+		t.Run(variant, func(t *testing.T) {
+			bz, err := json.Marshal(msg)
+			require.NoError(t, err)
+			hash := sha256.Sum256(bz)
+
+			ctx := ctx.WithEventManager(sdk.NewEventManager())
+			_, _, err = keeper.Dispatch(ctx, contractAddr, msg)
+			require.NoError(t, err)
+
+			var found []sdk.Event
+			for _, e := range ctx.EventManager().Events() {
+				if e.Type == types.EventTypeEncodedMsg {
+					found = append(found, e)
+				}
+			}
+			require.Len(t, found, 1)
+			assert.Equal(t, sdk.NewEvent(types.EventTypeEncodedMsg,
+				sdk.NewAttribute(types.AttributeKeyContract, contractAddr.String()),
+				sdk.NewAttribute(types.AttributeKeyMsgVariant, variant),
+				sdk.NewAttribute(types.AttributeKeyMsgHash, hex.EncodeToString(hash[:])),
+			), found[0])
+		})
+	}
+}
+
+func TestDispatchIncrementsVariantCounter(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Hour, time.Hour)
+	cfg := metrics.DefaultConfig("test")
+	cfg.EnableHostname = false
+	cfg.EnableRuntimeMetrics = false
+	_, err := metrics.NewGlobal(cfg, sink)
+	require.NoError(t, err)
+	defer metrics.NewGlobal(metrics.DefaultConfig(""), &metrics.BlackholeSink{}) //nolint:errcheck
+
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, stakingKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.StakingKeeper, keepers.WasmKeeper
+
+	valAddr := addValidator(ctx, stakingKeeper, accKeeper, bankKeeper, sdk.NewInt64Coin("stake", 1000000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+
+	msg := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Delegate: &wasmTypes.DelegateMsg{
+				Validator: valAddr.String(),
+				Amount:    wasmTypes.NewCoin(100, "stake"),
+			},
+		},
+	}
+	_, _, err = keeper.Dispatch(ctx, contractAddr, msg)
+	require.NoError(t, err)
+	_, _, err = keeper.Dispatch(ctx, contractAddr, msg)
+	require.NoError(t, err)
+
+	intervals := sink.Data()
+	require.Len(t, intervals, 1)
+	counter, ok := intervals[0].Counters["test.compute.keeper.encoded_msg;variant=staking_delegate"]
+	require.True(t, ok, "counters: %v", intervals[0].Counters)
+	assert.Equal(t, 2, counter.Count)
+}
+
+func TestDispatchLogsEncodeFailure(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+	_, _, contractAddr := keyPubAddr()
+
+	var buf strings.Builder
+	ctx = ctx.WithLogger(log.NewTMJSONLogger(&buf))
+	// sending nothing is a no-op
+	valid := wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{
+		FromAddress: contractAddr.String(),
+		ToAddress:   contractAddr.String(),
+	}}}
+	invalid := wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{
+		FromAddress: contractAddr.String(),
+		ToAddress:   "invalid",
+		Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+	}}}
+
+	err := keeper.dispatchMessages(ctx, contractAddr, []wasmTypes.CosmosMsg{valid, invalid}, keeper.GetParams(ctx))
+	require.Error(t, err)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(buf.String()), &entry), buf.String())
+	assert.Equal(t, "failed to encode contract message", entry["_msg"])
+	assert.Equal(t, contractAddr.String(), entry["contract"])
+	assert.Equal(t, float64(1), entry["msg_index"])
+	assert.Equal(t, "bank_send", entry["variant"])
+	assert.Contains(t, entry["err"], "invalid")
+}
+
+func TestVerifyCallbackCodeHash(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, creator := keyPubAddr()
+	_, _, contractAddr := keyPubAddr()
+	codeHash := sha256.Sum256([]byte("some wasm code"))
+	codeInfo := types.NewCodeInfo(codeHash[:], creator, "", "")
+	ctx.KVStore(keeper.storeKey).Set(types.GetCodeKey(1), keeper.cdc.MustMarshal(&codeInfo))
+	contractInfo := types.NewContractInfo(1, creator, "contract", nil)
+	keeper.setContractInfo(ctx, contractAddr, &contractInfo)
+
+	execute := func(contract sdk.AccAddress, codeHash string) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Execute: &wasmTypes.ExecuteMsg{
+					ContractAddr:     contract.String(),
+					CallbackCodeHash: codeHash,
+					Msg:              []byte(`{}`),
+				},
+			},
+		}
+	}
+	otherHash := sha256.Sum256([]byte("other wasm code"))
+	_, _, unknownAddr := keyPubAddr()
+
+	cases := map[string]struct {
+		msg     wasmTypes.CosmosMsg
+		isError bool
+	}{
+		"matching hash": {
+			msg: execute(contractAddr, hex.EncodeToString(codeHash[:])),
+		},
+		"matching upper case hash": {
+			msg: execute(contractAddr, strings.ToUpper(hex.EncodeToString(codeHash[:]))),
+		},
+		"no hash": {
+			msg: execute(contractAddr, ""),
+		},
+		"mismatched hash": {
+			msg:     execute(contractAddr, hex.EncodeToString(otherHash[:])),
+			isError: true,
+		},
+		"unknown contract": {
+			msg:     execute(unknownAddr, hex.EncodeToString(codeHash[:])),
+			isError: true,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := keeper.verifyCallbackCodeHash(ctx, tc.msg)
+			if tc.isError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEstimateEncodeGas(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	msg := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: addr1.String(),
+				ToAddress:   addr2.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(12345, "uscrt")},
+			},
+		},
+	}
+
+	// the estimate matches what encoding actually charges
+	encode := func(msgs []wasmTypes.CosmosMsg) ([]sdk.Msg, uint64) {
+		ctx := encodingTestContext().WithGasMeter(sdk.NewInfiniteGasMeter())
+		sdkMsgs, err := DefaultEncoders().EncodeBatch(ctx, addr1, msgs)
+		require.NoError(t, err)
+		return sdkMsgs, ctx.GasMeter().GasConsumed()
+	}
+	single, singleGas := encode([]wasmTypes.CosmosMsg{msg})
+	batch, batchGas := encode([]wasmTypes.CosmosMsg{msg, msg, msg})
+	assert.Equal(t, singleGas, EstimateEncodeGas(single))
+	assert.Equal(t, batchGas, EstimateEncodeGas(batch))
+	assert.Equal(t, 3*EstimateEncodeGas(single), EstimateEncodeGas(batch))
+
+	ctx := encodingTestContext().WithMinGasPrices(sdk.NewDecCoins(sdk.NewDecCoinFromDec("uscrt", sdk.NewDecWithPrec(25, 2))))
+	expFee := sdk.NewDec(int64(EstimateEncodeGas(single))).Mul(sdk.NewDecWithPrec(25, 2)).Ceil().RoundInt()
+	assert.Equal(t, sdk.NewCoins(sdk.NewCoin("uscrt", expFee)), EstimateEncodeFee(ctx, single))
+	assert.True(t, EstimateEncodeFee(encodingTestContext(), single).IsZero())
+}
+
+func TestEncodeWithMapping(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	valAddr := make(sdk.ValAddress, 20)
+	valAddr[0] = 12
+
+	withdrawMsg := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Withdraw: &wasmTypes.WithdrawMsg{
+				Validator: valAddr.String(),
+				Recipient: addr2.String(),
+			},
+		},
+	}
+	bankMsg := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: addr1.String(),
+				ToAddress:   addr2.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(12345, "uatom")},
+			},
+		},
+	}
+
+	res, err := DefaultEncoders().EncodeWithMapping(encodingTestContext(), addr1, []wasmTypes.CosmosMsg{withdrawMsg, bankMsg})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	// the withdraw sets the withdraw address and then withdraws the rewards
+	assert.Equal(t, 0, res[0].SourceIndex)
+	assert.IsType(t, &distributiontypes.MsgSetWithdrawAddress{}, res[0].Msg)
+	assert.Equal(t, 0, res[1].SourceIndex)
+	assert.IsType(t, &distributiontypes.MsgWithdrawDelegatorReward{}, res[1].Msg)
+	assert.Equal(t, 1, res[2].SourceIndex)
+	assert.IsType(t, &banktypes.MsgSend{}, res[2].Msg)
+
+	_, err = DefaultEncoders().EncodeWithMapping(encodingTestContext(), addr1, []wasmTypes.CosmosMsg{bankMsg, {}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "message at index 1")
+}
+
+func TestEncodeRelativeIBCTimeout(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	ctx := encodingTestContext().WithBlockHeight(100).WithChainID("secret-4")
+
+	transfer := func(timeout wasmTypes.IBCTimeout) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			IBC: &wasmTypes.IBCMsg{
+				Transfer: &wasmTypes.TransferMsg{
+					ChannelID: "channel-0",
+					ToAddress: "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+					Amount:    wasmTypes.NewCoin(1000, "uscrt"),
+					Timeout:   timeout,
+				},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		timeout   wasmTypes.IBCTimeout
+		expHeight clienttypes.Height
+		isError   bool
+	}{
+		"relative only": {
+			timeout:   wasmTypes.IBCTimeout{RelativeBlocks: 50},
+			expHeight: clienttypes.NewHeight(4, 150),
+		},
+		"absolute only": {
+			timeout:   wasmTypes.IBCTimeout{Block: &wasmTypes.IBCTimeoutBlock{Revision: 1, Height: 12345}},
+			expHeight: clienttypes.NewHeight(1, 12345),
+		},
+		"both set": {
+			timeout: wasmTypes.IBCTimeout{
+				Block:          &wasmTypes.IBCTimeoutBlock{Revision: 1, Height: 12345},
+				RelativeBlocks: 50,
+			},
+			isError: true,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			msg := transfer(tc.timeout)
+			res, err := DefaultEncoders().Encode(ctx, addr1, msg)
+			if tc.isError {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, types.ErrInvalidMsg), err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, tc.expHeight, res[0].(*ibctransfertypes.MsgTransfer).TimeoutHeight)
+			// the message of the contract is left as it was
+			assert.Equal(t, tc.timeout, msg.IBC.Transfer.Timeout)
+		})
+	}
+
+	// encoders can't resolve relative timeouts themselves
+	_, err := EncodeIBCMsg(addr1, transfer(wasmTypes.IBCTimeout{RelativeBlocks: 50}).IBC)
+	require.Error(t, err)
+}
+
+func TestDispatchRejectSelfSends(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	selfSend := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: contractAddr.String(),
+				ToAddress:   contractAddr.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+			},
+		},
+	}
+
+	// allowed by default
+	require.False(t, keeper.GetParams(ctx).RejectSelfSends)
+	_, _, err := keeper.Dispatch(ctx, contractAddr, selfSend)
+	require.NoError(t, err)
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	params := keeper.GetParams(ctx)
+	params.RejectSelfSends = true
+	keeper.SetParams(ctx, params)
+	_, _, err = keeper.Dispatch(ctx, contractAddr, selfSend)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, types.ErrInvalidRecipient), err)
+
+	// sends to others are still fine
+	_, _, rcpt := keyPubAddr()
+	selfSend.Bank.Send.ToAddress = rcpt.String()
+	_, _, err = keeper.Dispatch(ctx, contractAddr, selfSend)
+	require.NoError(t, err)
+}
+
+func TestDispatchRejectModuleAccountSends(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	// the bank keeper blocks sends to the other module accounts, but not to the distribution one
+	distrAddr := authtypes.NewModuleAddress(distributiontypes.ModuleName)
+	moduleSend := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: contractAddr.String(),
+				ToAddress:   distrAddr.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+			},
+		},
+	}
+
+	// allowed by default
+	require.False(t, keeper.GetParams(ctx).RejectModuleAccountSends)
+	_, _, err := keeper.Dispatch(ctx, contractAddr, moduleSend)
+	require.NoError(t, err)
+
+	params := keeper.GetParams(ctx)
+	params.RejectModuleAccountSends = true
+	keeper.SetParams(ctx, params)
+	_, _, err = keeper.Dispatch(ctx, contractAddr, moduleSend)
+	assert.True(t, errors.Is(err, types.ErrInvalidRecipient), err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 900)), bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	// whatever message the funds are sent with
+	stargateSend, err := (&banktypes.MsgSend{
+		FromAddress: contractAddr.String(),
+		ToAddress:   distrAddr.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("denom", 100)),
+	}).Marshal()
+	require.NoError(t, err)
+	for name, msg := range map[string]wasmTypes.CosmosMsg{
+		"stargate send": {Stargate: &wasmTypes.StargateMsg{TypeURL: "/cosmos.bank.v1beta1.MsgSend", Value: stargateSend}},
+		"authz send":    {Authz: &wasmTypes.AuthzMsg{Exec: &wasmTypes.AuthzExecMsg{Granter: contractAddr.String(), Msgs: []wasmTypes.CosmosMsg{moduleSend}}}},
+		"multi send": {Bank: &wasmTypes.BankMsg{MultiSend: &wasmTypes.MultiSendMsg{
+			Inputs:  []wasmTypes.BankInput{{Address: contractAddr.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")}}},
+			Outputs: []wasmTypes.BankOutput{{Address: distrAddr.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")}}},
+		}}},
+	} {
+		_, _, err = keeper.Dispatch(ctx, contractAddr, msg)
+		assert.True(t, errors.Is(err, types.ErrInvalidRecipient), "%s: %v", name, err)
+	}
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 900)), bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	// sends to other accounts are still fine
+	_, _, rcpt := keyPubAddr()
+	moduleSend.Bank.Send.ToAddress = rcpt.String()
+	_, _, err = keeper.Dispatch(ctx, contractAddr, moduleSend)
+	require.NoError(t, err)
+}
+
+func TestDispatchCodeAllowedMsgVariants(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, creator := keyPubAddr()
+	keeper.setContractInfo(ctx, contractAddr, &types.ContractInfo{CodeID: 1, Creator: creator, Label: "token"})
+	_, _, rcpt := keyPubAddr()
+	send := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: contractAddr.String(),
+				ToAddress:   rcpt.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+			},
+		},
+	}
+	delegate := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Delegate: &wasmTypes.DelegateMsg{
+				Validator: sdk.ValAddress(rcpt).String(),
+				Amount:    wasmTypes.NewCoin(100, "denom"),
+			},
+		},
+	}
+
+	require.NoError(t, keeper.SetCodeAllowedMsgVariants(ctx, 1, []string{"bank"}))
+	assert.Equal(t, []string{"bank"}, keeper.GetCodeAllowedMsgVariants(ctx, 1))
+	_, _, err := keeper.Dispatch(ctx, contractAddr, send)
+	require.NoError(t, err)
+	_, _, err = keeper.Dispatch(ctx, contractAddr, delegate)
+	assert.True(t, sdkerrors.ErrUnauthorized.Is(err), err)
+
+	// single variants can be allowed too
+	require.NoError(t, keeper.SetCodeAllowedMsgVariants(ctx, 1, []string{"bank_burn"}))
+	_, _, err = keeper.Dispatch(ctx, contractAddr, send)
+	assert.True(t, sdkerrors.ErrUnauthorized.Is(err), err)
+
+	// other codes and accounts that aren't contracts aren't restricted
+	require.NoError(t, keeper.SetCodeAllowedMsgVariants(ctx, 2, []string{"staking"}))
+	_, _, err = keeper.Dispatch(ctx, rcpt, wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{
+		FromAddress: rcpt.String(),
+		ToAddress:   contractAddr.String(),
+		Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+	}}})
+	require.NoError(t, err)
+
+	// no variants allows all of them again
+	require.NoError(t, keeper.SetCodeAllowedMsgVariants(ctx, 1, nil))
+	assert.Nil(t, keeper.GetCodeAllowedMsgVariants(ctx, 1))
+	_, _, err = keeper.Dispatch(ctx, contractAddr, send)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 900)), bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	err = keeper.SetCodeAllowedMsgVariants(ctx, 1, []string{""})
+	assert.True(t, types.ErrEmpty.Is(err), err)
+}
+
+func TestDispatchAuthzInnerMsgChecks(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, creator := keyPubAddr()
+	keeper.setContractInfo(ctx, contractAddr, &types.ContractInfo{CodeID: 1, Creator: creator, Label: "token"})
+	granter, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, target := keyPubAddr()
+	exec := func(inner wasmTypes.CosmosMsg) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{Authz: &wasmTypes.AuthzMsg{Exec: &wasmTypes.AuthzExecMsg{Granter: granter.String(), Msgs: []wasmTypes.CosmosMsg{inner}}}}
+	}
+	execute := func(msg string) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{Wasm: &wasmTypes.WasmMsg{Execute: &wasmTypes.ExecuteMsg{ContractAddr: target.String(), Msg: []byte(msg)}}}
+	}
+	gasLimit := uint64(100_000)
+	limitedExecute := execute(`{}`)
+	limitedExecute.Wasm.Execute.GasLimit = &gasLimit
+
+	params := keeper.GetParams(ctx)
+	params.MaxWasmMsgSize = 10
+	params.RejectSelfSends = true
+	params.MinInstantiateFunds = sdk.NewCoins(sdk.NewInt64Coin("denom", 1))
+	keeper.SetParams(ctx, params)
+	require.NoError(t, keeper.SetCodeAllowedMsgVariants(ctx, 1, []string{"authz", "bank", "wasm", "ibc"}))
+
+	for name, tc := range map[string]struct {
+		inner wasmTypes.CosmosMsg
+		err   *sdkerrors.Error
+	}{
+		"variant not allowed": {
+			inner: wasmTypes.CosmosMsg{Staking: &wasmTypes.StakingMsg{Delegate: &wasmTypes.DelegateMsg{
+				Validator: sdk.ValAddress(target).String(),
+				Amount:    wasmTypes.NewCoin(100, "denom"),
+			}}},
+			err: sdkerrors.ErrUnauthorized,
+		},
+		"self send": {
+			inner: wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{
+				FromAddress: granter.String(),
+				ToAddress:   granter.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+			}}},
+			err: types.ErrInvalidRecipient,
+		},
+		"oversized wasm msg": {
+			inner: execute(strings.Repeat("a", wasmMsgEnvelopeSize+11)),
+			err:   types.ErrLimit,
+		},
+		"nonexistent contract": {
+			inner: execute(`{}`),
+			err:   types.ErrContractNotFound,
+		},
+		"gas limited execute": {
+			inner: limitedExecute,
+			err:   types.ErrInvalidMsg,
+		},
+		"instantiate without funds": {
+			inner: wasmTypes.CosmosMsg{Wasm: &wasmTypes.WasmMsg{Instantiate: &wasmTypes.InstantiateMsg{CodeID: 1, Msg: []byte(`{}`), Label: "label"}}},
+			err:   sdkerrors.ErrInsufficientFunds,
+		},
+		"unbound ibc port": {
+			inner: wasmTypes.CosmosMsg{IBC: &wasmTypes.IBCMsg{Transfer: &wasmTypes.TransferMsg{
+				SourcePort: "unbound",
+				ChannelID:  "channel-0",
+				ToAddress:  target.String(),
+				Amount:     wasmTypes.NewCoin(100, "denom"),
+				Timeout:    wasmTypes.IBCTimeout{Timestamp: 1},
+			}}},
+			err: types.ErrInvalidMsg,
+		},
+	} {
+		_, _, err := keeper.Dispatch(ctx, contractAddr, exec(tc.inner))
+		assert.True(t, tc.err.Is(err), "%s: %v", name, err)
+		assert.ErrorContains(t, err, "authz exec message at index 0", name)
+	}
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, granter))
+}
+
+func TestDispatchAuthzExec(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, authzKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.AuthzKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	granter, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, recipient := keyPubAddr()
+	exec := wasmTypes.CosmosMsg{Authz: &wasmTypes.AuthzMsg{Exec: &wasmTypes.AuthzExecMsg{
+		Granter: granter.String(),
+		Msgs: []wasmTypes.CosmosMsg{{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{
+			FromAddress: granter.String(),
+			ToAddress:   recipient.String(),
+			Amount:      wasmTypes.Coins{wasmTypes.NewCoin(300, "denom")},
+		}}}},
+	}}}
+
+	// the contract can't send the tokens of the granter before it is granted to
+	_, _, err := keeper.Dispatch(ctx, contractAddr, exec)
+	assert.True(t, sdkerrors.ErrUnauthorized.Is(err), err)
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, granter))
+
+	sendLimit := sdk.NewCoins(sdk.NewInt64Coin("denom", 500))
+	require.NoError(t, authzKeeper.SaveGrant(ctx, contractAddr, granter, banktypes.NewSendAuthorization(sendLimit), ctx.BlockTime().Add(time.Hour)))
+	_, _, err = keeper.Dispatch(ctx, contractAddr, exec)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 700)), bankKeeper.GetAllBalances(ctx, granter))
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 300)), bankKeeper.GetAllBalances(ctx, recipient))
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	// 200 of the limit of the grant are left
+	_, _, err = keeper.Dispatch(ctx, contractAddr, exec)
+	assert.True(t, sdkerrors.ErrInsufficientFunds.Is(err), err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 700)), bankKeeper.GetAllBalances(ctx, granter))
+}
+
+func TestDispatchFeegrant(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, feegrantKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.FeegrantKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	grantee, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	expiration := ctx.BlockTime().Add(time.Hour).Truncate(time.Second)
+
+	_, _, err := keeper.Dispatch(ctx, contractAddr, wasmTypes.CosmosMsg{Feegrant: &wasmTypes.FeegrantMsg{
+		GrantAllowance: &wasmTypes.GrantAllowanceMsg{
+			Grantee: grantee.String(),
+			Allowance: wasmTypes.FeeAllowance{Basic: &wasmTypes.BasicAllowance{
+				SpendLimit: wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+				Expiration: expiration.Unix(),
+			}},
+		},
+	}})
+	require.NoError(t, err)
+
+	allowance, err := feegrantKeeper.GetAllowance(ctx, contractAddr, grantee)
+	require.NoError(t, err)
+	basic, ok := allowance.(*feegrant.BasicAllowance)
+	require.True(t, ok, "%T", allowance)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 100)), basic.SpendLimit)
+	require.NotNil(t, basic.Expiration)
+	assert.True(t, expiration.Equal(*basic.Expiration), basic.Expiration)
+
+	// the grantee only has one allowance of the contract
+	_, _, err = keeper.Dispatch(ctx, contractAddr, wasmTypes.CosmosMsg{Feegrant: &wasmTypes.FeegrantMsg{
+		GrantAllowance: &wasmTypes.GrantAllowanceMsg{
+			Grantee:   grantee.String(),
+			Allowance: wasmTypes.FeeAllowance{Basic: &wasmTypes.BasicAllowance{}},
+		},
+	}})
+	assert.Error(t, err)
+}
+
+func TestDispatchExecuteGasLimit(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, contractAddr := keyPubAddr()
+	_, _, target := keyPubAddr()
+	execute := func(gasLimit *uint64) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Execute: &wasmTypes.ExecuteMsg{
+					ContractAddr: target.String(),
+					Msg:          []byte(`{}`),
+					GasLimit:     gasLimit,
+				},
+			},
+		}
+	}
+	// the target doesn't exist, so dispatching fails after doing a fixed amount of work
+	dispatch := func(gasLimit *uint64) (uint64, error) {
+		ctx := ctx.WithGasMeter(sdk.NewGasMeter(1_000_000))
+		_, _, err := keeper.Dispatch(ctx, contractAddr, execute(gasLimit))
+		return ctx.GasMeter().GasConsumed(), err
+	}
+
+	// without a limit all remaining gas is forwarded
+	gasUsed, err := dispatch(nil)
+	assert.True(t, types.ErrContractNotFound.Is(err), err)
+
+	// a limit above what is needed changes nothing
+	limit := uint64(500_000)
+	limitedGasUsed, err := dispatch(&limit)
+	assert.True(t, types.ErrContractNotFound.Is(err), err)
+	assert.Equal(t, gasUsed, limitedGasUsed)
+
+	// a limit below it fails the message, and the caller is charged the limit on top of the gas used before the
+	// limited meter is set up
+	limit = 1
+	limitedGasUsed, err = dispatch(&limit)
+	assert.True(t, sdkerrors.ErrOutOfGas.Is(err), err)
+	assert.Less(t, limitedGasUsed, gasUsed)
+	limit = 2
+	higherLimitGasUsed, err := dispatch(&limit)
+	assert.True(t, sdkerrors.ErrOutOfGas.Is(err), err)
+	assert.Equal(t, limitedGasUsed+1, higherLimitGasUsed)
+
+	// the message of the contract isn't modified
+	msg := execute(&limit)
+	_, _, _ = keeper.Dispatch(ctx.WithGasMeter(sdk.NewInfiniteGasMeter()), contractAddr, msg)
+	assert.Equal(t, &limit, msg.Wasm.Execute.GasLimit)
+}
+
+func TestDispatchRejectSelfExecutes(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, contractAddr := keyPubAddr()
+	_, _, otherAddr := keyPubAddr()
+	execute := func(target sdk.AccAddress, funds wasmTypes.Coins) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Execute: &wasmTypes.ExecuteMsg{
+					ContractAddr: target.String(),
+					Msg:          []byte(`{}`),
+					Send:         funds,
+				},
+			},
+		}
+	}
+	funds := wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")}
+
+	// allowed by default
+	require.NoError(t, validateSelfReference(ctx, contractAddr, execute(contractAddr, funds), keeper.GetParams(ctx)))
+
+	params := keeper.GetParams(ctx)
+	params.RejectSelfExecutes = true
+	keeper.SetParams(ctx, params)
+	_, _, err := keeper.Dispatch(ctx, contractAddr, execute(contractAddr, funds))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, types.ErrInvalidMsg), err)
+	assert.Contains(t, err.Error(), "execute itself")
+
+	// executing itself without funds, or others with funds, is fine
+	require.NoError(t, validateSelfReference(ctx, contractAddr, execute(contractAddr, nil), keeper.GetParams(ctx)))
+	require.NoError(t, validateSelfReference(ctx, contractAddr, execute(otherAddr, funds), keeper.GetParams(ctx)))
+}
+
+func TestDispatchToNonexistentContract(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, contractAddr := keyPubAddr()
+	_, _, unknownAddr := keyPubAddr()
+	msg := wasmTypes.CosmosMsg{
+		Wasm: &wasmTypes.WasmMsg{
+			Execute: &wasmTypes.ExecuteMsg{
+				ContractAddr: unknownAddr.String(),
+				Msg:          []byte(`{}`),
+			},
+		},
+	}
+	_, _, err := keeper.Dispatch(ctx, contractAddr, msg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, types.ErrContractNotFound), err)
+}
+
+func TestDispatchStakingToNonexistentValidator(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, stakingKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.StakingKeeper, keepers.WasmKeeper
+
+	valAddr := addValidator(ctx, stakingKeeper, accKeeper, bankKeeper, sdk.NewInt64Coin("stake", 1000000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	// a well-formed operator address no validator has
+	_, _, unknown := keyPubAddr()
+	unknownVal := sdk.ValAddress(unknown).String()
+	amount := wasmTypes.NewCoin(100, "stake")
+
+	delegate := wasmTypes.CosmosMsg{Staking: &wasmTypes.StakingMsg{Delegate: &wasmTypes.DelegateMsg{Validator: unknownVal, Amount: amount}}}
+	for name, msg := range map[string]wasmTypes.CosmosMsg{
+		"delegate":   delegate,
+		"undelegate": {Staking: &wasmTypes.StakingMsg{Undelegate: &wasmTypes.UndelegateMsg{Validator: unknownVal, Amount: amount}}},
+		"redelegate from": {Staking: &wasmTypes.StakingMsg{Redelegate: &wasmTypes.RedelegateMsg{
+			SrcValidator: unknownVal, DstValidator: valAddr.String(), Amount: amount,
+		}}},
+		"redelegate to": {Staking: &wasmTypes.StakingMsg{Redelegate: &wasmTypes.RedelegateMsg{
+			SrcValidator: valAddr.String(), DstValidator: unknownVal, Amount: amount,
+		}}},
+		"authz delegate": {Authz: &wasmTypes.AuthzMsg{Exec: &wasmTypes.AuthzExecMsg{Granter: contractAddr.String(), Msgs: []wasmTypes.CosmosMsg{delegate}}}},
+	} {
+		_, _, err := keeper.Dispatch(ctx, contractAddr, msg)
+		assert.True(t, stakingtypes.ErrNoValidatorFound.Is(err), "%s: %v", name, err)
+		assert.ErrorContains(t, err, unknownVal, name)
+	}
+
+	// the validator that exists is fine
+	_, _, err := keeper.Dispatch(ctx, contractAddr, wasmTypes.CosmosMsg{Staking: &wasmTypes.StakingMsg{
+		Delegate: &wasmTypes.DelegateMsg{Validator: valAddr.String(), Amount: amount},
+	}})
+	require.NoError(t, err)
+}
+
+func TestConvertWasmCoins(t *testing.T) {
+	cases := map[string]struct {
+		input  []wasmTypes.Coin
+		exp    sdk.Coins
+		expErr *sdkerrors.Error
+	}{
+		"empty": {
+			input: nil,
+			exp:   sdk.Coins{},
+		},
+		"sorted by denom, zero amounts kept": {
+			input: []wasmTypes.Coin{wasmTypes.NewCoin(2, "uscrt"), wasmTypes.NewCoin(0, "denom"), wasmTypes.NewCoin(1, "atom")},
+			exp:   sdk.Coins{sdk.NewInt64Coin("atom", 1), sdk.NewInt64Coin("denom", 0), sdk.NewInt64Coin("uscrt", 2)},
+		},
+		"invalid denom": {
+			input:  []wasmTypes.Coin{wasmTypes.NewCoin(1, "1x")},
+			expErr: sdkerrors.ErrInvalidCoins,
+		},
+		"duplicate denom": {
+			input:  []wasmTypes.Coin{wasmTypes.NewCoin(1, "denom"), wasmTypes.NewCoin(2, "denom")},
+			expErr: sdkerrors.ErrInvalidCoins,
+		},
+		"negative amount": {
+			input:  []wasmTypes.Coin{{Denom: "denom", Amount: "-1"}},
+			expErr: types.ErrInvalidAmount,
+		},
+		"empty amount": {
+			input:  []wasmTypes.Coin{{Denom: "denom", Amount: ""}},
+			expErr: types.ErrInvalidAmount,
+		},
+		"amount isn't an integer": {
+			input:  []wasmTypes.Coin{{Denom: "denom", Amount: "1.5"}},
+			expErr: types.ErrInvalidAmount,
+		},
+		"amount overflows sdk.Int": {
+			input:  []wasmTypes.Coin{{Denom: "denom", Amount: strings.Repeat("9", maxCoinAmountLength)}},
+			expErr: types.ErrInvalidAmount,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			coins, err := convertWasmCoins(tc.input)
+			if tc.expErr != nil {
+				require.Error(t, err)
+				assert.True(t, tc.expErr.Is(err), err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, coins)
+		})
+	}
+}
+
+func TestEncodeStargatePreservesCoins(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+
+	// more than fits in the uint128 amounts of contracts
+	amount, ok := sdk.NewIntFromString("1" + strings.Repeat("0", 70))
+	require.True(t, ok)
+	send := &banktypes.MsgSend{
+		FromAddress: addr1.String(),
+		ToAddress:   addr2.String(),
+		Amount:      sdk.NewCoins(sdk.NewCoin("uscrt", amount)),
+	}
+	bz, err := send.Marshal()
+	require.NoError(t, err)
+
+	encoder := NewMessageHandler(nil, nil, MakeEncodingConfig().InterfaceRegistry).encoders
+	res, err := encoder.Encode(encodingTestContext(), addr1, wasmTypes.CosmosMsg{
+		Stargate: &wasmTypes.StargateMsg{TypeURL: sdk.MsgTypeURL(send), Value: bz},
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, send, res[0])
+
+	encoded, err := res[0].(*banktypes.MsgSend).Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, bz, encoded)
+}
+
+// TestEncodeExpandingMsgOrder guards the order of the sdk.Msgs of messages that are encoded into several,
+// as it's part of consensus
+func TestEncodeExpandingMsgOrder(t *testing.T) {
+	_, _, contractAddr := keyPubAddr()
+	_, _, rcpt := keyPubAddr()
+	valAddr := make(sdk.ValAddress, 20)
+	valAddr[0] = 12
+
+	withdraw := wasmTypes.CosmosMsg{
+		Staking: &wasmTypes.StakingMsg{
+			Withdraw: &wasmTypes.WithdrawMsg{Validator: valAddr.String(), Recipient: rcpt.String()},
+		},
+	}
+	expected := []sdk.Msg{
+		&distributiontypes.MsgSetWithdrawAddress{DelegatorAddress: contractAddr.String(), WithdrawAddress: rcpt.String()},
+		&distributiontypes.MsgWithdrawDelegatorReward{DelegatorAddress: contractAddr.String(), ValidatorAddress: valAddr.String()},
+	}
+
+	encoder := NewMessageHandler(nil, nil, MakeEncodingConfig().InterfaceRegistry).encoders
+	for i := 0; i < 10; i++ {
+		res, err := encoder.Encode(encodingTestContext(), contractAddr, withdraw)
+		require.NoError(t, err)
+		require.Equal(t, expected, res)
+	}
+
+	// in a batch, the sdk.Msgs of each message stay together, in the order of the messages
+	send := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: contractAddr.String(),
+				ToAddress:   rcpt.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(1, "denom")},
+			},
+		},
+	}
+	res, err := encoder.EncodeWithMapping(encodingTestContext(), contractAddr, []wasmTypes.CosmosMsg{send, withdraw, send})
+	require.NoError(t, err)
+	var sources []int
+	var msgs []sdk.Msg
+	for _, m := range res {
+		sources = append(sources, m.SourceIndex)
+		msgs = append(msgs, m.Msg)
+	}
+	assert.Equal(t, []int{0, 1, 1, 2}, sources)
+	assert.Equal(t, expected, msgs[1:3])
+}
+
+func TestSimulateEncodedMsg(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, rcpt := keyPubAddr()
+	send := func(amount int64) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress: contractAddr.String(),
+					ToAddress:   rcpt.String(),
+					Amount:      wasmTypes.Coins{wasmTypes.NewCoin(uint64(amount), "denom")},
+				},
+			},
+		}
+	}
+
+	ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter()).WithEventManager(sdk.NewEventManager())
+	gasUsed, err := keeper.SimulateEncodedMsg(ctx, contractAddr, send(100))
+	require.NoError(t, err)
+	assert.NotZero(t, gasUsed)
+	assert.Equal(t, gasUsed, ctx.GasMeter().GasConsumed())
+	assert.Empty(t, ctx.EventManager().Events())
+
+	// the send wasn't persisted
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+	assert.True(t, bankKeeper.GetAllBalances(ctx, rcpt).IsZero())
+
+	// a send that would fail is reported, and doesn't change anything either
+	_, err = keeper.SimulateEncodedMsg(ctx, contractAddr, send(2000))
+	assert.True(t, sdkerrors.ErrInsufficientFunds.Is(err), err)
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	// the simulation can use the gas left in ctx, and no more
+	limitedCtx := ctx.WithGasMeter(sdk.NewGasMeter(gasUsed + 1000))
+	limitedCtx.GasMeter().ConsumeGas(1000, "before the simulation")
+	limitedGasUsed, err := keeper.SimulateEncodedMsg(limitedCtx, contractAddr, send(100))
+	require.NoError(t, err)
+	assert.Equal(t, gasUsed, limitedGasUsed)
+	assert.Equal(t, gasUsed+1000, limitedCtx.GasMeter().GasConsumed())
+
+	limitedCtx = ctx.WithGasMeter(sdk.NewGasMeter(gasUsed + 1000))
+	limitedCtx.GasMeter().ConsumeGas(1001, "before the simulation")
+	limitedGasUsed, err = keeper.SimulateEncodedMsg(limitedCtx, contractAddr, send(100))
+	assert.True(t, sdkerrors.ErrOutOfGas.Is(err), err)
+	assert.Equal(t, gasUsed-1, limitedGasUsed)
+	assert.Equal(t, gasUsed+1000, limitedCtx.GasMeter().GasConsumed())
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+}
+
+// boundPorts is a PortKeeper with a fixed set of bound ports
+type boundPorts map[string]bool
+
+func (p boundPorts) IsBound(_ sdk.Context, portID string) bool {
+	return p[portID]
+}
+
+func TestDispatchIBCTransferSourcePort(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, contractAddr := keyPubAddr()
+	transfer := func(port string) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			IBC: &wasmTypes.IBCMsg{
+				Transfer: &wasmTypes.TransferMsg{
+					SourcePort: port,
+					ChannelID:  "channel-0",
+					ToAddress:  "cosmos1vx8knpllrj7n963p9ttd80w47kpacrhuts497x",
+					Amount:     wasmTypes.NewCoin(1000, "uscrt"),
+					Timeout:    wasmTypes.IBCTimeout{Timestamp: 1640000000000000000},
+				},
+			},
+		}
+	}
+
+	// the transfer port is always allowed
+	require.NoError(t, keeper.verifyIBCSourcePort(ctx, transfer("")))
+	require.NoError(t, keeper.verifyIBCSourcePort(ctx, transfer(ibctransfertypes.PortID)))
+
+	// without a port keeper, no other port is
+	_, _, err := keeper.Dispatch(ctx, contractAddr, transfer("middleware"))
+	assert.True(t, types.ErrInvalidMsg.Is(err), err)
+
+	keeper.portKeeper = boundPorts{"middleware": true}
+	require.NoError(t, keeper.verifyIBCSourcePort(ctx, transfer("middleware")))
+	_, _, err = keeper.Dispatch(ctx, contractAddr, transfer("unbound"))
+	assert.True(t, types.ErrInvalidMsg.Is(err), err)
+	assert.Contains(t, err.Error(), "IBC port unbound is not bound")
+}
+
+func TestEncodeErrorField(t *testing.T) {
+	_, _, addr := keyPubAddr()
+	valAddr := sdk.ValAddress(addr).String()
+	coins := wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")}
+
+	cases := map[string]struct {
+		msg    wasmTypes.CosmosMsg
+		field  string
+		expErr *sdkerrors.Error
+	}{
+		"bank send from": {
+			msg:    wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{FromAddress: "invalid", ToAddress: addr.String(), Amount: coins}}},
+			field:  "bank.send.from_address",
+			expErr: sdkerrors.ErrInvalidAddress,
+		},
+		"bank send to": {
+			msg:    wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{FromAddress: addr.String(), ToAddress: "invalid", Amount: coins}}},
+			field:  "bank.send.to_address",
+			expErr: types.ErrInvalidRecipient,
+		},
+		"multi send output": {
+			msg: wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{MultiSend: &wasmTypes.MultiSendMsg{
+				Inputs:  []wasmTypes.BankInput{{Address: addr.String(), Coins: coins}},
+				Outputs: []wasmTypes.BankOutput{{Address: addr.String(), Coins: coins[:0]}, {Address: "invalid", Coins: coins}},
+			}}},
+			field:  "bank.multi_send.outputs[1].address",
+			expErr: types.ErrInvalidRecipient,
+		},
+		"redelegate destination": {
+			msg: wasmTypes.CosmosMsg{Staking: &wasmTypes.StakingMsg{Redelegate: &wasmTypes.RedelegateMsg{
+				SrcValidator: valAddr,
+				DstValidator: addr.String(),
+				Amount:       wasmTypes.NewCoin(100, "denom"),
+			}}},
+			field:  "staking.redelegate.dst_validator",
+			expErr: sdkerrors.ErrInvalidAddress,
+		},
+		"execute contract": {
+			msg: wasmTypes.CosmosMsg{Wasm: &wasmTypes.WasmMsg{Execute: &wasmTypes.ExecuteMsg{
+				ContractAddr: "invalid",
+				Msg:          []byte("{}"),
+			}}},
+			field:  "wasm.execute.contract_addr",
+			expErr: sdkerrors.ErrInvalidAddress,
+		},
+	}
+
+	encoder := DefaultEncoders()
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := encoder.Encode(encodingTestContext(), addr, tc.msg)
+			require.Error(t, err)
+			field, ok := types.ErrorField(err)
+			require.True(t, ok, err)
+			assert.Equal(t, tc.field, field)
+			assert.Contains(t, err.Error(), tc.field+": ")
+			// the error keeps its code
+			assert.True(t, tc.expErr.Is(err), err)
+			_, code, _ := sdkerrors.ABCIInfo(err, false)
+			assert.Equal(t, tc.expErr.ABCICode(), code)
+		})
+	}
+
+	// other errors don't name a field
+	_, err := encoder.Encode(encodingTestContext(), addr, wasmTypes.CosmosMsg{})
+	_, ok := types.ErrorField(err)
+	assert.False(t, ok, err)
+}
+
+func TestEncodeStargateMsgResolvedType(t *testing.T) {
+	_, _, addr1 := keyPubAddr()
+	_, _, addr2 := keyPubAddr()
+	send := &banktypes.MsgSend{
+		FromAddress: addr1.String(),
+		ToAddress:   addr2.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("uscrt", 12345)),
+	}
+	bz, err := send.Marshal()
+	require.NoError(t, err)
+	msg := &wasmTypes.StargateMsg{TypeURL: sdk.MsgTypeURL(send), Value: bz}
+
+	encoder := EncodeStargateMsg(MakeEncodingConfig().InterfaceRegistry)
+	first, err := encoder(addr1, msg)
+	require.NoError(t, err)
+	// the second message uses the type resolved for the first one
+	second, err := encoder(addr1, msg)
+	require.NoError(t, err)
+	assert.Equal(t, []sdk.Msg{send}, first)
+	assert.Equal(t, first, second)
+	assert.NotSame(t, first[0], second[0])
+
+	_, err = encoder(addr1, &wasmTypes.StargateMsg{TypeURL: sdk.MsgTypeURL(send), Value: []byte{0xff}})
+	assert.True(t, types.ErrInvalidMsg.Is(err), err)
+}
+
+func TestDispatchSendDeadline(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+	ctx = ctx.WithBlockHeight(100)
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, rcpt := keyPubAddr()
+	send := func(deadline uint64) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress:    contractAddr.String(),
+					ToAddress:      rcpt.String(),
+					Amount:         wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+					DeadlineHeight: deadline,
+				},
+			},
+		}
+	}
+
+	_, _, err := keeper.Dispatch(ctx, contractAddr, send(99))
+	assert.True(t, types.ErrDeadlineExceeded.Is(err), err)
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	// the deadline height itself is still fine
+	for _, deadline := range []uint64{100, 101, 0} {
+		_, _, err = keeper.Dispatch(ctx, contractAddr, send(deadline))
+		require.NoError(t, err, deadline)
+	}
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 300)), bankKeeper.GetAllBalances(ctx, rcpt))
 }
@@ -2,7 +2,9 @@ package keeper
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -18,6 +20,7 @@ import (
 	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
 	govkeeper "github.com/cosmos/cosmos-sdk/x/gov/keeper"
 	mintkeeper "github.com/cosmos/cosmos-sdk/x/mint/keeper"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
 	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
 
 	"github.com/tendermint/tendermint/crypto"
@@ -25,7 +28,9 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/store/prefix"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/address"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
 	sdktxsigning "github.com/cosmos/cosmos-sdk/types/tx/signing"
 	"github.com/cosmos/cosmos-sdk/x/auth/ante"
@@ -42,15 +47,28 @@ type Keeper struct {
 	legacyAmino   codec.LegacyAmino
 	accountKeeper authkeeper.AccountKeeper
 	bankKeeper    bankkeeper.Keeper
+	stakingKeeper stakingkeeper.Keeper
 
 	wasmer       wasm.Wasmer
 	queryPlugins QueryPlugins
 	messenger    MessageHandler
+	// replyer replaces the enclave in replies to submessages if set, it is nil outside of tests
+	replyer replyer
 	// queryGasLimit is the max wasm gas that can be spent on executing a query with a contract
 	queryGasLimit uint64
+	// serviceRouter routes the messages of the modules without a legacy route, like authz
 	serviceRouter MsgServiceRouter
 	// authZPolicy   AuthorizationPolicy
-	//paramSpace    subspace.Subspace
+	paramSpace paramtypes.Subspace
+	// portKeeper is used to check the source ports of IBC transfers, it may be nil
+	portKeeper PortKeeper
+	// tStoreKey is the transient store of the per block dispatch counts
+	tStoreKey sdk.StoreKey
+}
+
+// PortKeeper is the part of the IBC port keeper the compute module uses
+type PortKeeper interface {
+	IsBound(ctx sdk.Context, portID string) bool
 }
 
 // MsgServiceRouter expected MsgServiceRouter interface
@@ -64,46 +82,52 @@ func NewKeeper(
 	cdc codec.Codec,
 	legacyAmino codec.LegacyAmino,
 	storeKey sdk.StoreKey,
+	tStoreKey sdk.StoreKey,
+	paramSpace paramtypes.Subspace,
 	accountKeeper authkeeper.AccountKeeper,
 	bankKeeper bankkeeper.Keeper,
 	govKeeper govkeeper.Keeper,
 	distKeeper distrkeeper.Keeper,
 	mintKeeper mintkeeper.Keeper,
 	stakingKeeper stakingkeeper.Keeper,
-	//serviceRouter MsgServiceRouter,
+	serviceRouter MsgServiceRouter,
 	router sdk.Router,
 	homeDir string,
 	wasmConfig *types.WasmConfig,
 	supportedFeatures string,
 	customEncoders *MessageEncoders,
 	customPlugins *QueryPlugins,
+	opts ...Option,
 ) Keeper {
 	wasmer, err := wasm.NewWasmer(filepath.Join(homeDir, "wasm"), supportedFeatures, wasmConfig.CacheSize, wasmConfig.EnclaveCacheSize)
 	if err != nil {
 		panic(err)
 	}
 
-	/*
-		// set KeyTable if it has not already been set
-		if !paramSpace.HasKeyTable() {
-			paramSpace = paramSpace.WithKeyTable(types.ParamKeyTable())
-		}
-	*/
+	// set KeyTable if it has not already been set
+	if !paramSpace.HasKeyTable() {
+		paramSpace = paramSpace.WithKeyTable(types.ParamKeyTable())
+	}
 
 	keeper := Keeper{
 		storeKey:      storeKey,
+		tStoreKey:     tStoreKey,
 		cdc:           cdc,
 		legacyAmino:   legacyAmino,
 		wasmer:        *wasmer,
 		accountKeeper: accountKeeper,
 		bankKeeper:    bankKeeper,
-		messenger:     NewMessageHandler(router, customEncoders),
+		stakingKeeper: stakingKeeper,
+		messenger:     NewMessageHandler(router, customEncoders, cdc),
 		queryGasLimit: wasmConfig.SmartQueryGasLimit,
-		//serviceRouter: serviceRouter,
+		paramSpace:    paramSpace,
+		serviceRouter: serviceRouter,
 		// authZPolicy:   DefaultAuthorizationPolicy{},
-		//paramSpace:    paramSpace,
 	}
 	keeper.queryPlugins = DefaultQueryPlugins(govKeeper, distKeeper, mintKeeper, bankKeeper, stakingKeeper, &keeper).Merge(customPlugins)
+	for _, o := range opts {
+		o.apply(&keeper)
+	}
 	return keeper
 }
 
@@ -273,6 +297,30 @@ func (k Keeper) Instantiate(ctx sdk.Context, codeID uint64, creator /* , admin *
 
 		func (k Keeper) instantiate(ctx sdk.Context, codeID uint64, creator , admin sdk.AccAddress, initMsg []byte, label string, deposit sdk.Coins, callbackSig []byte) (sdk.AccAddress, error) {
 	*/
+	return k.instantiate(ctx, codeID, creator, initMsg, label, deposit, callbackSig, func() sdk.AccAddress {
+		return k.generateContractAddress(ctx, codeID)
+	})
+}
+
+// Instantiate2 is like Instantiate, but creates the contract at PredictableContractAddress for the code, the creator
+// and salt, rather than at the next free address. It fails if an account already exists at that address.
+func (k Keeper) Instantiate2(ctx sdk.Context, codeID uint64, creator sdk.AccAddress, initMsg []byte, label string, deposit sdk.Coins, callbackSig []byte, salt []byte) (sdk.AccAddress, error) {
+	if err := types.ValidateSalt(salt); err != nil {
+		return nil, err
+	}
+	codeInfo := k.GetCodeInfo(ctx, codeID)
+	if codeInfo == nil {
+		return nil, sdkerrors.Wrap(types.ErrNotFound, "code")
+	}
+	contractAddress := PredictableContractAddress(codeInfo.CodeHash, creator, salt)
+	return k.instantiate(ctx, codeID, creator, initMsg, label, deposit, callbackSig, func() sdk.AccAddress {
+		return contractAddress
+	})
+}
+
+// instantiate creates an instance of a WASM contract at the address returned by newAddress, which is only called
+// once the label is known to be unique
+func (k Keeper) instantiate(ctx sdk.Context, codeID uint64, creator sdk.AccAddress, initMsg []byte, label string, deposit sdk.Coins, callbackSig []byte, newAddress func() sdk.AccAddress) (sdk.AccAddress, error) {
 	defer telemetry.MeasureSince(time.Now(), "compute", "keeper", "instantiate")
 
 	ctx.GasMeter().ConsumeGas(types.InstanceCost, "Loading CosmWasm module: init")
@@ -303,7 +351,7 @@ func (k Keeper) Instantiate(ctx sdk.Context, codeID uint64, creator /* , admin *
 		return nil, sdkerrors.Wrap(types.ErrAccountExists, label)
 	}
 
-	contractAddress := k.generateContractAddress(ctx, codeID)
+	contractAddress := newAddress()
 	existingAcct := k.accountKeeper.GetAccount(ctx, contractAddress)
 	if existingAcct != nil {
 		return nil, sdkerrors.Wrap(types.ErrAccountExists, existingAcct.GetAddress().String())
@@ -340,7 +388,9 @@ func (k Keeper) Instantiate(ctx sdk.Context, codeID uint64, creator /* , admin *
 	// }
 
 	// prepare params for contract instantiate call
+	computeParams := k.GetParams(ctx)
 	params := types.NewEnv(ctx, creator, deposit, contractAddress, nil)
+	params.CanonicalWasmMsgs = computeParams.CanonicalWasmMsgs
 
 	// create prefixed data store
 	// 0x03 | contractAddress (sdk.AccAddress)
@@ -349,7 +399,7 @@ func (k Keeper) Instantiate(ctx sdk.Context, codeID uint64, creator /* , admin *
 
 	// prepare querier
 	querier := QueryHandler{
-		Ctx:     ctx,
+		Ctx:     withMaxQueryStackSize(ctx, computeParams.MaxQueryStackSize),
 		Plugins: k.queryPlugins,
 	}
 
@@ -362,13 +412,16 @@ func (k Keeper) Instantiate(ctx sdk.Context, codeID uint64, creator /* , admin *
 	}
 
 	// emit all events from this contract itself
+	if err := validateEventAttributes(res.Log, computeParams); err != nil {
+		return nil, err
+	}
 	events := types.ParseEvents(res.Log, contractAddress)
 	ctx.EventManager().EmitEvents(events)
 
 	// persist instance
 	createdAt := types.NewAbsoluteTxPosition(ctx)
 	instance := types.NewContractInfo(codeID, creator /* admin, */, label, createdAt)
-	store.Set(types.GetContractAddressKey(contractAddress), k.cdc.MustMarshal(&instance))
+	k.setContractInfo(ctx, contractAddress, &instance)
 
 	// fmt.Printf("Storing key: %v for account %s\n", key, contractAddress)
 
@@ -376,8 +429,8 @@ func (k Keeper) Instantiate(ctx sdk.Context, codeID uint64, creator /* , admin *
 
 	store.Set(types.GetContractLabelPrefix(label), contractAddress)
 
-	err = k.dispatchMessages(ctx, contractAddress, res.Messages)
-	if err != nil {
+	// there is no data to return from instantiate, so the data of replies is dropped
+	if _, err = k.dispatchContractMsgs(ctx, contractAddress, initMsg, res.Messages, res.Submessages, computeParams); err != nil {
 		return nil, err
 	}
 
@@ -428,11 +481,13 @@ func (k Keeper) Execute(ctx sdk.Context, contractAddress sdk.AccAddress, caller
 	}
 
 	contractKey := store.Get(types.GetContractEnclaveKey(contractAddress))
+	computeParams := k.GetParams(ctx)
 	params := types.NewEnv(ctx, caller, coins, contractAddress, contractKey)
+	params.CanonicalWasmMsgs = computeParams.CanonicalWasmMsgs
 
 	// prepare querier
 	querier := QueryHandler{
-		Ctx:     ctx,
+		Ctx:     withMaxQueryStackSize(ctx, computeParams.MaxQueryStackSize),
 		Plugins: k.queryPlugins,
 	}
 
@@ -451,20 +506,89 @@ func (k Keeper) Execute(ctx sdk.Context, contractAddress sdk.AccAddress, caller
 	//}
 
 	// emit all events from this contract itself
+	if err := validateEventAttributes(res.Log, computeParams); err != nil {
+		return nil, err
+	}
 	events := types.ParseEvents(res.Log, contractAddress)
 	ctx.EventManager().EmitEvents(events)
 
 	// TODO: capture events here as well
-	err = k.dispatchMessages(ctx, contractAddress, res.Messages)
+	data, err := k.dispatchContractMsgs(ctx, contractAddress, msg, res.Messages, res.Submessages, computeParams)
 	if err != nil {
 		return nil, err
 	}
+	// data returned by a reply overrides the data of the execution
+	if data == nil {
+		data = res.Data
+	}
 
 	return &sdk.Result{
-		Data: res.Data,
+		Data: data,
 	}, nil
 }
 
+// reply calls the reply export of the contract with the result of a submessage it dispatched. The enclave gets the
+// nonce and public key of ogMsg along with it, to decrypt the data of the submessage and to encrypt the response
+// for the user that sent ogMsg.
+func (k Keeper) reply(ctx sdk.Context, contractAddress sdk.AccAddress, reply wasmTypes.Reply, ogMsg []byte) ([]byte, error) {
+	defer telemetry.MeasureSince(time.Now(), "compute", "keeper", "reply")
+
+	ctx.GasMeter().ConsumeGas(types.InstanceCost, "Loading Compute module: reply")
+
+	// the nonce and the public key are the first 64 bytes of an encrypted message
+	if len(ogMsg) < 64 {
+		return nil, sdkerrors.Wrapf(types.ErrInvalid, "cannot reply to submessage %d of a message that isn't encrypted", reply.ID)
+	}
+	replyBz, err := json.Marshal(reply)
+	if err != nil {
+		return nil, err
+	}
+	msg := append(append([]byte{}, ogMsg[:64]...), replyBz...)
+
+	codeInfo, prefixStore, err := k.contractInstance(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	contractKey := store.Get(types.GetContractEnclaveKey(contractAddress))
+	// the chain calls reply, so the contract is the sender and no funds are sent
+	computeParams := k.GetParams(ctx)
+	params := types.NewEnv(ctx, contractAddress, sdk.NewCoins(), contractAddress, contractKey)
+	params.CanonicalWasmMsgs = computeParams.CanonicalWasmMsgs
+
+	// prepare querier
+	querier := QueryHandler{
+		Ctx:     withMaxQueryStackSize(ctx, computeParams.MaxQueryStackSize),
+		Plugins: k.queryPlugins,
+	}
+
+	gas := gasForContract(ctx)
+	res, gasUsed, execErr := k.wasmer.Reply(codeInfo.CodeHash, params, msg, prefixStore, cosmwasmAPI, querier, gasMeter(ctx), gas)
+	consumeGas(ctx, gasUsed)
+
+	if execErr != nil {
+		// the error is encrypted like one of execute, so it gets the same code
+		return nil, sdkerrors.Wrap(types.ErrExecuteFailed, execErr.Error())
+	}
+
+	// emit all events from this contract itself
+	if err := validateEventAttributes(res.Log, computeParams); err != nil {
+		return nil, err
+	}
+	events := types.ParseEvents(res.Log, contractAddress)
+	ctx.EventManager().EmitEvents(events)
+
+	data, err := k.dispatchContractMsgs(ctx, contractAddress, ogMsg, res.Messages, res.Submessages, computeParams)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = res.Data
+	}
+	return data, nil
+}
+
 /*
 // We don't use this function currently. It's here for upstream compatibility
 // Migrate allows to upgrade a contract to a new code with data migration.
@@ -504,11 +628,13 @@ func (k Keeper) migrate(ctx sdk.Context, contractAddress sdk.AccAddress, caller
 	contractKey := store.Get(types.GetContractEnclaveKey(contractAddress))
 
 	var noDeposit sdk.Coins
+	computeParams := k.GetParams(ctx)
 	params := types.NewEnv(ctx, caller, noDeposit, contractAddress, contractKey)
+	params.CanonicalWasmMsgs = computeParams.CanonicalWasmMsgs
 
 	// prepare querier
 	querier := QueryHandler{
-		Ctx:     ctx,
+		Ctx:     withMaxQueryStackSize(ctx, computeParams.MaxQueryStackSize),
 		Plugins: k.queryPlugins,
 	}
 
@@ -522,6 +648,9 @@ func (k Keeper) migrate(ctx sdk.Context, contractAddress sdk.AccAddress, caller
 	}
 
 	// emit all events from this contract itself
+	if err := validateEventAttributes(res.Log, computeParams); err != nil {
+		return nil, err
+	}
 	events := types.ParseEvents(res.Log, contractAddress)
 	ctx.EventManager().EmitEvents(events)
 
@@ -529,7 +658,7 @@ func (k Keeper) migrate(ctx sdk.Context, contractAddress sdk.AccAddress, caller
 	k.appendToContractHistory(ctx, contractAddress, historyEntry)
 	k.setContractInfo(ctx, contractAddress, contractInfo)
 
-	if err := k.dispatchMessages(ctx, contractAddress, res.Messages); err != nil {
+	if err := k.dispatchMessages(ctx, contractAddress, res.Messages, computeParams); err != nil {
 		return nil, sdkerrors.Wrap(err, "dispatch")
 	}
 
@@ -588,9 +717,45 @@ func (k Keeper) querySmartRecursive(ctx sdk.Context, contractAddr sdk.AccAddress
 	return k.querySmartImpl(ctx, contractAddr, req, useDefaultGasLimit, true)
 }
 
+type contextKeyQueryStackSize struct{}
+
+// checkAndIncreaseQueryStackSize returns a ctx with the query stack one level deeper, or an error if that is deeper
+// than maxQueryStackSize.
+func checkAndIncreaseQueryStackSize(ctx sdk.Context, maxQueryStackSize uint32) (sdk.Context, error) {
+	var queryStackSize uint32
+	if size, ok := ctx.Context().Value(contextKeyQueryStackSize{}).(uint32); ok {
+		queryStackSize = size
+	}
+	queryStackSize++
+	if queryStackSize > maxQueryStackSize {
+		return ctx, sdkerrors.Wrapf(types.ErrMaxQueryStackSize, "%d", maxQueryStackSize)
+	}
+	return ctx.WithContext(context.WithValue(ctx.Context(), contextKeyQueryStackSize{}, queryStackSize)), nil
+}
+
+type contextKeyMaxQueryStackSize struct{}
+
+// withMaxQueryStackSize returns a ctx whose smart queries may be nested maxQueryStackSize deep, so the queries and the
+// ones nested in them don't read the params again
+func withMaxQueryStackSize(ctx sdk.Context, maxQueryStackSize uint32) sdk.Context {
+	return ctx.WithContext(context.WithValue(ctx.Context(), contextKeyMaxQueryStackSize{}, maxQueryStackSize))
+}
+
 func (k Keeper) querySmartImpl(ctx sdk.Context, contractAddr sdk.AccAddress, req []byte, useDefaultGasLimit bool, recursive bool) ([]byte, error) {
 	defer telemetry.MeasureSince(time.Now(), "compute", "keeper", "query")
 
+	// the query plugins pass ctx on to queries of other contracts, so this counts the nesting, with the limit the
+	// outermost query or the contract call that made it read
+	maxQueryStackSize, ok := ctx.Context().Value(contextKeyMaxQueryStackSize{}).(uint32)
+	if !ok {
+		maxQueryStackSize = k.GetParams(ctx).MaxQueryStackSize
+		ctx = withMaxQueryStackSize(ctx, maxQueryStackSize)
+	}
+	ctx, err := checkAndIncreaseQueryStackSize(ctx, maxQueryStackSize)
+	if err != nil {
+		return nil, err
+	}
+
 	if useDefaultGasLimit {
 		ctx = ctx.WithGasMeter(sdk.NewGasMeter(k.queryGasLimit))
 	}
@@ -697,6 +862,39 @@ func (k Keeper) GetContractHash(ctx sdk.Context, contractAddress sdk.AccAddress)
 	return hash
 }
 
+// GetParams returns the current parameters of the module. Parameters that were never set have their default value
+func (k Keeper) GetParams(ctx sdk.Context) types.Params {
+	params := types.DefaultParams()
+	for _, pair := range params.ParamSetPairs() {
+		k.paramSpace.GetIfExists(ctx, pair.Key, pair.Value)
+	}
+	return params
+}
+
+// SetParams sets the parameters of the module
+func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
+	k.paramSpace.SetParamSet(ctx, &params)
+}
+
+// UpdateComputeParams sets the params of msg, if it was sent by the account of the ParamsAuthority param
+func (k Keeper) UpdateComputeParams(ctx sdk.Context, msg *types.MsgUpdateComputeParams) error {
+	params := k.GetParams(ctx)
+	if params.ParamsAuthority == "" {
+		return sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "no params authority is set")
+	}
+	if msg.Authority.String() != params.ParamsAuthority {
+		return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "the params authority is %s, not %s", params.ParamsAuthority, msg.Authority)
+	}
+	params.MaxQueryStackSize = msg.MaxQueryStackSize
+	params.MaxMessagesPerCall = msg.MaxMessagesPerCall
+	params.MsgDispatchCost = msg.MsgDispatchCost
+	if err := params.ValidateBasic(); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	k.SetParams(ctx, params)
+	return nil
+}
+
 func (k Keeper) GetContractInfo(ctx sdk.Context, contractAddress sdk.AccAddress) *types.ContractInfo {
 	store := ctx.KVStore(k.storeKey)
 	var contract types.ContractInfo
@@ -716,6 +914,45 @@ func (k Keeper) containsContractInfo(ctx sdk.Context, contractAddress sdk.AccAdd
 func (k Keeper) setContractInfo(ctx sdk.Context, contractAddress sdk.AccAddress, contract *types.ContractInfo) {
 	store := ctx.KVStore(k.storeKey)
 	store.Set(types.GetContractAddressKey(contractAddress), k.cdc.MustMarshal(contract))
+	store.Set(types.GetContractCodeIndexKey(contract.CodeID, contractAddress), []byte{})
+}
+
+// ContractsByCodeID returns the addresses of all contracts instantiated from the code, ordered by address.
+// Use ContractsByCodeIDPaginated for codes that may have many contracts.
+func (k Keeper) ContractsByCodeID(ctx sdk.Context, codeID uint64) []sdk.AccAddress {
+	prefixStore := prefix.NewStore(ctx.KVStore(k.storeKey), types.GetContractCodeIndexPrefix(codeID))
+	iter := prefixStore.Iterator(nil, nil)
+	defer iter.Close()
+
+	var contracts []sdk.AccAddress
+	for ; iter.Valid(); iter.Next() {
+		contracts = append(contracts, append(sdk.AccAddress(nil), iter.Key()...))
+	}
+	return contracts
+}
+
+// ContractsByCodeIDPaginated is ContractsByCodeID, a page at a time
+func (k Keeper) ContractsByCodeIDPaginated(ctx sdk.Context, codeID uint64, pageReq *query.PageRequest) ([]sdk.AccAddress, *query.PageResponse, error) {
+	prefixStore := prefix.NewStore(ctx.KVStore(k.storeKey), types.GetContractCodeIndexPrefix(codeID))
+	var contracts []sdk.AccAddress
+	pageRes, err := query.Paginate(prefixStore, pageReq, func(key []byte, _ []byte) error {
+		contracts = append(contracts, append(sdk.AccAddress(nil), key...))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return contracts, pageRes, nil
+}
+
+// RebuildContractCodeIndex indexes all contracts by their code ID, for the contracts instantiated before the index
+// existed. It is run once, by the migration of the store to version 2, see Migrator.
+func (k Keeper) RebuildContractCodeIndex(ctx sdk.Context) {
+	store := ctx.KVStore(k.storeKey)
+	k.IterateContractInfo(ctx, func(addr sdk.AccAddress, info types.ContractInfo, _ types.ContractCustomInfo) bool {
+		store.Set(types.GetContractCodeIndexKey(info.CodeID, addr), []byte{})
+		return false
+	})
 }
 
 func (k Keeper) setContractCustomInfo(ctx sdk.Context, contractAddress sdk.AccAddress, contract *types.ContractCustomInfo) {
@@ -808,6 +1045,42 @@ func (k Keeper) GetCodeInfo(ctx sdk.Context, codeID uint64) *types.CodeInfo {
 	return &codeInfo
 }
 
+// SetCodeAllowedMsgVariants restricts the messages contracts of the code may dispatch to the variants, like
+// "bank_send", or whole modules, like "bank". No variants allows all of them.
+func (k Keeper) SetCodeAllowedMsgVariants(ctx sdk.Context, codeID uint64, variants []string) error {
+	store := ctx.KVStore(k.storeKey)
+	if len(variants) == 0 {
+		store.Delete(types.GetCodeMsgVariantsKey(codeID))
+		return nil
+	}
+	for _, variant := range variants {
+		if variant == "" {
+			return sdkerrors.Wrap(types.ErrEmpty, "message variant")
+		}
+	}
+	bz, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	store.Set(types.GetCodeMsgVariantsKey(codeID), bz)
+	return nil
+}
+
+// GetCodeAllowedMsgVariants returns the message variants contracts of the code may dispatch, nil if they may
+// dispatch all of them
+func (k Keeper) GetCodeAllowedMsgVariants(ctx sdk.Context, codeID uint64) []string {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(types.GetCodeMsgVariantsKey(codeID))
+	if bz == nil {
+		return nil
+	}
+	var variants []string
+	if err := json.Unmarshal(bz, &variants); err != nil {
+		panic(err)
+	}
+	return variants
+}
+
 func (k Keeper) containsCodeInfo(ctx sdk.Context, codeID uint64) bool {
 	store := ctx.KVStore(k.storeKey)
 	return store.Has(types.GetCodeKey(codeID))
@@ -837,14 +1110,48 @@ func (k Keeper) GetByteCode(ctx sdk.Context, codeID uint64) ([]byte, error) {
 	return k.wasmer.GetCode(codeInfo.CodeHash)
 }
 
-func (k Keeper) dispatchMessages(ctx sdk.Context, contractAddr sdk.AccAddress, msgs []wasmTypes.CosmosMsg) error {
-	for _, msg := range msgs {
+// validateEventAttributes rejects the attributes a contract call emitted if there are more of them, or they are
+// longer, than the params allow. They are rejected rather than truncated, so no event is silently changed.
+func validateEventAttributes(attrs []wasmTypes.LogAttribute, params types.Params) error {
+	if max := params.MaxEventAttributes; max != 0 && len(attrs) > int(max) {
+		return sdkerrors.Wrapf(types.ErrInvalidEvent, "%d attributes exceed the limit of %d", len(attrs), max)
+	}
+	for _, attr := range attrs {
+		if max := params.MaxEventAttributeKeyLength; max != 0 && len(attr.Key) > int(max) {
+			return sdkerrors.Wrapf(types.ErrInvalidEvent, "attribute key of %d bytes exceeds the limit of %d", len(attr.Key), max)
+		}
+		if max := params.MaxEventAttributeValueLength; max != 0 && len(attr.Value) > int(max) {
+			return sdkerrors.Wrapf(types.ErrInvalidEvent, "attribute value of %d bytes exceeds the limit of %d", len(attr.Value), max)
+		}
+	}
+	return nil
+}
+
+// dispatchContractMsgs dispatches the messages and then the submessages a contract call returned, and returns the
+// data of the last reply that set it. It fails without dispatching anything if there are more of them than the
+// MaxMessagesPerCall param allows, or if the DispatchEnabled param is unset. All of them are dispatched with the
+// params the contract call read, even if one of them changes the params.
+func (k Keeper) dispatchContractMsgs(ctx sdk.Context, contractAddr sdk.AccAddress, ogMsg []byte, msgs []wasmTypes.CosmosMsg, submsgs []wasmTypes.SubMsg, params types.Params) ([]byte, error) {
+	if max := params.MaxMessagesPerCall; max != 0 && len(msgs)+len(submsgs) > int(max) {
+		return nil, sdkerrors.Wrapf(types.ErrTooManyContractMsgs, "%d exceeds the limit of %d", len(msgs)+len(submsgs), max)
+	}
+	if !params.DispatchEnabled && len(msgs)+len(submsgs) > 0 {
+		return nil, sdkerrors.Wrap(types.ErrDispatchPaused, "contract message dispatch is paused by governance")
+	}
+	if err := k.dispatchMessages(ctx, contractAddr, msgs, params); err != nil {
+		return nil, err
+	}
+	return k.dispatchSubmessages(ctx, contractAddr, ogMsg, submsgs, params)
+}
+
+func (k Keeper) dispatchMessages(ctx sdk.Context, contractAddr sdk.AccAddress, msgs []wasmTypes.CosmosMsg, params types.Params) error {
+	for i, msg := range msgs {
 
 		//var events sdk.Events
 		//var data []byte
 		var err error
 
-		if _, _, err = k.Dispatch(ctx, contractAddr, msg); err != nil {
+		if _, _, err = k.dispatch(ctx.WithLogger(ctx.Logger().With("msg_index", i)), contractAddr, msg, params); err != nil {
 			return err
 		}
 	}
@@ -883,6 +1190,16 @@ func contractAddress(codeID, instanceID uint64) sdk.AccAddress {
 
 }
 
+// PredictableContractAddress returns the address of the contract creator instantiates from the code with the
+// hash codeHash and with the given salt. Unlike the addresses of Instantiate, it doesn't depend on how many
+// contracts exist, so it can be computed before the contract is instantiated.
+func PredictableContractAddress(codeHash []byte, creator sdk.AccAddress, salt []byte) sdk.AccAddress {
+	key := append([]byte("instantiate2"), address.MustLengthPrefix(codeHash)...)
+	key = append(key, address.MustLengthPrefix(creator)...)
+	key = append(key, address.MustLengthPrefix(salt)...)
+	return sdk.AccAddress(crypto.AddressHash(key))
+}
+
 func (k Keeper) GetNextCodeID(ctx sdk.Context) uint64 {
 	store := ctx.KVStore(k.storeKey)
 	bz := store.Get(types.KeyLastCodeID)
@@ -1390,3 +1390,19 @@ type InitMsg struct {
 	Verifier    sdk.AccAddress `json:"verifier"`
 	Beneficiary sdk.AccAddress `json:"beneficiary"`
 }
+
+func TestPredictableContractAddress(t *testing.T) {
+	codeHash, _ := hex.DecodeString("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	otherHash, _ := hex.DecodeString("fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210")
+	_, _, creator := keyPubAddr()
+	_, _, otherCreator := keyPubAddr()
+	salt := []byte("salt")
+
+	addr := PredictableContractAddress(codeHash, creator, salt)
+	require.NoError(t, sdk.VerifyAddressFormat(addr))
+	assert.Equal(t, addr, PredictableContractAddress(codeHash, creator, []byte("salt")))
+
+	assert.NotEqual(t, addr, PredictableContractAddress(codeHash, creator, []byte("other salt")))
+	assert.NotEqual(t, addr, PredictableContractAddress(otherHash, creator, salt))
+	assert.NotEqual(t, addr, PredictableContractAddress(codeHash, otherCreator, salt))
+}
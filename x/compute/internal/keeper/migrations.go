@@ -0,0 +1,51 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+// Migrator migrates the store of the compute module to the ConsensusVersion of the module
+type Migrator struct {
+	keeper Keeper
+}
+
+// NewMigrator returns a Migrator for the store of keeper
+func NewMigrator(keeper Keeper) Migrator {
+	return Migrator{keeper: keeper}
+}
+
+// Migrate1to2 indexes the contracts instantiated before version 2 by their code ID, see ContractsByCodeID, and
+// creates the compute module account that contracts burn coins through
+func (m Migrator) Migrate1to2(ctx sdk.Context) error {
+	m.keeper.RebuildContractCodeIndex(ctx)
+	return m.keeper.createModuleAccount(ctx)
+}
+
+// createModuleAccount creates the compute module account, which version 1 didn't have. Anyone could send coins to
+// its address before, which created a BaseAccount there, and the bank module panics on burning from that.
+// Such an account is converted with its account number and balance, since no one has a key for it.
+// Any other account there fails the migration.
+func (k Keeper) createModuleAccount(ctx sdk.Context) error {
+	addr, perms := k.accountKeeper.GetModuleAddressAndPermissions(types.ModuleName)
+	if addr == nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "no module account permissions for %s", types.ModuleName)
+	}
+
+	switch acc := k.accountKeeper.GetAccount(ctx, addr).(type) {
+	case nil:
+		k.accountKeeper.GetModuleAccount(ctx, types.ModuleName)
+	case authtypes.ModuleAccountI:
+	case *authtypes.BaseAccount:
+		if acc.GetPubKey() != nil || acc.GetSequence() != 0 {
+			return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "account %s of the %s module has signed transactions", addr, types.ModuleName)
+		}
+		k.accountKeeper.SetModuleAccount(ctx, authtypes.NewModuleAccount(acc, types.ModuleName, perms...))
+	default:
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidType, "account %s of the %s module is a %T", addr, types.ModuleName, acc)
+	}
+	return nil
+}
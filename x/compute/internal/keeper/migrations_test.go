@@ -0,0 +1,98 @@
+package keeper
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+func TestMigrate1to2(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, creator := keyPubAddr()
+	contracts := map[uint64][]sdk.AccAddress{1: {}, 2: {}}
+	for i := 0; i < 3; i++ {
+		codeID := uint64(i%2 + 1)
+		_, _, contractAddr := keyPubAddr()
+		keeper.setContractInfo(ctx, contractAddr, &types.ContractInfo{CodeID: codeID, Creator: creator, Label: "label"})
+		contracts[codeID] = append(contracts[codeID], contractAddr)
+	}
+	// a store of version 1 has the contracts, but not the index
+	store := ctx.KVStore(keeper.storeKey)
+	for codeID, addrs := range contracts {
+		for _, addr := range addrs {
+			store.Delete(types.GetContractCodeIndexKey(codeID, addr))
+		}
+		require.Empty(t, keeper.ContractsByCodeID(ctx, codeID))
+	}
+
+	require.NoError(t, NewMigrator(keeper).Migrate1to2(ctx))
+	for codeID, addrs := range contracts {
+		assert.ElementsMatch(t, addrs, keeper.ContractsByCodeID(ctx, codeID), "code %d", codeID)
+	}
+	assert.Empty(t, keeper.ContractsByCodeID(ctx, 3))
+
+	// running it again changes nothing
+	require.NoError(t, NewMigrator(keeper).Migrate1to2(ctx))
+	for codeID, addrs := range contracts {
+		assert.ElementsMatch(t, addrs, keeper.ContractsByCodeID(ctx, codeID), "code %d", codeID)
+	}
+}
+
+func TestMigrate1to2ModuleAccount(t *testing.T) {
+	moduleAddr := authtypes.NewModuleAddress(types.ModuleName)
+	coins := sdk.NewCoins(sdk.NewInt64Coin("denom", 100))
+
+	requireModuleAccount := func(t *testing.T, ctx sdk.Context, keepers TestKeepers) authtypes.ModuleAccountI {
+		macc, ok := keepers.AccountKeeper.GetAccount(ctx, moduleAddr).(authtypes.ModuleAccountI)
+		require.True(t, ok)
+		assert.Equal(t, types.ModuleName, macc.GetName())
+		assert.True(t, macc.HasPermission(authtypes.Burner))
+		return macc
+	}
+
+	t.Run("no account", func(t *testing.T) {
+		ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+		require.Nil(t, keepers.AccountKeeper.GetAccount(ctx, moduleAddr))
+
+		require.NoError(t, NewMigrator(keepers.WasmKeeper).Migrate1to2(ctx))
+		requireModuleAccount(t, ctx, keepers)
+
+		// running it again changes nothing
+		require.NoError(t, NewMigrator(keepers.WasmKeeper).Migrate1to2(ctx))
+		requireModuleAccount(t, ctx, keepers)
+	})
+
+	t.Run("funded base account", func(t *testing.T) {
+		ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+		sender, _ := CreateFakeFundedAccount(ctx, keepers.AccountKeeper, keepers.BankKeeper, coins)
+		require.NoError(t, keepers.BankKeeper.SendCoins(ctx, sender, moduleAddr, coins))
+		base, ok := keepers.AccountKeeper.GetAccount(ctx, moduleAddr).(*authtypes.BaseAccount)
+		require.True(t, ok)
+
+		require.NoError(t, NewMigrator(keepers.WasmKeeper).Migrate1to2(ctx))
+		macc := requireModuleAccount(t, ctx, keepers)
+		assert.Equal(t, base.GetAccountNumber(), macc.GetAccountNumber())
+		assert.Equal(t, coins, keepers.BankKeeper.GetAllBalances(ctx, moduleAddr))
+
+		// and burning from it works
+		require.NoError(t, keepers.BankKeeper.BurnCoins(ctx, types.ModuleName, coins))
+		assert.True(t, keepers.BankKeeper.GetAllBalances(ctx, moduleAddr).IsZero())
+	})
+
+	t.Run("base account that signed", func(t *testing.T) {
+		ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+		_, pub, _ := keyPubAddr()
+		base := authtypes.NewBaseAccountWithAddress(moduleAddr)
+		require.NoError(t, base.SetPubKey(pub))
+		keepers.AccountKeeper.SetAccount(ctx, keepers.AccountKeeper.NewAccount(ctx, base))
+
+		require.Error(t, NewMigrator(keepers.WasmKeeper).Migrate1to2(ctx))
+	})
+}
@@ -0,0 +1,165 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+// replyer calls back into the contract that dispatched a submessage with its result. ogMsg is the encrypted
+// message of the contract call that returned the submessage.
+type replyer interface {
+	reply(ctx sdk.Context, contractAddress sdk.AccAddress, reply wasmTypes.Reply, ogMsg []byte) ([]byte, error)
+}
+
+// DispatchSubmessages executes the submessages of a contract in order. Each one runs in its own cached context,
+// so a failing submessage that asked for a reply on error is rolled back without aborting the whole execution.
+// It returns the data of the last reply that set any, which replaces the data of the contract execution.
+func (k Keeper) DispatchSubmessages(ctx sdk.Context, contractAddr sdk.AccAddress, ogMsg []byte, msgs []wasmTypes.SubMsg) ([]byte, error) {
+	return k.dispatchSubmessages(ctx, contractAddr, ogMsg, msgs, k.GetParams(ctx))
+}
+
+// dispatchSubmessages is DispatchSubmessages with the params of the module, which are only read once per execution
+func (k Keeper) dispatchSubmessages(ctx sdk.Context, contractAddr sdk.AccAddress, ogMsg []byte, msgs []wasmTypes.SubMsg, params types.Params) ([]byte, error) {
+	var replyer replyer = k
+	if k.replyer != nil {
+		replyer = k.replyer
+	}
+
+	var rsp []byte
+	for i, msg := range msgs {
+		switch msg.ReplyOn {
+		case wasmTypes.ReplySuccess, wasmTypes.ReplyError, wasmTypes.ReplyAlways, wasmTypes.ReplyNever:
+		default:
+			return nil, sdkerrors.Wrapf(types.ErrInvalidMsg, "unknown reply_on value %d", msg.ReplyOn)
+		}
+
+		subCtx, commit := ctx.CacheContext()
+		em := sdk.NewEventManager()
+		subCtx = subCtx.WithEventManager(em).WithLogger(ctx.Logger().With("submsg_index", i))
+
+		// only limit the gas if the submessage asks for less than what is left
+		gasRemaining := ctx.GasMeter().Limit() - ctx.GasMeter().GasConsumed()
+		limitGas := msg.GasLimit != nil && *msg.GasLimit < gasRemaining
+
+		var data []byte
+		var err error
+		if limitGas {
+			data, err = k.dispatchMsgWithGasLimit(subCtx, contractAddr, msg.Msg, *msg.GasLimit, params)
+		} else {
+			_, data, err = k.dispatch(subCtx, contractAddr, msg.Msg, params)
+		}
+
+		// only persist the state and events of a successful submessage
+		var events sdk.Events
+		if err == nil {
+			commit()
+			events = em.Events()
+			ctx.EventManager().EmitEvents(events)
+		}
+
+		// the contract doesn't want a reply for this result
+		if msg.ReplyOn == wasmTypes.ReplyNever || (err == nil && msg.ReplyOn == wasmTypes.ReplyError) {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// an error it didn't ask to handle aborts the execution
+		if err != nil && msg.ReplyOn == wasmTypes.ReplySuccess {
+			return nil, err
+		}
+
+		var result wasmTypes.SubcallResult
+		if err == nil {
+			result.Ok = &wasmTypes.SubcallResponse{
+				Events: sdkEventsToWasmEvents(events),
+				Data:   data,
+			}
+		} else {
+			result.Err = err.Error()
+		}
+
+		reply := wasmTypes.Reply{
+			ID:     msg.ID,
+			Result: result,
+		}
+		rspData, err := replyer.reply(ctx, contractAddr, reply, ogMsg)
+		if err != nil {
+			return nil, sdkerrors.Wrap(err, "reply")
+		}
+		if rspData != nil {
+			rsp = rspData
+		}
+	}
+	return rsp, nil
+}
+
+// dispatchMsgWithGasLimit dispatches a submessage with its own gas meter, so running out of gas only fails the
+// submessage. All gas it used, up to the limit, is charged to ctx.
+func (k Keeper) dispatchMsgWithGasLimit(ctx sdk.Context, contractAddr sdk.AccAddress, msg wasmTypes.CosmosMsg, gasLimit uint64, params types.Params) (data []byte, err error) {
+	limitedMeter := sdk.NewGasMeter(gasLimit)
+	subCtx := ctx.WithGasMeter(limitedMeter)
+
+	defer func() {
+		if r := recover(); r != nil {
+			// only catch out of gas of the limited meter, everything else is a real panic
+			if _, ok := r.(sdk.ErrorOutOfGas); !ok || !limitedMeter.IsOutOfGas() {
+				panic(r)
+			}
+			ctx.GasMeter().ConsumeGas(gasLimit, "submessage out of gas")
+			err = sdkerrors.Wrap(sdkerrors.ErrOutOfGas, fmt.Sprintf("submessage hit gas limit %d", gasLimit))
+		}
+	}()
+	_, data, err = k.dispatch(subCtx, contractAddr, msg, params)
+
+	ctx.GasMeter().ConsumeGas(limitedMeter.GasConsumed(), "submessage")
+	return data, err
+}
+
+// dispatchExecuteWithGasLimit dispatches an execute that sets a GasLimit with its own gas meter, so the called
+// contract can't use more than that. Unlike for submessages, running out of gas fails the calling contract too.
+func (k Keeper) dispatchExecuteWithGasLimit(ctx sdk.Context, contractAddr sdk.AccAddress, execute wasmTypes.ExecuteMsg, params types.Params) (events sdk.Events, data []byte, err error) {
+	gasLimit := *execute.GasLimit
+	// execute is a copy, so this doesn't modify the message of the contract
+	execute.GasLimit = nil
+	limitedMeter := sdk.NewGasMeter(gasLimit)
+	subCtx := ctx.WithGasMeter(limitedMeter)
+
+	defer func() {
+		if r := recover(); r != nil {
+			// only catch out of gas of the limited meter, everything else is a real panic
+			if _, ok := r.(sdk.ErrorOutOfGas); !ok || !limitedMeter.IsOutOfGas() {
+				panic(r)
+			}
+			ctx.GasMeter().ConsumeGas(gasLimit, "execute out of gas")
+			err = sdkerrors.Wrap(sdkerrors.ErrOutOfGas, fmt.Sprintf("execute hit gas limit %d", gasLimit))
+		}
+	}()
+	events, data, err = k.dispatch(subCtx, contractAddr, wasmTypes.CosmosMsg{Wasm: &wasmTypes.WasmMsg{Execute: &execute}}, params)
+
+	ctx.GasMeter().ConsumeGas(limitedMeter.GasConsumed(), "execute")
+	return events, data, err
+}
+
+func sdkEventsToWasmEvents(events sdk.Events) wasmTypes.Events {
+	res := make(wasmTypes.Events, len(events))
+	for i, ev := range events {
+		attributes := make([]wasmTypes.LogAttribute, len(ev.Attributes))
+		for j, attr := range ev.Attributes {
+			attributes[j] = wasmTypes.LogAttribute{
+				Key:   string(attr.Key),
+				Value: string(attr.Value),
+			}
+		}
+		res[i] = wasmTypes.Event{
+			Type:       ev.Type,
+			Attributes: attributes,
+		}
+	}
+	return res
+}
@@ -0,0 +1,240 @@
+package keeper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+type mockReplyer struct {
+	replies []wasmTypes.Reply
+	ogMsgs  [][]byte
+	data    []byte
+	err     error
+}
+
+func (m *mockReplyer) reply(_ sdk.Context, _ sdk.AccAddress, reply wasmTypes.Reply, ogMsg []byte) ([]byte, error) {
+	m.replies = append(m.replies, reply)
+	m.ogMsgs = append(m.ogMsgs, ogMsg)
+	return m.data, m.err
+}
+
+func TestDispatchSubmessages(t *testing.T) {
+	_, _, contractAddr := keyPubAddr()
+	_, _, otherAddr := keyPubAddr()
+	storeKey := sdk.NewKVStoreKey("test")
+
+	// executing a contract stores a marker, so we can check the state is rolled back on failure.
+	// A contract message of "fail" returns an error and "burn" uses a lot of gas.
+	executeHandler := func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+		execMsg := msg.(*types.MsgExecuteContract)
+		ctx.KVStore(storeKey).Set(execMsg.Msg, []byte{1})
+		ctx.EventManager().EmitEvent(sdk.NewEvent("executed", sdk.NewAttribute("msg", string(execMsg.Msg))))
+		switch string(execMsg.Msg) {
+		case "fail":
+			return nil, sdkerrors.Wrap(types.ErrExecuteFailed, "testing")
+		case "burn":
+			ctx.GasMeter().ConsumeGas(10000, "testing")
+		}
+		return &sdk.Result{Data: []byte("data of " + string(execMsg.Msg))}, nil
+	}
+	router := baseapp.NewRouter()
+	router.AddRoute(sdk.NewRoute(types.RouterKey, executeHandler))
+
+	execute := func(msg string) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Execute: &wasmTypes.ExecuteMsg{
+					ContractAddr: otherAddr.String(),
+					Msg:          []byte(msg),
+				},
+			},
+		}
+	}
+	gasLimit := uint64(5000)
+
+	cases := map[string]struct {
+		msgs      []wasmTypes.SubMsg
+		replyData []byte
+		replyErr  error
+		// set if invalid
+		isError bool
+		// the IDs and results we expect the contract to be called back with
+		expReplies []wasmTypes.Reply
+		expData    []byte
+		// messages whose state must be persisted
+		expStored []string
+	}{
+		"reply always on success": {
+			msgs: []wasmTypes.SubMsg{{ID: 7, Msg: execute("ok"), ReplyOn: wasmTypes.ReplyAlways}},
+			expReplies: []wasmTypes.Reply{{
+				ID: 7,
+				Result: wasmTypes.SubcallResult{Ok: &wasmTypes.SubcallResponse{
+					Events: sdkEventsToWasmEvents(sdk.Events{
+						encodedMsgEvent(contractAddr, execute("ok")),
+						sdk.NewEvent("executed", sdk.NewAttribute("msg", "ok")),
+					}),
+					Data: []byte("data of ok"),
+				}},
+			}},
+			expStored: []string{"ok"},
+		},
+		"reply always on error": {
+			msgs: []wasmTypes.SubMsg{{ID: 8, Msg: execute("fail"), ReplyOn: wasmTypes.ReplyAlways}},
+			expReplies: []wasmTypes.Reply{{
+				ID:     8,
+				Result: wasmTypes.SubcallResult{Err: sdkerrors.Wrap(types.ErrExecuteFailed, "testing").Error()},
+			}},
+		},
+		"reply on error is not called on success": {
+			msgs:      []wasmTypes.SubMsg{{ID: 9, Msg: execute("ok"), ReplyOn: wasmTypes.ReplyError}},
+			expStored: []string{"ok"},
+		},
+		"reply on error on error": {
+			msgs: []wasmTypes.SubMsg{
+				{ID: 16, Msg: execute("first"), ReplyOn: wasmTypes.ReplyNever},
+				{ID: 17, Msg: execute("fail"), ReplyOn: wasmTypes.ReplyError},
+			},
+			expReplies: []wasmTypes.Reply{{
+				ID:     17,
+				Result: wasmTypes.SubcallResult{Err: sdkerrors.Wrap(types.ErrExecuteFailed, "testing").Error()},
+			}},
+			// only the state of the failed submessage is rolled back
+			expStored: []string{"first"},
+		},
+		"reply on success aborts on error": {
+			msgs:    []wasmTypes.SubMsg{{ID: 10, Msg: execute("fail"), ReplyOn: wasmTypes.ReplySuccess}},
+			isError: true,
+		},
+		"reply never aborts on error": {
+			msgs:    []wasmTypes.SubMsg{{ID: 11, Msg: execute("fail"), ReplyOn: wasmTypes.ReplyNever}},
+			isError: true,
+		},
+		"gas limit is enforced": {
+			msgs: []wasmTypes.SubMsg{{ID: 12, Msg: execute("burn"), GasLimit: &gasLimit, ReplyOn: wasmTypes.ReplyError}},
+			expReplies: []wasmTypes.Reply{{
+				ID:     12,
+				Result: wasmTypes.SubcallResult{Err: "submessage hit gas limit 5000: out of gas"},
+			}},
+		},
+		"reply data is returned": {
+			msgs: []wasmTypes.SubMsg{
+				{ID: 13, Msg: execute("first"), ReplyOn: wasmTypes.ReplySuccess},
+				{ID: 14, Msg: execute("second"), ReplyOn: wasmTypes.ReplyNever},
+			},
+			replyData: []byte("reply data"),
+			expReplies: []wasmTypes.Reply{{
+				ID: 13,
+				Result: wasmTypes.SubcallResult{Ok: &wasmTypes.SubcallResponse{
+					Events: sdkEventsToWasmEvents(sdk.Events{
+						encodedMsgEvent(contractAddr, execute("first")),
+						sdk.NewEvent("executed", sdk.NewAttribute("msg", "first")),
+					}),
+					Data: []byte("data of first"),
+				}},
+			}},
+			expData:   []byte("reply data"),
+			expStored: []string{"first", "second"},
+		},
+		"reply error aborts": {
+			msgs:     []wasmTypes.SubMsg{{ID: 15, Msg: execute("ok"), ReplyOn: wasmTypes.ReplyAlways}},
+			replyErr: errors.New("reply failed"),
+			isError:  true,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			ctx := submessageTestContext(t, storeKey)
+			rec := &mockReplyer{data: tc.replyData, err: tc.replyErr}
+			k := Keeper{
+				storeKey:   storeKey,
+				cdc:        MakeEncodingConfig().Marshaler,
+				messenger:  NewMessageHandler(router, nil, nil),
+				replyer:    rec,
+				paramSpace: submessageTestParamSpace(),
+			}
+			// messages are only dispatched to existing contracts
+			contractInfo := types.NewContractInfo(1, contractAddr, "other", nil)
+			k.setContractInfo(ctx, otherAddr, &contractInfo)
+
+			ogMsg := []byte("original message")
+			data, err := k.DispatchSubmessages(ctx, contractAddr, ogMsg, tc.msgs)
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expData, data)
+			assert.Equal(t, tc.expReplies, rec.replies)
+			for _, msg := range rec.ogMsgs {
+				assert.Equal(t, ogMsg, msg)
+			}
+			for _, msg := range []string{"ok", "fail", "burn", "first", "second"} {
+				assert.Equal(t, contains(tc.expStored, msg), ctx.KVStore(storeKey).Has([]byte(msg)), msg)
+			}
+		})
+	}
+}
+
+func TestDispatchSubmessagesReplyToUnencryptedMsg(t *testing.T) {
+	_, _, contractAddr := keyPubAddr()
+	_, _, rcpt := keyPubAddr()
+
+	storeKey := sdk.NewKVStoreKey("test")
+	// without a replyer the reply goes to the enclave, which needs the nonce and public key of the message
+	k := Keeper{storeKey: storeKey, messenger: NewMessageHandler(nil, nil, nil), paramSpace: submessageTestParamSpace()}
+	msg := wasmTypes.SubMsg{
+		ID:      1,
+		Msg:     wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{FromAddress: contractAddr.String(), ToAddress: rcpt.String()}}},
+		ReplyOn: wasmTypes.ReplyAlways,
+	}
+	_, err := k.DispatchSubmessages(submessageTestContext(t, storeKey), contractAddr, []byte("{}"), []wasmTypes.SubMsg{msg})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, types.ErrInvalid))
+}
+
+var (
+	submessageTestParamsKey  = sdk.NewKVStoreKey(paramtypes.StoreKey)
+	submessageTestParamsTKey = sdk.NewTransientStoreKey(paramtypes.TStoreKey)
+)
+
+// submessageTestParamSpace returns a compute param subspace backed by the stores of submessageTestContext
+func submessageTestParamSpace() paramtypes.Subspace {
+	encodingConfig := MakeEncodingConfig()
+	return paramtypes.NewSubspace(encodingConfig.Marshaler, encodingConfig.Amino, submessageTestParamsKey, submessageTestParamsTKey, types.DefaultParamspace).
+		WithKeyTable(types.ParamKeyTable())
+}
+
+func submessageTestContext(t *testing.T, storeKey sdk.StoreKey) sdk.Context {
+	ms := store.NewCommitMultiStore(dbm.NewMemDB())
+	ms.MountStoreWithDB(storeKey, sdk.StoreTypeIAVL, nil)
+	ms.MountStoreWithDB(submessageTestParamsKey, sdk.StoreTypeIAVL, nil)
+	ms.MountStoreWithDB(submessageTestParamsTKey, sdk.StoreTypeTransient, nil)
+	require.NoError(t, ms.LoadLatestVersion())
+	return sdk.NewContext(ms, tmproto.Header{}, false, log.NewNopLogger()).
+		WithGasMeter(sdk.NewGasMeter(1000000))
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
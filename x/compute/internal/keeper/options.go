@@ -0,0 +1,50 @@
+package keeper
+
+// Option is an extension point to instantiate the keeper with non default values
+type Option interface {
+	apply(*Keeper)
+}
+
+type optsFn func(*Keeper)
+
+func (f optsFn) apply(keeper *Keeper) {
+	f(keeper)
+}
+
+// WithCustomEncoder registers an encoder for the custom message variant `name`.
+// Contracts trigger it by emitting `{"custom": {"<name>": <payload>}}`, and the encoder receives the raw payload.
+func WithCustomEncoder(name string, encoder CustomEncoder) Option {
+	return optsFn(func(k *Keeper) {
+		k.messenger.encoders = k.messenger.encoders.Merge(&MessageEncoders{
+			CustomEncoders: map[string]CustomEncoder{name: encoder},
+		})
+	})
+}
+
+// WithCustomMsgHandler registers the handler of all custom messages that no encoder of WithCustomEncoder matches.
+// It receives the whole `custom` JSON value emitted by the contract, byte for byte. Without one, custom messages fail.
+func WithCustomMsgHandler(handler CustomEncoder) Option {
+	return optsFn(func(k *Keeper) {
+		k.messenger.encoders = k.messenger.encoders.Merge(&MessageEncoders{
+			Custom: handler,
+		})
+	})
+}
+
+// WithPortKeeper lets contracts send IBC transfers from any port bound on the chain, not only the one of the
+// transfer module.
+func WithPortKeeper(portKeeper PortKeeper) Option {
+	return optsFn(func(k *Keeper) {
+		k.portKeeper = portKeeper
+	})
+}
+
+// WithCustomQuerier registers a querier for the custom query variant `name`.
+// Contracts trigger it by querying `{"custom": {"<name>": <payload>}}`, and the querier receives the raw payload.
+func WithCustomQuerier(name string, querier CustomQuerier) Option {
+	return optsFn(func(k *Keeper) {
+		k.queryPlugins = k.queryPlugins.Merge(&QueryPlugins{
+			CustomQueriers: map[string]CustomQuerier{name: querier},
+		})
+	})
+}
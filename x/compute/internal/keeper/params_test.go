@@ -0,0 +1,488 @@
+package keeper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	paramproposal "github.com/cosmos/cosmos-sdk/x/params/types/proposal"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+func TestParamChangeProposal(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper, govKeeper := keepers.WasmKeeper, keepers.GovKeeper
+	require.Equal(t, types.DefaultParams(), keeper.GetParams(ctx))
+
+	proposal := paramproposal.NewParameterChangeProposal("compute params", "description", []paramproposal.ParamChange{
+		{Subspace: types.DefaultParamspace, Key: string(types.ParamStoreKeyMaxQueryStackSize), Value: `3`},
+		{Subspace: types.DefaultParamspace, Key: string(types.ParamStoreKeyMaxMessagesPerCall), Value: `7`},
+		{Subspace: types.DefaultParamspace, Key: string(types.ParamStoreKeyMsgDispatchCost), Value: `"500"`},
+	})
+
+	// anyone can submit a proposal, but it changes nothing until governance passes it
+	_, err := govKeeper.SubmitProposal(ctx, proposal)
+	require.NoError(t, err)
+	assert.Equal(t, types.DefaultParams(), keeper.GetParams(ctx))
+
+	// a passed proposal is executed by the handler of its route
+	handler := govKeeper.Router().GetRoute(proposal.ProposalRoute())
+	require.NoError(t, handler(ctx, proposal))
+	params := keeper.GetParams(ctx)
+	assert.Equal(t, uint32(3), params.MaxQueryStackSize)
+	assert.Equal(t, uint32(7), params.MaxMessagesPerCall)
+	assert.Equal(t, uint64(500), params.MsgDispatchCost)
+
+	// invalid values are rejected
+	invalid := paramproposal.NewParameterChangeProposal("compute params", "description", []paramproposal.ParamChange{
+		{Subspace: types.DefaultParamspace, Key: string(types.ParamStoreKeyMaxQueryStackSize), Value: `0`},
+	})
+	require.Error(t, handler(ctx, invalid))
+	assert.Equal(t, params, keeper.GetParams(ctx))
+}
+
+func TestDispatchChargesMsgDispatchCost(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+	_, _, contractAddr := keyPubAddr()
+	// the message fails to encode, which happens after the dispatch cost is charged
+	msg := wasmTypes.CosmosMsg{}
+
+	dispatchGas := func(cost uint64) uint64 {
+		params := keeper.GetParams(ctx)
+		params.MsgDispatchCost = cost
+		keeper.SetParams(ctx, params)
+
+		ctx := ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		_, _, err := keeper.Dispatch(ctx, contractAddr, msg)
+		require.Error(t, err)
+		return ctx.GasMeter().GasConsumed()
+	}
+	// reading the params costs gas by their size, so both costs have the same number of digits
+	assert.Equal(t, dispatchGas(100)+400, dispatchGas(500))
+}
+
+func TestDispatchMaxMessagesPerCall(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, rcpt := keyPubAddr()
+	send := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: contractAddr.String(),
+				ToAddress:   rcpt.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+			},
+		},
+	}
+	msgs := []wasmTypes.CosmosMsg{send, send}
+	submsgs := []wasmTypes.SubMsg{{ID: 1, Msg: send, ReplyOn: wasmTypes.ReplyNever}}
+
+	params := keeper.GetParams(ctx)
+	params.MaxMessagesPerCall = 2
+	keeper.SetParams(ctx, params)
+
+	// messages and submessages count towards the same limit, and nothing is dispatched when it's exceeded
+	_, err := keeper.dispatchContractMsgs(ctx, contractAddr, nil, msgs, submsgs, params)
+	assert.True(t, types.ErrTooManyContractMsgs.Is(err), err)
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	_, err = keeper.dispatchContractMsgs(ctx, contractAddr, nil, msgs, nil, params)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 200)), bankKeeper.GetAllBalances(ctx, rcpt))
+
+	// there is no limit when it's 0
+	params.MaxMessagesPerCall = 0
+	keeper.SetParams(ctx, params)
+	_, err = keeper.dispatchContractMsgs(ctx, contractAddr, nil, msgs, submsgs, params)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 500)), bankKeeper.GetAllBalances(ctx, rcpt))
+}
+
+func TestDispatchEnabled(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, rcpt := keyPubAddr()
+	send := wasmTypes.CosmosMsg{
+		Bank: &wasmTypes.BankMsg{
+			Send: &wasmTypes.SendMsg{
+				FromAddress: contractAddr.String(),
+				ToAddress:   rcpt.String(),
+				Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+			},
+		},
+	}
+	msgs := []wasmTypes.CosmosMsg{send}
+	submsgs := []wasmTypes.SubMsg{{ID: 1, Msg: send, ReplyOn: wasmTypes.ReplyNever}}
+	require.True(t, keeper.GetParams(ctx).DispatchEnabled)
+
+	params := keeper.GetParams(ctx)
+	params.DispatchEnabled = false
+	keeper.SetParams(ctx, params)
+
+	_, err := keeper.dispatchContractMsgs(ctx, contractAddr, nil, msgs, submsgs, params)
+	assert.True(t, types.ErrDispatchPaused.Is(err), err)
+	_, _, err = keeper.Dispatch(ctx, contractAddr, send)
+	assert.True(t, types.ErrDispatchPaused.Is(err), err)
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+	// a call that returns no messages isn't affected
+	_, err = keeper.dispatchContractMsgs(ctx, contractAddr, nil, nil, nil, params)
+	require.NoError(t, err)
+
+	params.DispatchEnabled = true
+	keeper.SetParams(ctx, params)
+	_, err = keeper.dispatchContractMsgs(ctx, contractAddr, nil, msgs, submsgs, params)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 200)), bankKeeper.GetAllBalances(ctx, rcpt))
+}
+
+func TestDispatchMinInstantiateFunds(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	instantiate := func(send wasmTypes.Coins) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Instantiate: &wasmTypes.InstantiateMsg{
+					CodeID:           1,
+					CallbackCodeHash: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+					Msg:              []byte("{}"),
+					Label:            "child",
+					Send:             send,
+				},
+			},
+		}
+	}
+
+	// no minimum by default
+	require.Empty(t, keeper.GetParams(ctx).MinInstantiateFunds)
+	require.NoError(t, validateInstantiateFunds(instantiate(nil), keeper.GetParams(ctx)))
+
+	params := keeper.GetParams(ctx)
+	params.MinInstantiateFunds = sdk.NewCoins(sdk.NewInt64Coin("denom", 100))
+	keeper.SetParams(ctx, params)
+
+	// at the threshold
+	require.NoError(t, validateInstantiateFunds(instantiate(wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")}), keeper.GetParams(ctx)))
+	require.NoError(t, validateInstantiateFunds(instantiate(wasmTypes.Coins{wasmTypes.NewCoin(100, "denom"), wasmTypes.NewCoin(1, "other")}), keeper.GetParams(ctx)))
+
+	// below it
+	for _, send := range []wasmTypes.Coins{nil, {wasmTypes.NewCoin(99, "denom")}, {wasmTypes.NewCoin(100, "other")}} {
+		_, _, err := keeper.Dispatch(ctx, contractAddr, instantiate(send))
+		assert.True(t, sdkerrors.ErrInsufficientFunds.Is(err), err)
+	}
+	assert.Equal(t, deposit, bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	// other messages aren't affected
+	require.NoError(t, validateInstantiateFunds(wasmTypes.CosmosMsg{Bank: &wasmTypes.BankMsg{Send: &wasmTypes.SendMsg{}}}, keeper.GetParams(ctx)))
+
+	params.MinInstantiateFunds = sdk.Coins{sdk.Coin{Denom: "denom", Amount: sdk.NewInt(-1)}}
+	require.Error(t, params.ValidateBasic())
+}
+
+func TestUpdateComputeParams(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper, govKeeper := keepers.WasmKeeper, keepers.GovKeeper
+	require.Equal(t, types.DefaultParams(), keeper.GetParams(ctx))
+
+	_, _, authority := keyPubAddr()
+	_, _, other := keyPubAddr()
+	update := func(authority sdk.AccAddress, maxQueryStackSize uint32) *types.MsgUpdateComputeParams {
+		return &types.MsgUpdateComputeParams{
+			Authority:          authority,
+			MaxQueryStackSize:  maxQueryStackSize,
+			MaxMessagesPerCall: 7,
+			MsgDispatchCost:    500,
+		}
+	}
+
+	// there's no authority by default, not even the gov module, which can't send messages
+	err := keeper.UpdateComputeParams(ctx, update(authtypes.NewModuleAddress(govtypes.ModuleName), 3))
+	assert.True(t, sdkerrors.ErrUnauthorized.Is(err), err)
+	assert.Equal(t, types.DefaultParams(), keeper.GetParams(ctx))
+
+	// governance sets the authority with a parameter change proposal
+	proposal := paramproposal.NewParameterChangeProposal("compute params authority", "description", []paramproposal.ParamChange{
+		{Subspace: types.DefaultParamspace, Key: string(types.ParamStoreKeyParamsAuthority), Value: `"` + authority.String() + `"`},
+	})
+	handler := govKeeper.Router().GetRoute(proposal.ProposalRoute())
+	require.NoError(t, handler(ctx, proposal))
+	require.Equal(t, authority.String(), keeper.GetParams(ctx).ParamsAuthority)
+
+	require.NoError(t, keeper.UpdateComputeParams(ctx, update(authority, 3)))
+	params := keeper.GetParams(ctx)
+	assert.Equal(t, uint32(3), params.MaxQueryStackSize)
+	assert.Equal(t, uint32(7), params.MaxMessagesPerCall)
+	assert.Equal(t, uint64(500), params.MsgDispatchCost)
+	// the params the message doesn't have are kept, including the authority
+	assert.True(t, params.DispatchEnabled)
+	assert.Equal(t, authority.String(), params.ParamsAuthority)
+
+	// anyone else is rejected
+	err = keeper.UpdateComputeParams(ctx, update(other, 4))
+	assert.True(t, sdkerrors.ErrUnauthorized.Is(err), err)
+	assert.Equal(t, params, keeper.GetParams(ctx))
+
+	// invalid values are rejected
+	err = keeper.UpdateComputeParams(ctx, update(authority, 0))
+	assert.True(t, sdkerrors.ErrInvalidRequest.Is(err), err)
+	assert.Equal(t, params, keeper.GetParams(ctx))
+
+	// and so is an authority that isn't an address
+	invalid := paramproposal.NewParameterChangeProposal("compute params authority", "description", []paramproposal.ParamChange{
+		{Subspace: types.DefaultParamspace, Key: string(types.ParamStoreKeyParamsAuthority), Value: `"banana"`},
+	})
+	require.Error(t, handler(ctx, invalid))
+	assert.Equal(t, params, keeper.GetParams(ctx))
+}
+
+func TestGenesisExportImportParams(t *testing.T) {
+	srcCtx, srcKeepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	srcKeeper := srcKeepers.WasmKeeper
+
+	_, _, authority := keyPubAddr()
+	params := types.DefaultParams()
+	params.RejectSelfSends = true
+	params.MaxQueryStackSize = 3
+	params.MsgDispatchCost = 500
+	params.SendDenomAllowlist = []string{"denom", "uscrt"}
+	params.DispatchEnabled = false
+	params.MinInstantiateFunds = sdk.NewCoins(sdk.NewInt64Coin("denom", 100))
+	params.CanonicalWasmMsgs = true
+	params.ParamsAuthority = authority.String()
+	srcKeeper.SetParams(srcCtx, params)
+
+	cdc := MakeEncodingConfig().Marshaler
+	exported, err := cdc.MarshalJSON(ExportGenesis(srcCtx, srcKeeper))
+	require.NoError(t, err)
+
+	var imported types.GenesisState
+	require.NoError(t, cdc.UnmarshalJSON(exported, &imported))
+	require.NoError(t, imported.ValidateBasic())
+	assert.Equal(t, params, imported.Params)
+
+	dstCtx, dstKeepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	dstKeeper := dstKeepers.WasmKeeper
+	require.NoError(t, InitGenesis(dstCtx, dstKeeper, imported))
+	assert.Equal(t, params, dstKeeper.GetParams(dstCtx))
+
+	// a genesis state from before the params were part of it fails instead of pausing dispatch
+	var old types.GenesisState
+	require.NoError(t, cdc.UnmarshalJSON([]byte(`{"codes":[],"contracts":[],"sequences":[]}`), &old))
+	assert.Error(t, old.ValidateBasic())
+}
+
+func TestDispatchMaxDispatchesPerBlock(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	otherAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, rcpt := keyPubAddr()
+	send := func(from sdk.AccAddress) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress: from.String(),
+					ToAddress:   rcpt.String(),
+					Amount:      wasmTypes.Coins{wasmTypes.NewCoin(100, "denom")},
+				},
+			},
+		}
+	}
+	require.Zero(t, keeper.GetParams(ctx).MaxDispatchesPerBlock)
+
+	params := keeper.GetParams(ctx)
+	params.MaxDispatchesPerBlock = 2
+	keeper.SetParams(ctx, params)
+
+	for i := 0; i < 2; i++ {
+		_, _, err := keeper.Dispatch(ctx, contractAddr, send(contractAddr))
+		require.NoError(t, err)
+	}
+	_, _, err := keeper.Dispatch(ctx, contractAddr, send(contractAddr))
+	assert.True(t, types.ErrTooManyContractMsgs.Is(err), err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 800)), bankKeeper.GetAllBalances(ctx, contractAddr))
+
+	// the limit is per contract
+	_, _, err = keeper.Dispatch(ctx, otherAddr, send(otherAddr))
+	require.NoError(t, err)
+
+	// raising the limit lets the contract go on in the same block
+	params.MaxDispatchesPerBlock = 3
+	keeper.SetParams(ctx, params)
+	_, _, err = keeper.Dispatch(ctx, contractAddr, send(contractAddr))
+	require.NoError(t, err)
+}
+
+func TestDispatchMaxWasmMsgSize(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+	_, _, contractAddr := keyPubAddr()
+	_, _, target := keyPubAddr()
+	// the msgs contracts send are encrypted by the enclave, the limit is on the plaintext
+	encrypted := func(size int) []byte {
+		return bytes.Repeat([]byte("a"), wasmMsgEnvelopeSize+size)
+	}
+	execute := func(size int) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Wasm: &wasmTypes.WasmMsg{
+				Execute: &wasmTypes.ExecuteMsg{
+					ContractAddr: target.String(),
+					Msg:          encrypted(size),
+				},
+			},
+		}
+	}
+	instantiate := wasmTypes.CosmosMsg{
+		Wasm: &wasmTypes.WasmMsg{
+			Instantiate: &wasmTypes.InstantiateMsg{CodeID: 1, Msg: encrypted(101), Label: "label"},
+		},
+	}
+
+	// no limit by default
+	require.NoError(t, validateWasmMsgSize(execute(1<<20), keeper.GetParams(ctx)))
+
+	params := keeper.GetParams(ctx)
+	params.MaxWasmMsgSize = 100
+	keeper.SetParams(ctx, params)
+
+	require.NoError(t, validateWasmMsgSize(execute(100), keeper.GetParams(ctx)))
+	require.NoError(t, validateWasmMsgSize(execute(-wasmMsgEnvelopeSize), keeper.GetParams(ctx)))
+	err := validateWasmMsgSize(execute(101), keeper.GetParams(ctx))
+	assert.True(t, types.ErrLimit.Is(err), err)
+	err = validateWasmMsgSize(instantiate, keeper.GetParams(ctx))
+	assert.True(t, types.ErrLimit.Is(err), err)
+
+	// dispatch rejects it before anything else
+	_, _, err = keeper.Dispatch(ctx, contractAddr, execute(101))
+	assert.True(t, types.ErrLimit.Is(err), err)
+}
+
+func TestValidateEventAttributes(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+	attrs := []wasmTypes.LogAttribute{{Key: "action", Value: "transfer"}, {Key: "amount", Value: "100"}}
+	bigValue := []wasmTypes.LogAttribute{{Key: "action", Value: strings.Repeat("a", 2000)}}
+
+	// there are no limits by default
+	require.NoError(t, validateEventAttributes(bigValue, keeper.GetParams(ctx)))
+
+	params := keeper.GetParams(ctx)
+	params.MaxEventAttributes = 2
+	params.MaxEventAttributeKeyLength = 6
+	params.MaxEventAttributeValueLength = 1000
+	keeper.SetParams(ctx, params)
+
+	require.NoError(t, validateEventAttributes(attrs, keeper.GetParams(ctx)))
+	require.NoError(t, validateEventAttributes(nil, keeper.GetParams(ctx)))
+
+	for name, attrs := range map[string][]wasmTypes.LogAttribute{
+		"value too long":  bigValue,
+		"key too long":    {{Key: "actions", Value: "transfer"}},
+		"too many":        append(attrs, wasmTypes.LogAttribute{Key: "to", Value: "me"}),
+		"one of them bad": append([]wasmTypes.LogAttribute{{Key: "ok", Value: "ok"}}, bigValue...),
+	} {
+		err := validateEventAttributes(attrs, keeper.GetParams(ctx))
+		assert.True(t, types.ErrInvalidEvent.Is(err), "%s: %v", name, err)
+	}
+}
+
+func TestDispatchSendDenomAllowlist(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	deposit := sdk.NewCoins(sdk.NewInt64Coin("denom", 1000), sdk.NewInt64Coin("other", 1000))
+	contractAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, deposit)
+	_, _, rcpt := keyPubAddr()
+	send := func(coins ...wasmTypes.Coin) wasmTypes.CosmosMsg {
+		return wasmTypes.CosmosMsg{
+			Bank: &wasmTypes.BankMsg{
+				Send: &wasmTypes.SendMsg{
+					FromAddress: contractAddr.String(),
+					ToAddress:   rcpt.String(),
+					Amount:      coins,
+				},
+			},
+		}
+	}
+
+	// an empty allowlist allows all denoms
+	_, _, err := keeper.Dispatch(ctx, contractAddr, send(wasmTypes.NewCoin(100, "other")))
+	require.NoError(t, err)
+
+	params := keeper.GetParams(ctx)
+	params.SendDenomAllowlist = []string{"denom"}
+	keeper.SetParams(ctx, params)
+
+	_, _, err = keeper.Dispatch(ctx, contractAddr, send(wasmTypes.NewCoin(100, "denom")))
+	require.NoError(t, err)
+
+	for name, msg := range map[string]wasmTypes.CosmosMsg{
+		"disallowed":      send(wasmTypes.NewCoin(100, "other")),
+		"one of them bad": send(wasmTypes.NewCoin(100, "denom"), wasmTypes.NewCoin(100, "other")),
+		"multi send": {
+			Bank: &wasmTypes.BankMsg{
+				MultiSend: &wasmTypes.MultiSendMsg{
+					Inputs:  []wasmTypes.BankInput{{Address: contractAddr.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "other")}}},
+					Outputs: []wasmTypes.BankOutput{{Address: rcpt.String(), Coins: wasmTypes.Coins{wasmTypes.NewCoin(100, "other")}}},
+				},
+			},
+		},
+	} {
+		_, _, err = keeper.Dispatch(ctx, contractAddr, msg)
+		assert.True(t, sdkerrors.ErrUnauthorized.Is(err), "%s: %v", name, err)
+	}
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("denom", 100), sdk.NewInt64Coin("other", 100)), bankKeeper.GetAllBalances(ctx, rcpt))
+
+	// the allowlist applies to funds sent with any message, not only with bank sends
+	other := wasmTypes.Coins{wasmTypes.NewCoin(100, "other")}
+	stargateSend, err := (&banktypes.MsgSend{
+		FromAddress: contractAddr.String(),
+		ToAddress:   rcpt.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("other", 100)),
+	}).Marshal()
+	require.NoError(t, err)
+	for name, msg := range map[string]wasmTypes.CosmosMsg{
+		"stargate send": {Stargate: &wasmTypes.StargateMsg{TypeURL: "/cosmos.bank.v1beta1.MsgSend", Value: stargateSend}},
+		"authz send":    {Authz: &wasmTypes.AuthzMsg{Exec: &wasmTypes.AuthzExecMsg{Granter: rcpt.String(), Msgs: []wasmTypes.CosmosMsg{send(other...)}}}},
+		"execute":       {Wasm: &wasmTypes.WasmMsg{Execute: &wasmTypes.ExecuteMsg{ContractAddr: rcpt.String(), Msg: []byte(`{}`), Send: other}}},
+		"instantiate":   {Wasm: &wasmTypes.WasmMsg{Instantiate: &wasmTypes.InstantiateMsg{CodeID: 1, Msg: []byte(`{}`), Label: "label", Send: other}}},
+		"instantiate2": {Wasm: &wasmTypes.WasmMsg{Instantiate2: &wasmTypes.Instantiate2Msg{
+			CodeID: 1, Msg: []byte(`{}`), Label: "label", Send: other, CallbackSignature: []byte("signature"), Salt: []byte("salt"),
+		}}},
+		"ibc transfer": {IBC: &wasmTypes.IBCMsg{Transfer: &wasmTypes.TransferMsg{
+			ChannelID: "channel-0", ToAddress: rcpt.String(), Amount: other[0], Timeout: wasmTypes.IBCTimeout{Timestamp: 1},
+		}}},
+		"vesting":        {Vesting: &wasmTypes.VestingMsg{CreateVestingAccount: &wasmTypes.CreateVestingAccountMsg{To: rcpt.String(), Amount: other, EndTime: ctx.BlockTime().Unix() + 100}}},
+		"community pool": {Distribution: &wasmTypes.DistributionMsg{FundCommunityPool: &wasmTypes.FundCommunityPoolMsg{Amount: other}}},
+	} {
+		_, _, err = keeper.Dispatch(ctx, contractAddr, msg)
+		assert.ErrorContains(t, err, "contracts may not send other", name)
+	}
+
+	// the allowlist must hold valid, distinct denoms
+	params.SendDenomAllowlist = []string{"denom", "denom"}
+	require.Error(t, params.ValidateBasic())
+	params.SendDenomAllowlist = []string{"1nvalid"}
+	require.Error(t, params.ValidateBasic())
+}
@@ -1,9 +1,11 @@
 package keeper
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
 	"strings"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
@@ -16,6 +18,7 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
 	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -38,12 +41,21 @@ func (q QueryHandler) Query(request wasmTypes.QueryRequest, gasLimit uint64) ([]
 		q.Ctx.GasMeter().ConsumeGas(subctx.GasMeter().GasConsumed(), "contract sub-query")
 	}()
 
-	// do the query
+	res, err := q.query(subctx, request)
+	if err != nil {
+		return nil, err
+	}
+	// large results are charged for, so contracts can't cheaply read unbounded lists
+	subctx.GasMeter().ConsumeGas(uint64(len(res))*types.QueryResultByteCost, "contract sub-query result")
+	return res, nil
+}
+
+func (q QueryHandler) query(subctx sdk.Context, request wasmTypes.QueryRequest) ([]byte, error) {
 	if request.Bank != nil {
 		return q.Plugins.Bank(subctx, request.Bank)
 	}
 	if request.Custom != nil {
-		return q.Plugins.Custom(subctx, request.Custom)
+		return q.Plugins.queryCustom(subctx, request.Custom)
 	}
 	if request.Staking != nil {
 		return q.Plugins.Staking(subctx, request.Staking)
@@ -52,13 +64,16 @@ func (q QueryHandler) Query(request wasmTypes.QueryRequest, gasLimit uint64) ([]
 		return q.Plugins.Wasm(subctx, request.Wasm)
 	}
 	if request.Dist != nil {
-		return q.Plugins.Dist(q.Ctx, request.Dist)
+		return q.Plugins.Dist(subctx, request.Dist)
 	}
 	if request.Mint != nil {
-		return q.Plugins.Mint(q.Ctx, request.Mint)
+		return q.Plugins.Mint(subctx, request.Mint)
 	}
 	if request.Gov != nil {
-		return q.Plugins.Gov(q.Ctx, request.Gov)
+		return q.Plugins.Gov(subctx, request.Gov)
+	}
+	if request.Env != nil {
+		return q.Plugins.Env(subctx, request.Env)
 	}
 	return nil, wasmTypes.Unknown{}
 }
@@ -77,6 +92,10 @@ type QueryPlugins struct {
 	Dist    func(ctx sdk.Context, request *wasmTypes.DistQuery) ([]byte, error)
 	Mint    func(ctx sdk.Context, request *wasmTypes.MintQuery) ([]byte, error)
 	Gov     func(ctx sdk.Context, request *wasmTypes.GovQuery) ([]byte, error)
+	Env     func(ctx sdk.Context, request *wasmTypes.EnvQuery) ([]byte, error)
+	// CustomQueriers are looked up by the name of the custom variant (the single top-level key
+	// of the custom JSON object). If no named querier matches, the query is handed to Custom.
+	CustomQueriers map[string]CustomQuerier
 }
 
 func DefaultQueryPlugins(gov govkeeper.Keeper, dist distrkeeper.Keeper, mint mintkeeper.Keeper, bank bankkeeper.Keeper, staking stakingkeeper.Keeper, wasm *Keeper) QueryPlugins {
@@ -88,6 +107,7 @@ func DefaultQueryPlugins(gov govkeeper.Keeper, dist distrkeeper.Keeper, mint min
 		Dist:    DistQuerier(dist),
 		Mint:    MintQuerier(mint),
 		Gov:     GovQuerier(gov),
+		Env:     EnvQuerier,
 	}
 }
 
@@ -117,9 +137,43 @@ func (e QueryPlugins) Merge(o *QueryPlugins) QueryPlugins {
 	if o.Gov != nil {
 		e.Gov = o.Gov
 	}
+	if o.Env != nil {
+		e.Env = o.Env
+	}
+	if len(o.CustomQueriers) != 0 {
+		// copy so we never mutate a map shared with another QueryPlugins
+		merged := make(map[string]CustomQuerier, len(e.CustomQueriers)+len(o.CustomQueriers))
+		for name, querier := range e.CustomQueriers {
+			merged[name] = querier
+		}
+		for name, querier := range o.CustomQueriers {
+			merged[name] = querier
+		}
+		e.CustomQueriers = merged
+	}
 	return e
 }
 
+// queryCustom dispatches a custom query of the form `{"<variant>": <payload>}` to the querier registered
+// for that variant, passing it the raw payload. Anything else falls through to the generic Custom querier.
+func (e QueryPlugins) queryCustom(ctx sdk.Context, request json.RawMessage) ([]byte, error) {
+	if len(e.CustomQueriers) != 0 {
+		var variants map[string]json.RawMessage
+		if err := json.Unmarshal(request, &variants); err == nil && len(variants) == 1 {
+			for name, payload := range variants {
+				if querier, ok := e.CustomQueriers[name]; ok {
+					return querier(ctx, payload)
+				}
+			}
+		}
+	}
+	return e.Custom(ctx, request)
+}
+
+func EnvQuerier(ctx sdk.Context, _ *wasmTypes.EnvQuery) ([]byte, error) {
+	return json.Marshal(types.NewBlockInfo(ctx))
+}
+
 func GovQuerier(keeper govkeeper.Keeper) func(ctx sdk.Context, request *wasmTypes.GovQuery) ([]byte, error) {
 	return func(ctx sdk.Context, request *wasmTypes.GovQuery) ([]byte, error) {
 		if request.Proposals != nil {
@@ -227,21 +281,89 @@ func DistQuerier(keeper distrkeeper.Keeper) func(ctx sdk.Context, request *wasmT
 
 			return ret, nil
 		}
+		if request.Params != nil {
+			params := keeper.GetParams(ctx)
+			res := wasmTypes.DistParamsResponse{
+				CommunityTax:        params.CommunityTax.String(),
+				BaseProposerReward:  params.BaseProposerReward.String(),
+				BonusProposerReward: params.BonusProposerReward.String(),
+				WithdrawAddrEnabled: params.WithdrawAddrEnabled,
+			}
+			return json.Marshal(res)
+		}
+		if request.WithdrawAddress != nil {
+			addr, err := sdk.AccAddressFromBech32(request.WithdrawAddress.Delegator)
+			if err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.WithdrawAddress.Delegator)
+			}
+
+			res := wasmTypes.WithdrawAddressResponse{
+				WithdrawAddress: keeper.GetDelegatorWithdrawAddr(ctx, addr).String(),
+			}
+			return json.Marshal(res)
+		}
+		if request.DelegationRewards != nil {
+			if _, err := sdk.AccAddressFromBech32(request.DelegationRewards.Delegator); err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.DelegationRewards.Delegator)
+			}
+			if _, err := sdk.ValAddressFromBech32(request.DelegationRewards.Validator); err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.DelegationRewards.Validator)
+			}
+
+			// calculating the rewards ends the current period of the validator, which must not be persisted by a query
+			cacheCtx, _ := ctx.CacheContext()
+			rewards, err := keeper.DelegationRewards(sdk.WrapSDKContext(cacheCtx), &distrtypes.QueryDelegationRewardsRequest{
+				DelegatorAddress: request.DelegationRewards.Delegator,
+				ValidatorAddress: request.DelegationRewards.Validator,
+			})
+			var res wasmTypes.DelegationRewardsResponse
+			switch {
+			case distrtypes.ErrNoDelegationExists.Is(err):
+			case err != nil:
+				return nil, err
+			default:
+				// like in the Rewards query, fractions of a coin are dropped
+				coins, _ := rewards.Rewards.TruncateDecimal()
+				res.Rewards = types.NewWasmCoins(coins)
+			}
+			return json.Marshal(res)
+		}
 		return nil, wasmTypes.UnsupportedRequest{Kind: "unknown DistQuery variant"}
 	}
 }
 
-func BankQuerier(bankKeeper bankkeeper.ViewKeeper) func(ctx sdk.Context, request *wasmTypes.BankQuery) ([]byte, error) {
+// maxQueriedBalances is the most coins returned to a contract by a single AllBalances query
+const maxQueriedBalances = 100
+
+// maxQueriedSendEnabledDenoms is the most denoms a contract can query with a single SendEnabled query
+const maxQueriedSendEnabledDenoms = 100
+
+func BankQuerier(bankKeeper bankkeeper.Keeper) func(ctx sdk.Context, request *wasmTypes.BankQuery) ([]byte, error) {
 	return func(ctx sdk.Context, request *wasmTypes.BankQuery) ([]byte, error) {
 		if request.AllBalances != nil {
 			addr, err := sdk.AccAddressFromBech32(request.AllBalances.Address)
 			if err != nil {
 				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.AllBalances.Address)
 			}
-			coins := bankKeeper.GetAllBalances(ctx, addr)
-			res := wasmTypes.AllBalancesResponse{
-				Amount: convertSdkCoinsToWasmCoins(coins),
+			limit := request.AllBalances.Limit
+			if limit == 0 || limit > maxQueriedBalances {
+				limit = maxQueriedBalances
 			}
+			// balances are iterated in denom order
+			var res wasmTypes.AllBalancesResponse
+			coins := sdk.Coins{}
+			bankKeeper.IterateAccountBalances(ctx, addr, func(coin sdk.Coin) bool {
+				if coin.Denom <= request.AllBalances.StartAfter {
+					return false
+				}
+				if uint32(len(coins)) == limit {
+					res.NextKey = coins[len(coins)-1].Denom
+					return true
+				}
+				coins = append(coins, coin)
+				return false
+			})
+			res.Amount = convertSdkCoinsToWasmCoins(coins)
 			return json.Marshal(res)
 		}
 		if request.Balance != nil {
@@ -259,6 +381,35 @@ func BankQuerier(bankKeeper bankkeeper.ViewKeeper) func(ctx sdk.Context, request
 			}
 			return json.Marshal(res)
 		}
+		if request.SupplyOf != nil {
+			if err := sdk.ValidateDenom(request.SupplyOf.Denom); err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, err.Error())
+			}
+			// an unknown denom has a supply of zero
+			supply := bankKeeper.GetSupply(ctx, request.SupplyOf.Denom)
+			res := wasmTypes.SupplyOfResponse{
+				Amount: wasmTypes.Coin{
+					Denom:  supply.Denom,
+					Amount: supply.Amount.String(),
+				},
+			}
+			return json.Marshal(res)
+		}
+		if request.SendEnabled != nil {
+			if len(request.SendEnabled.Denoms) > maxQueriedSendEnabledDenoms {
+				return nil, sdkerrors.Wrapf(types.ErrLimit, "can't query more than %d denoms", maxQueriedSendEnabledDenoms)
+			}
+			params := bankKeeper.GetParams(ctx)
+			res := wasmTypes.SendEnabledResponse{SendEnabled: make([]wasmTypes.DenomSendEnabled, len(request.SendEnabled.Denoms))}
+			for i, denom := range request.SendEnabled.Denoms {
+				if err := sdk.ValidateDenom(denom); err != nil {
+					return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidCoins, err.Error())
+				}
+				// a denom without its own flag has the default one
+				res.SendEnabled[i] = wasmTypes.DenomSendEnabled{Denom: denom, Enabled: params.SendEnabledDenom(denom)}
+			}
+			return json.Marshal(res)
+		}
 		return nil, wasmTypes.UnsupportedRequest{Kind: "unknown BankQuery variant"}
 	}
 }
@@ -267,6 +418,10 @@ func NoCustomQuerier(sdk.Context, json.RawMessage) ([]byte, error) {
 	return nil, wasmTypes.UnsupportedRequest{Kind: "custom"}
 }
 
+// maxQueriedDelegations is the most delegations (or unbondings) a single AllDelegations (or UnbondingDelegations)
+// query loads for a contract
+const maxQueriedDelegations = 100
+
 func StakingQuerier(keeper stakingkeeper.Keeper, distKeeper distrkeeper.Keeper) func(ctx sdk.Context, request *wasmTypes.StakingQuery) ([]byte, error) {
 	return func(ctx sdk.Context, request *wasmTypes.StakingQuery) ([]byte, error) {
 		if request.BondedDenom != nil {
@@ -276,6 +431,17 @@ func StakingQuerier(keeper stakingkeeper.Keeper, distKeeper distrkeeper.Keeper)
 			}
 			return json.Marshal(res)
 		}
+		if request.Params != nil {
+			params := keeper.GetParams(ctx)
+			res := wasmTypes.StakingParamsResponse{
+				UnbondingTime:     uint64(params.UnbondingTime / time.Second),
+				MaxValidators:     params.MaxValidators,
+				MaxEntries:        params.MaxEntries,
+				HistoricalEntries: params.HistoricalEntries,
+				BondDenom:         params.BondDenom,
+			}
+			return json.Marshal(res)
+		}
 		if request.Validators != nil {
 			validators := keeper.GetBondedValidatorsByPower(ctx)
 			//validators := keeper.GetAllValidators(ctx)
@@ -293,12 +459,37 @@ func StakingQuerier(keeper stakingkeeper.Keeper, distKeeper distrkeeper.Keeper)
 			}
 			return json.Marshal(res)
 		}
+		if request.Validator != nil {
+			valAddr, err := sdk.ValAddressFromBech32(request.Validator.Address)
+			if err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.Validator.Address)
+			}
+			v, found := keeper.GetValidator(ctx, valAddr)
+			if !found {
+				return nil, sdkerrors.Wrap(stakingtypes.ErrNoValidatorFound, request.Validator.Address)
+			}
+			res := wasmTypes.ValidatorResponse{
+				Validator: wasmTypes.FullValidator{
+					Validator: wasmTypes.Validator{
+						Address:       v.OperatorAddress,
+						Commission:    v.Commission.Rate.String(),
+						MaxCommission: v.Commission.MaxRate.String(),
+						MaxChangeRate: v.Commission.MaxChangeRate.String(),
+					},
+					Status: validatorStatus(v.Status),
+					Tokens: v.Tokens.String(),
+					Jailed: v.Jailed,
+				},
+			}
+			return json.Marshal(res)
+		}
 		if request.AllDelegations != nil {
 			delegator, err := sdk.AccAddressFromBech32(request.AllDelegations.Delegator)
 			if err != nil {
 				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.AllDelegations.Delegator)
 			}
-			sdkDels := keeper.GetAllDelegatorDelegations(ctx, delegator)
+			// every delegation costs reads of the delegation and the validator, so bound how many we load
+			sdkDels := keeper.GetDelegatorDelegations(ctx, delegator, maxQueriedDelegations)
 			delegations, err := sdkToDelegations(ctx, keeper, sdkDels)
 			if err != nil {
 				return nil, err
@@ -329,55 +520,49 @@ func StakingQuerier(keeper stakingkeeper.Keeper, distKeeper distrkeeper.Keeper)
 			return json.Marshal(res)
 		}
 		if request.UnBondingDelegations != nil {
-			bondDenom := keeper.BondDenom(ctx)
-
 			delegator, err := sdk.AccAddressFromBech32(request.UnBondingDelegations.Delegator)
 			if err != nil {
-				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.Delegation.Delegator)
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.UnBondingDelegations.Delegator)
 			}
-
-			unbondingDelegations := keeper.GetAllUnbondingDelegations(ctx, delegator)
-			if unbondingDelegations == nil {
-				unbondingDelegations = stakingtypes.UnbondingDelegations{}
+			sdkUnbondings := keeper.GetUnbondingDelegations(ctx, delegator, maxQueriedDelegations)
+			res := wasmTypes.UnbondingDelegationsResponse{
+				Delegations: sdkToUnbondingDelegations(keeper.BondDenom(ctx), sdkUnbondings),
 			}
-
-			delegations, err := sdkToUnbondingDelegations(bondDenom, unbondingDelegations)
-			if err != nil {
-				return nil, err
-			}
-
-			var res wasmTypes.UnbondingDelegationsResponse
-			res.Delegations = delegations
-
 			return json.Marshal(res)
-
 		}
 		return nil, wasmTypes.UnsupportedRequest{Kind: "unknown Staking variant"}
 	}
 }
 
-func sdkToUnbondingDelegations(bondDenom string, delegations stakingtypes.UnbondingDelegations) ([]wasmTypes.Delegation, error) {
-	result := make([]wasmTypes.Delegation, len(delegations))
-
-	for i, d := range delegations {
-
+func sdkToUnbondingDelegations(bondDenom string, unbondings []stakingtypes.UnbondingDelegation) wasmTypes.UnbondingDelegations {
+	var result wasmTypes.UnbondingDelegations
+	for _, d := range unbondings {
 		for _, e := range d.Entries {
-
-			wasmCoin := wasmTypes.Coin{
-				Denom:  bondDenom,
-				Amount: e.Balance.String(),
-			}
-
-			result[i] = wasmTypes.Delegation{
+			result = append(result, wasmTypes.UnbondingDelegation{
 				Delegator: d.DelegatorAddress,
 				Validator: d.ValidatorAddress,
-				Amount:    wasmCoin,
-			}
-
+				Amount: wasmTypes.Coin{
+					Denom:  bondDenom,
+					Amount: e.Balance.String(),
+				},
+				CompletionTime: uint64(e.CompletionTime.Unix()),
+				CreationHeight: e.CreationHeight,
+			})
 		}
+	}
+	return result
+}
 
+// validatorStatus converts a bond status to the name contracts see
+func validatorStatus(status stakingtypes.BondStatus) string {
+	switch status {
+	case stakingtypes.Bonded:
+		return "bonded"
+	case stakingtypes.Unbonding:
+		return "unbonding"
+	default:
+		return "unbonded"
 	}
-	return result, nil
 }
 
 func sdkToDelegations(ctx sdk.Context, keeper stakingkeeper.Keeper, delegations []stakingtypes.Delegation) (wasmTypes.Delegations, error) {
@@ -487,6 +672,9 @@ func getAccumulatedRewards(ctx sdk.Context, distKeeper distrkeeper.Keeper, deleg
 	return rewards, nil
 }
 
+// maxQueriedContracts is the most addresses returned to a contract by a single ContractsByCode query
+const maxQueriedContracts = 100
+
 func WasmQuerier(wasm *Keeper) func(ctx sdk.Context, request *wasmTypes.WasmQuery) ([]byte, error) {
 	return func(ctx sdk.Context, request *wasmTypes.WasmQuery) ([]byte, error) {
 		if request.Smart != nil {
@@ -505,6 +693,72 @@ func WasmQuerier(wasm *Keeper) func(ctx sdk.Context, request *wasmTypes.WasmQuer
 			// TODO: do we want to change the return value?
 			return json.Marshal(models)
 		}
+		if request.ContractInfo != nil {
+			addr, err := sdk.AccAddressFromBech32(request.ContractInfo.ContractAddr)
+			if err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.ContractInfo.ContractAddr)
+			}
+			info := wasm.GetContractInfo(ctx, addr)
+			if info == nil {
+				return nil, sdkerrors.Wrap(types.ErrNotFound, request.ContractInfo.ContractAddr)
+			}
+			res := wasmTypes.ContractInfoResponse{
+				CodeID:  info.CodeID,
+				Creator: info.Creator.String(),
+				Label:   info.Label,
+			}
+			return json.Marshal(res)
+		}
+		if request.IsContract != nil {
+			addr, err := sdk.AccAddressFromBech32(request.IsContract.Address)
+			if err != nil {
+				return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.IsContract.Address)
+			}
+			res := wasmTypes.IsContractResponse{
+				IsContract: wasm.containsContractInfo(ctx, addr),
+			}
+			return json.Marshal(res)
+		}
+		if request.ContractsByCode != nil {
+			limit := request.ContractsByCode.Limit
+			if limit == 0 || limit > maxQueriedContracts {
+				limit = maxQueriedContracts
+			}
+			pageReq := &query.PageRequest{Limit: uint64(limit)}
+			if request.ContractsByCode.StartAfter != "" {
+				startAfter, err := sdk.AccAddressFromBech32(request.ContractsByCode.StartAfter)
+				if err != nil {
+					return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, request.ContractsByCode.StartAfter)
+				}
+				// the first key after the address
+				pageReq.Key = append(startAfter, 0)
+			}
+			contracts, _, err := wasm.ContractsByCodeIDPaginated(ctx, request.ContractsByCode.CodeID, pageReq)
+			if err != nil {
+				return nil, err
+			}
+			res := wasmTypes.ContractsByCodeResponse{Contracts: make([]string, len(contracts))}
+			for i, contract := range contracts {
+				res.Contracts[i] = contract.String()
+			}
+			return json.Marshal(res)
+		}
+		if request.CodeInfo != nil {
+			if request.CodeInfo.CodeID == 0 {
+				return nil, sdkerrors.Wrap(types.ErrInvalid, "code id cannot be 0")
+			}
+			info := wasm.GetCodeInfo(ctx, request.CodeInfo.CodeID)
+			if info == nil {
+				return nil, sdkerrors.Wrapf(types.ErrNotFound, "code id: %d", request.CodeInfo.CodeID)
+			}
+			res := wasmTypes.CodeInfoResponse{
+				CodeHash: hex.EncodeToString(info.CodeHash),
+				Creator:  info.Creator.String(),
+				Source:   info.Source,
+				Builder:  info.Builder,
+			}
+			return json.Marshal(res)
+		}
 		return nil, wasmTypes.UnsupportedRequest{Kind: "unknown WasmQuery variant"}
 	}
 }
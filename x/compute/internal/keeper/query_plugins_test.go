@@ -0,0 +1,691 @@
+package keeper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/staking"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+func TestDistQuerierWithdrawAddress(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	distKeeper := keepers.DistKeeper
+
+	_, _, delegator := keyPubAddr()
+	_, _, withdrawAddr := keyPubAddr()
+	querier := DistQuerier(distKeeper)
+
+	query := func(delegator string) (wasmTypes.WithdrawAddressResponse, error) {
+		var res wasmTypes.WithdrawAddressResponse
+		bz, err := querier(ctx, &wasmTypes.DistQuery{WithdrawAddress: &wasmTypes.WithdrawAddressQuery{Delegator: delegator}})
+		if err != nil {
+			return res, err
+		}
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res, nil
+	}
+
+	// defaults to the delegator itself
+	res, err := query(delegator.String())
+	require.NoError(t, err)
+	assert.Equal(t, delegator.String(), res.WithdrawAddress)
+
+	require.NoError(t, distKeeper.SetWithdrawAddr(ctx, delegator, withdrawAddr))
+	res, err = query(delegator.String())
+	require.NoError(t, err)
+	assert.Equal(t, withdrawAddr.String(), res.WithdrawAddress)
+
+	_, err = query("invalid")
+	require.Error(t, err)
+}
+
+func TestDistQuerierDelegationRewards(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, stakingKeeper, distKeeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.StakingKeeper, keepers.DistKeeper
+
+	valAddr := addValidator(ctx, stakingKeeper, accKeeper, bankKeeper, sdk.NewInt64Coin("stake", 1000000))
+	ctx = nextBlock(ctx, stakingKeeper)
+	delegator, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000000)))
+	_, err := staking.NewHandler(stakingKeeper)(ctx, stakingtypes.NewMsgDelegate(delegator, valAddr, sdk.NewInt64Coin("stake", 1000000)))
+	require.NoError(t, err)
+	// a delegation earns no rewards in the block it's created in
+	ctx = nextBlock(ctx, stakingKeeper)
+
+	querier := DistQuerier(distKeeper)
+	query := func(delegator string, validator string) (wasmTypes.RewardCoins, error) {
+		bz, err := querier(ctx, &wasmTypes.DistQuery{DelegationRewards: &wasmTypes.DelegationRewardsQuery{Delegator: delegator, Validator: validator}})
+		if err != nil {
+			return nil, err
+		}
+		var res wasmTypes.DelegationRewardsResponse
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res.Rewards, nil
+	}
+
+	// nothing accrued yet
+	rewards, err := query(delegator.String(), valAddr.String())
+	require.NoError(t, err)
+	assert.Empty(t, rewards)
+
+	// the delegator has half of the stake, and the validator takes a commission of 10%
+	v, found := stakingKeeper.GetValidator(ctx, valAddr)
+	require.True(t, found)
+	distKeeper.AllocateTokensToValidator(ctx, v, sdk.NewDecCoins(sdk.NewDecCoin("stake", sdk.NewInt(1001))))
+	period := distKeeper.GetValidatorCurrentRewards(ctx, valAddr).Period
+
+	rewards, err = query(delegator.String(), valAddr.String())
+	require.NoError(t, err)
+	// fractions of a coin are dropped, 450.45 is returned as 450
+	assert.Equal(t, wasmTypes.RewardCoins{wasmTypes.NewCoin(450, "stake")}, rewards)
+	// the query doesn't change the state of the distribution module
+	assert.Equal(t, period, distKeeper.GetValidatorCurrentRewards(ctx, valAddr).Period)
+
+	// there is nothing to withdraw without a delegation
+	_, _, other := keyPubAddr()
+	rewards, err = query(other.String(), valAddr.String())
+	require.NoError(t, err)
+	assert.Empty(t, rewards)
+
+	_, err = query(delegator.String(), delegator.String())
+	require.Error(t, err)
+	_, err = query("invalid", valAddr.String())
+	require.Error(t, err)
+}
+
+func TestStakingQuerierAllDelegations(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, stakingKeeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.StakingKeeper
+
+	valAddr1 := addValidator(ctx, stakingKeeper, accKeeper, bankKeeper, sdk.NewInt64Coin("stake", 1000000))
+	valAddr2 := addValidator(ctx, stakingKeeper, accKeeper, bankKeeper, sdk.NewInt64Coin("stake", 2000000))
+	ctx = nextBlock(ctx, stakingKeeper)
+
+	delegator, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	h := staking.NewHandler(stakingKeeper)
+	_, err := h(ctx, stakingtypes.NewMsgDelegate(delegator, valAddr1, sdk.NewInt64Coin("stake", 100)))
+	require.NoError(t, err)
+	_, err = h(ctx, stakingtypes.NewMsgDelegate(delegator, valAddr2, sdk.NewInt64Coin("stake", 200)))
+	require.NoError(t, err)
+
+	querier := StakingQuerier(stakingKeeper, keepers.DistKeeper)
+	bz, err := querier(ctx, &wasmTypes.StakingQuery{AllDelegations: &wasmTypes.AllDelegationsQuery{Delegator: delegator.String()}})
+	require.NoError(t, err)
+	var res wasmTypes.AllDelegationsResponse
+	require.NoError(t, json.Unmarshal(bz, &res))
+	assert.ElementsMatch(t, wasmTypes.Delegations{
+		{Delegator: delegator.String(), Validator: valAddr1.String(), Amount: wasmTypes.NewCoin(100, "stake")},
+		{Delegator: delegator.String(), Validator: valAddr2.String(), Amount: wasmTypes.NewCoin(200, "stake")},
+	}, res.Delegations)
+
+	_, err = querier(ctx, &wasmTypes.StakingQuery{AllDelegations: &wasmTypes.AllDelegationsQuery{Delegator: valAddr1.String()}})
+	require.Error(t, err)
+}
+
+func TestStakingQuerierUnbondingDelegations(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, stakingKeeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.StakingKeeper
+
+	params := stakingKeeper.GetParams(ctx)
+	params.UnbondingTime = 72 * time.Hour
+	stakingKeeper.SetParams(ctx, params)
+
+	valAddr := addValidator(ctx, stakingKeeper, accKeeper, bankKeeper, sdk.NewInt64Coin("stake", 1000000))
+	ctx = nextBlock(ctx, stakingKeeper)
+	ctx = ctx.WithBlockTime(time.Unix(1_600_000_000, 0))
+
+	delegator, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("stake", 1000)))
+	h := staking.NewHandler(stakingKeeper)
+	_, err := h(ctx, stakingtypes.NewMsgDelegate(delegator, valAddr, sdk.NewInt64Coin("stake", 500)))
+	require.NoError(t, err)
+
+	querier := StakingQuerier(stakingKeeper, keepers.DistKeeper)
+	query := func(delegator string) wasmTypes.UnbondingDelegations {
+		bz, err := querier(ctx, &wasmTypes.StakingQuery{UnBondingDelegations: &wasmTypes.UnbondingDelegationsQuery{Delegator: delegator}})
+		require.NoError(t, err)
+		var res wasmTypes.UnbondingDelegationsResponse
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res.Delegations
+	}
+	assert.Empty(t, query(delegator.String()))
+
+	// every entry is returned, not just one per validator
+	_, err = h(ctx, stakingtypes.NewMsgUndelegate(delegator, valAddr, sdk.NewInt64Coin("stake", 100)))
+	require.NoError(t, err)
+	_, err = h(ctx.WithBlockHeight(ctx.BlockHeight()+1), stakingtypes.NewMsgUndelegate(delegator, valAddr, sdk.NewInt64Coin("stake", 50)))
+	require.NoError(t, err)
+
+	completion := uint64(ctx.BlockTime().Add(72 * time.Hour).Unix())
+	assert.Equal(t, wasmTypes.UnbondingDelegations{
+		{Delegator: delegator.String(), Validator: valAddr.String(), Amount: wasmTypes.NewCoin(100, "stake"), CompletionTime: completion, CreationHeight: ctx.BlockHeight()},
+		{Delegator: delegator.String(), Validator: valAddr.String(), Amount: wasmTypes.NewCoin(50, "stake"), CompletionTime: completion, CreationHeight: ctx.BlockHeight() + 1},
+	}, query(delegator.String()))
+
+	_, err = querier(ctx, &wasmTypes.StakingQuery{UnBondingDelegations: &wasmTypes.UnbondingDelegationsQuery{Delegator: valAddr.String()}})
+	require.Error(t, err)
+}
+
+func TestStakingQuerierBondedDenom(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	stakingKeeper := keepers.StakingKeeper
+
+	params := stakingKeeper.GetParams(ctx)
+	params.BondDenom = "uscrt"
+	stakingKeeper.SetParams(ctx, params)
+
+	querier := StakingQuerier(stakingKeeper, keepers.DistKeeper)
+	bz, err := querier(ctx, &wasmTypes.StakingQuery{BondedDenom: &struct{}{}})
+	require.NoError(t, err)
+	var res wasmTypes.BondedDenomResponse
+	require.NoError(t, json.Unmarshal(bz, &res))
+	assert.Equal(t, "uscrt", res.Denom)
+}
+
+func TestStakingQuerierParams(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	stakingKeeper := keepers.StakingKeeper
+
+	params := stakingKeeper.GetParams(ctx)
+	params.UnbondingTime = 21 * 24 * time.Hour
+	params.MaxValidators = 50
+	params.BondDenom = "uscrt"
+	stakingKeeper.SetParams(ctx, params)
+
+	querier := StakingQuerier(stakingKeeper, keepers.DistKeeper)
+	bz, err := querier(ctx, &wasmTypes.StakingQuery{Params: &struct{}{}})
+	require.NoError(t, err)
+	var res wasmTypes.StakingParamsResponse
+	require.NoError(t, json.Unmarshal(bz, &res))
+	assert.Equal(t, wasmTypes.StakingParamsResponse{
+		UnbondingTime:     21 * 24 * 60 * 60,
+		MaxValidators:     50,
+		MaxEntries:        params.MaxEntries,
+		HistoricalEntries: params.HistoricalEntries,
+		BondDenom:         "uscrt",
+	}, res)
+}
+
+func TestDistQuerierParams(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	distKeeper := keepers.DistKeeper
+
+	params := distKeeper.GetParams(ctx)
+	params.CommunityTax = sdk.NewDecWithPrec(2, 2)
+	params.WithdrawAddrEnabled = false
+	distKeeper.SetParams(ctx, params)
+
+	bz, err := DistQuerier(distKeeper)(ctx, &wasmTypes.DistQuery{Params: &struct{}{}})
+	require.NoError(t, err)
+	var res wasmTypes.DistParamsResponse
+	require.NoError(t, json.Unmarshal(bz, &res))
+	assert.Equal(t, wasmTypes.DistParamsResponse{
+		CommunityTax:        "0.020000000000000000",
+		BaseProposerReward:  params.BaseProposerReward.String(),
+		BonusProposerReward: params.BonusProposerReward.String(),
+		WithdrawAddrEnabled: false,
+	}, res)
+}
+
+func TestStakingQuerierValidator(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, stakingKeeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.StakingKeeper
+
+	valAddr := addValidator(ctx, stakingKeeper, accKeeper, bankKeeper, sdk.NewInt64Coin("stake", 1000000))
+	ctx = nextBlock(ctx, stakingKeeper)
+	_, _, unknown := keyPubAddr()
+
+	querier := StakingQuerier(stakingKeeper, keepers.DistKeeper)
+	bz, err := querier(ctx, &wasmTypes.StakingQuery{Validator: &wasmTypes.ValidatorQuery{Address: valAddr.String()}})
+	require.NoError(t, err)
+	var res wasmTypes.ValidatorResponse
+	require.NoError(t, json.Unmarshal(bz, &res))
+	v, found := stakingKeeper.GetValidator(ctx, valAddr)
+	require.True(t, found)
+	assert.Equal(t, wasmTypes.FullValidator{
+		Validator: wasmTypes.Validator{
+			Address:       valAddr.String(),
+			Commission:    v.Commission.Rate.String(),
+			MaxCommission: v.Commission.MaxRate.String(),
+			MaxChangeRate: v.Commission.MaxChangeRate.String(),
+		},
+		Status: "bonded",
+		Tokens: "1000000",
+		Jailed: false,
+	}, res.Validator)
+
+	_, err = querier(ctx, &wasmTypes.StakingQuery{Validator: &wasmTypes.ValidatorQuery{Address: sdk.ValAddress(unknown).String()}})
+	require.Error(t, err)
+	assert.True(t, stakingtypes.ErrNoValidatorFound.Is(err), err)
+
+	_, err = querier(ctx, &wasmTypes.StakingQuery{Validator: &wasmTypes.ValidatorQuery{Address: unknown.String()}})
+	require.Error(t, err)
+}
+
+func TestBankQuerierAllBalances(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper := keepers.AccountKeeper, keepers.BankKeeper
+
+	addr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(
+		sdk.NewInt64Coin("uscrt", 100),
+		sdk.NewInt64Coin("atom", 200),
+		sdk.NewInt64Coin("btc", 300),
+	))
+	_, _, unknown := keyPubAddr()
+
+	querier := BankQuerier(bankKeeper)
+	query := func(q wasmTypes.AllBalancesQuery) wasmTypes.AllBalancesResponse {
+		bz, err := querier(ctx, &wasmTypes.BankQuery{AllBalances: &q})
+		require.NoError(t, err)
+		var res wasmTypes.AllBalancesResponse
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res
+	}
+
+	res := query(wasmTypes.AllBalancesQuery{Address: addr.String()})
+	assert.Equal(t, wasmTypes.Coins{
+		wasmTypes.NewCoin(200, "atom"),
+		wasmTypes.NewCoin(300, "btc"),
+		wasmTypes.NewCoin(100, "uscrt"),
+	}, res.Amount)
+	assert.Empty(t, res.NextKey)
+
+	// paginate
+	res = query(wasmTypes.AllBalancesQuery{Address: addr.String(), Limit: 2})
+	assert.Equal(t, wasmTypes.Coins{wasmTypes.NewCoin(200, "atom"), wasmTypes.NewCoin(300, "btc")}, res.Amount)
+	assert.Equal(t, "btc", res.NextKey)
+	res = query(wasmTypes.AllBalancesQuery{Address: addr.String(), Limit: 2, StartAfter: res.NextKey})
+	assert.Equal(t, wasmTypes.Coins{wasmTypes.NewCoin(100, "uscrt")}, res.Amount)
+	assert.Empty(t, res.NextKey)
+
+	// unknown accounts have no balances
+	res = query(wasmTypes.AllBalancesQuery{Address: unknown.String()})
+	assert.Empty(t, res.Amount)
+
+	_, err := querier(ctx, &wasmTypes.BankQuery{AllBalances: &wasmTypes.AllBalancesQuery{Address: "invalid"}})
+	require.Error(t, err)
+}
+
+func TestBankQuerierSupplyOf(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	bankKeeper := keepers.BankKeeper
+
+	before := bankKeeper.GetSupply(ctx, "utoken")
+	require.True(t, before.IsZero())
+	CreateFakeFundedAccount(ctx, keepers.AccountKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("utoken", 12345)))
+
+	querier := BankQuerier(bankKeeper)
+	query := func(denom string) (wasmTypes.SupplyOfResponse, error) {
+		var res wasmTypes.SupplyOfResponse
+		bz, err := querier(ctx, &wasmTypes.BankQuery{SupplyOf: &wasmTypes.SupplyOfQuery{Denom: denom}})
+		if err != nil {
+			return res, err
+		}
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res, nil
+	}
+
+	res, err := query("utoken")
+	require.NoError(t, err)
+	assert.Equal(t, wasmTypes.NewCoin(12345, "utoken"), res.Amount)
+
+	res, err = query("unknown")
+	require.NoError(t, err)
+	assert.Equal(t, wasmTypes.NewCoin(0, "unknown"), res.Amount)
+
+	_, err = query("1nvalid!")
+	require.Error(t, err)
+}
+
+func TestBankQuerierSendEnabled(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	bankKeeper := keepers.BankKeeper
+
+	bankParams := banktypes.DefaultParams().SetSendEnabledParam("udisabled", false).SetSendEnabledParam("uenabled", true)
+	bankKeeper.SetParams(ctx, bankParams)
+
+	querier := BankQuerier(bankKeeper)
+	query := func(denoms ...string) ([]wasmTypes.DenomSendEnabled, error) {
+		bz, err := querier(ctx, &wasmTypes.BankQuery{SendEnabled: &wasmTypes.SendEnabledQuery{Denoms: denoms}})
+		if err != nil {
+			return nil, err
+		}
+		var res wasmTypes.SendEnabledResponse
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res.SendEnabled, nil
+	}
+
+	res, err := query("udisabled", "uenabled", "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, []wasmTypes.DenomSendEnabled{
+		{Denom: "udisabled", Enabled: false},
+		{Denom: "uenabled", Enabled: true},
+		// the default
+		{Denom: "unknown", Enabled: true},
+	}, res)
+
+	bankParams.DefaultSendEnabled = false
+	bankKeeper.SetParams(ctx, bankParams)
+	res, err = query("uenabled", "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, []wasmTypes.DenomSendEnabled{{Denom: "uenabled", Enabled: true}, {Denom: "unknown", Enabled: false}}, res)
+
+	_, err = query("1nvalid!")
+	require.Error(t, err)
+	_, err = query(make([]string, maxQueriedSendEnabledDenoms+1)...)
+	assert.True(t, types.ErrLimit.Is(err), err)
+}
+
+func TestWasmQuerierContractInfo(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, creator := keyPubAddr()
+	_, _, contractAddr := keyPubAddr()
+	_, _, unknown := keyPubAddr()
+	contractInfo := types.NewContractInfo(7, creator, "my contract", types.NewAbsoluteTxPosition(ctx))
+	keeper.setContractInfo(ctx, contractAddr, &contractInfo)
+
+	querier := WasmQuerier(&keeper)
+	bz, err := querier(ctx, &wasmTypes.WasmQuery{ContractInfo: &wasmTypes.ContractInfoQuery{ContractAddr: contractAddr.String()}})
+	require.NoError(t, err)
+	var res wasmTypes.ContractInfoResponse
+	require.NoError(t, json.Unmarshal(bz, &res))
+	assert.Equal(t, wasmTypes.ContractInfoResponse{CodeID: 7, Creator: creator.String(), Label: "my contract"}, res)
+
+	_, err = querier(ctx, &wasmTypes.WasmQuery{ContractInfo: &wasmTypes.ContractInfoQuery{ContractAddr: unknown.String()}})
+	require.Error(t, err)
+	assert.True(t, types.ErrNotFound.Is(err), err)
+
+	_, err = querier(ctx, &wasmTypes.WasmQuery{ContractInfo: &wasmTypes.ContractInfoQuery{ContractAddr: "invalid"}})
+	require.Error(t, err)
+}
+
+func TestWasmQuerierIsContract(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	accKeeper, bankKeeper, keeper := keepers.AccountKeeper, keepers.BankKeeper, keepers.WasmKeeper
+
+	_, _, contractAddr := keyPubAddr()
+	_, _, creator := keyPubAddr()
+	contractInfo := types.NewContractInfo(7, creator, "my contract", types.NewAbsoluteTxPosition(ctx))
+	keeper.setContractInfo(ctx, contractAddr, &contractInfo)
+	// an account that exists, but isn't a contract
+	accountAddr, _ := CreateFakeFundedAccount(ctx, accKeeper, bankKeeper, sdk.NewCoins(sdk.NewInt64Coin("denom", 100)))
+
+	querier := WasmQuerier(&keeper)
+	isContract := func(addr string) (bool, error) {
+		bz, err := querier(ctx, &wasmTypes.WasmQuery{IsContract: &wasmTypes.IsContractQuery{Address: addr}})
+		if err != nil {
+			return false, err
+		}
+		var res wasmTypes.IsContractResponse
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res.IsContract, nil
+	}
+
+	res, err := isContract(contractAddr.String())
+	require.NoError(t, err)
+	assert.True(t, res)
+
+	res, err = isContract(accountAddr.String())
+	require.NoError(t, err)
+	assert.False(t, res)
+
+	_, err = isContract("invalid")
+	require.Error(t, err)
+}
+
+func TestWasmQuerierContractsByCode(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, creator := keyPubAddr()
+	var contracts []sdk.AccAddress
+	for i := 0; i < 3; i++ {
+		_, _, contractAddr := keyPubAddr()
+		contractInfo := types.NewContractInfo(1, creator, fmt.Sprintf("contract %d", i), types.NewAbsoluteTxPosition(ctx))
+		keeper.setContractInfo(ctx, contractAddr, &contractInfo)
+		contracts = append(contracts, contractAddr)
+	}
+	// a contract of another code
+	_, _, otherAddr := keyPubAddr()
+	otherInfo := types.NewContractInfo(2, creator, "other contract", types.NewAbsoluteTxPosition(ctx))
+	keeper.setContractInfo(ctx, otherAddr, &otherInfo)
+
+	sort.Slice(contracts, func(i, j int) bool { return bytes.Compare(contracts[i], contracts[j]) < 0 })
+	assert.Equal(t, contracts, keeper.ContractsByCodeID(ctx, 1))
+	assert.Equal(t, []sdk.AccAddress{otherAddr}, keeper.ContractsByCodeID(ctx, 2))
+	assert.Empty(t, keeper.ContractsByCodeID(ctx, 3))
+
+	querier := WasmQuerier(&keeper)
+	contractsByCode := func(q wasmTypes.ContractsByCodeQuery) []string {
+		bz, err := querier(ctx, &wasmTypes.WasmQuery{ContractsByCode: &q})
+		require.NoError(t, err)
+		var res wasmTypes.ContractsByCodeResponse
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res.Contracts
+	}
+
+	assert.Equal(t, []string{contracts[0].String(), contracts[1].String(), contracts[2].String()}, contractsByCode(wasmTypes.ContractsByCodeQuery{CodeID: 1}))
+	assert.Equal(t, []string{contracts[0].String(), contracts[1].String()}, contractsByCode(wasmTypes.ContractsByCodeQuery{CodeID: 1, Limit: 2}))
+	assert.Equal(t, []string{contracts[2].String()}, contractsByCode(wasmTypes.ContractsByCodeQuery{CodeID: 1, StartAfter: contracts[1].String(), Limit: 2}))
+	assert.Empty(t, contractsByCode(wasmTypes.ContractsByCodeQuery{CodeID: 1, StartAfter: contracts[2].String()}))
+	assert.Empty(t, contractsByCode(wasmTypes.ContractsByCodeQuery{CodeID: 3}))
+
+	_, err := querier(ctx, &wasmTypes.WasmQuery{ContractsByCode: &wasmTypes.ContractsByCodeQuery{CodeID: 1, StartAfter: "invalid"}})
+	assert.True(t, sdkerrors.ErrInvalidAddress.Is(err), err)
+}
+
+func TestWasmQuerierCodeInfo(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+
+	_, _, creator := keyPubAddr()
+	codeHash := sha256.Sum256([]byte("some wasm code"))
+	codeInfo := types.NewCodeInfo(codeHash[:], creator, "https://example.com/source", "enigmampc/secret-contract-optimizer:1.0.8")
+	ctx.KVStore(keeper.storeKey).Set(types.GetCodeKey(1), keeper.cdc.MustMarshal(&codeInfo))
+
+	querier := WasmQuerier(&keeper)
+	query := func(codeID uint64) (wasmTypes.CodeInfoResponse, error) {
+		var res wasmTypes.CodeInfoResponse
+		bz, err := querier(ctx, &wasmTypes.WasmQuery{CodeInfo: &wasmTypes.CodeInfoQuery{CodeID: codeID}})
+		if err != nil {
+			return res, err
+		}
+		require.NoError(t, json.Unmarshal(bz, &res))
+		return res, nil
+	}
+
+	res, err := query(1)
+	require.NoError(t, err)
+	assert.Equal(t, wasmTypes.CodeInfoResponse{
+		CodeHash: hex.EncodeToString(codeHash[:]),
+		Creator:  creator.String(),
+		Source:   "https://example.com/source",
+		Builder:  "enigmampc/secret-contract-optimizer:1.0.8",
+	}, res)
+
+	_, err = query(2)
+	require.Error(t, err)
+	assert.True(t, types.ErrNotFound.Is(err), err)
+
+	_, err = query(0)
+	require.Error(t, err)
+	assert.True(t, types.ErrInvalid.Is(err), err)
+}
+
+func TestQuerySmartMaxStackSize(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+	params := keeper.GetParams(ctx)
+	params.MaxQueryStackSize = 3
+	keeper.SetParams(ctx, params)
+	_, _, contractAddr := keyPubAddr()
+
+	// every contract in the chain queries the next one through the query plugin
+	querier := WasmQuerier(&keeper)
+	query := func(ctx sdk.Context) error {
+		_, err := querier(ctx, &wasmTypes.WasmQuery{Smart: &wasmTypes.SmartQuery{ContractAddr: contractAddr.String(), Msg: []byte(`{}`)}})
+		return err
+	}
+	var err error
+	for depth := 0; depth < 3; depth++ {
+		// there is no contract at the end of the chain, so a query that isn't rejected for its depth isn't found
+		err = query(ctx)
+		assert.True(t, types.ErrNotFound.Is(err), "depth %d: %v", depth, err)
+
+		ctx, err = checkAndIncreaseQueryStackSize(ctx, 3)
+		require.NoError(t, err)
+	}
+	err = query(ctx)
+	assert.True(t, types.ErrMaxQueryStackSize.Is(err), err)
+}
+
+func TestQuerySmartUsesMaxStackSizeOfCtx(t *testing.T) {
+	ctx, keepers := CreateTestInput(t, false, SupportedFeatures, nil, nil)
+	keeper := keepers.WasmKeeper
+	_, _, contractAddr := keyPubAddr()
+	require.Equal(t, types.DefaultMaxQueryStackSize, keeper.GetParams(ctx).MaxQueryStackSize)
+
+	// the contract call that queries set a lower limit than the params
+	ctx = withMaxQueryStackSize(ctx, 1)
+	_, err := keeper.QuerySmart(ctx, contractAddr, []byte(`{}`), false)
+	assert.True(t, types.ErrNotFound.Is(err), err)
+
+	nested, err := checkAndIncreaseQueryStackSize(ctx, 1)
+	require.NoError(t, err)
+	_, err = keeper.QuerySmart(nested, contractAddr, []byte(`{}`), false)
+	assert.True(t, types.ErrMaxQueryStackSize.Is(err), err)
+}
+
+func TestCustomQuerier(t *testing.T) {
+	var calledWith json.RawMessage
+	fakeQuerier := func(_ sdk.Context, request json.RawMessage) ([]byte, error) {
+		calledWith = request
+		return []byte(`{"price":"1.5"}`), nil
+	}
+
+	keeper := Keeper{queryPlugins: QueryPlugins{Custom: NoCustomQuerier}}
+	WithCustomQuerier("oracle", fakeQuerier).apply(&keeper)
+	q := QueryHandler{
+		Ctx:     encodingTestContext().WithGasMeter(sdk.NewGasMeter(1000000)),
+		Plugins: keeper.queryPlugins,
+	}
+
+	res, err := q.Query(wasmTypes.QueryRequest{Custom: json.RawMessage(`{"oracle":{"price":{"denom":"uscrt"}}}`)}, 1000000*types.GasMultiplier)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"price":"1.5"}`), res)
+	assert.JSONEq(t, `{"price":{"denom":"uscrt"}}`, string(calledWith))
+
+	// unregistered variants fall through to the default custom querier
+	_, err = q.Query(wasmTypes.QueryRequest{Custom: json.RawMessage(`{"unknown":{}}`)}, 1000000*types.GasMultiplier)
+	require.Error(t, err)
+
+	// registering a querier must not leak into the plugins it was merged into
+	base := QueryPlugins{CustomQueriers: map[string]CustomQuerier{"oracle": fakeQuerier}}
+	merged := base.Merge(&QueryPlugins{CustomQueriers: map[string]CustomQuerier{"other": fakeQuerier}})
+	assert.Len(t, merged.CustomQueriers, 2)
+	assert.Len(t, base.CustomQueriers, 1)
+}
+
+func TestEnvQuerier(t *testing.T) {
+	blockTime := time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)
+	ctx := encodingTestContext().
+		WithBlockHeight(1234).
+		WithBlockTime(blockTime).
+		WithChainID("secret-4").
+		WithGasMeter(sdk.NewGasMeter(1000000))
+
+	q := QueryHandler{Ctx: ctx, Plugins: QueryPlugins{Env: EnvQuerier}}
+	bz, err := q.Query(wasmTypes.QueryRequest{Env: &wasmTypes.EnvQuery{}}, 1000000*types.GasMultiplier)
+	require.NoError(t, err)
+	var res wasmTypes.BlockInfo
+	require.NoError(t, json.Unmarshal(bz, &res))
+	assert.Equal(t, wasmTypes.BlockInfo{Height: 1234, Time: uint64(blockTime.Unix()), ChainID: "secret-4"}, res)
+	// the same values are injected into the env of executions
+	assert.Equal(t, types.NewEnv(ctx, nil, nil, nil, nil).Block, res)
+
+	// time is in whole seconds, sub-second precision is dropped
+	ctx = ctx.WithBlockTime(time.Unix(1654086600, 999999999))
+	q = QueryHandler{Ctx: ctx, Plugins: QueryPlugins{Env: EnvQuerier}}
+	bz, err = q.Query(wasmTypes.QueryRequest{Env: &wasmTypes.EnvQuery{}}, 1000000*types.GasMultiplier)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bz, &res))
+	assert.Equal(t, uint64(1654086600), res.Time)
+}
+
+func TestQueryHandlerChargesResultSize(t *testing.T) {
+	query := func(result []byte) uint64 {
+		ctx := encodingTestContext().WithGasMeter(sdk.NewGasMeter(1000000))
+		q := QueryHandler{
+			Ctx: ctx,
+			Plugins: QueryPlugins{
+				Bank: func(sdk.Context, *wasmTypes.BankQuery) ([]byte, error) {
+					return result, nil
+				},
+			},
+		}
+		res, err := q.Query(wasmTypes.QueryRequest{Bank: &wasmTypes.BankQuery{}}, 1000000*types.GasMultiplier)
+		require.NoError(t, err)
+		assert.Equal(t, result, res)
+		return q.GasConsumed()
+	}
+
+	small := query([]byte(`{"amount":[]}`))
+	large := query([]byte(`{"amount":[` + strings.Repeat(`{"denom":"uscrt","amount":"1"},`, 100) + `]}`))
+	assert.Greater(t, large, small)
+}
+
+func TestQueryHandlerGasLimitsEveryPlugin(t *testing.T) {
+	const gasLimit = 5000
+	var limits []sdk.Gas
+	record := func(ctx sdk.Context) ([]byte, error) {
+		limits = append(limits, ctx.GasMeter().Limit())
+		return []byte(`{}`), nil
+	}
+	q := QueryHandler{
+		Ctx: encodingTestContext().WithGasMeter(sdk.NewGasMeter(1000000)),
+		Plugins: QueryPlugins{
+			Bank:    func(ctx sdk.Context, _ *wasmTypes.BankQuery) ([]byte, error) { return record(ctx) },
+			Staking: func(ctx sdk.Context, _ *wasmTypes.StakingQuery) ([]byte, error) { return record(ctx) },
+			Wasm:    func(ctx sdk.Context, _ *wasmTypes.WasmQuery) ([]byte, error) { return record(ctx) },
+			Dist:    func(ctx sdk.Context, _ *wasmTypes.DistQuery) ([]byte, error) { return record(ctx) },
+			Mint:    func(ctx sdk.Context, _ *wasmTypes.MintQuery) ([]byte, error) { return record(ctx) },
+			Gov:     func(ctx sdk.Context, _ *wasmTypes.GovQuery) ([]byte, error) { return record(ctx) },
+			Env:     func(ctx sdk.Context, _ *wasmTypes.EnvQuery) ([]byte, error) { return record(ctx) },
+		},
+	}
+
+	requests := []wasmTypes.QueryRequest{
+		{Bank: &wasmTypes.BankQuery{}},
+		{Staking: &wasmTypes.StakingQuery{}},
+		{Wasm: &wasmTypes.WasmQuery{}},
+		{Dist: &wasmTypes.DistQuery{}},
+		{Mint: &wasmTypes.MintQuery{}},
+		{Gov: &wasmTypes.GovQuery{}},
+		{Env: &wasmTypes.EnvQuery{}},
+	}
+	for _, request := range requests {
+		_, err := q.Query(request, gasLimit*types.GasMultiplier)
+		require.NoError(t, err)
+	}
+	require.Len(t, limits, len(requests))
+	for i, limit := range limits {
+		assert.Equal(t, sdk.Gas(gasLimit), limit, "request %d", i)
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	crypto "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 
 	cosmwasm "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
 	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
@@ -1570,6 +1571,183 @@ func TestContractSendFundsToExecCallback(t *testing.T) {
 	require.Equal(t, "199983denom", walletCointsAfter.String())
 }
 
+// TestContractSendsEveryMsg sends each variant of CosmosMsg from a contract, so it goes through the enclave and the
+// encoders. The variants of the modules the test app doesn't route should only fail when they are routed.
+func TestContractSendsEveryMsg(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// msg returns the message the contract sends
+		msg func(ctx sdk.Context, keeper Keeper, codeID uint64, codeHash string, addr sdk.AccAddress, walletA sdk.AccAddress, walletB sdk.AccAddress) string
+		// check checks the state after the message, if it succeeds
+		check  func(t *testing.T, ctx sdk.Context, keeper Keeper, codeID uint64, codeHash string, addr sdk.AccAddress, walletA sdk.AccAddress, walletB sdk.AccAddress)
+		expErr string
+	}{
+		{
+			name: "bank send with a deadline",
+			msg: func(ctx sdk.Context, _ Keeper, _ uint64, _ string, addr, walletA, _ sdk.AccAddress) string {
+				return fmt.Sprintf(`{"bank":{"send":{"from_address":"%s","to_address":"%s","amount":[{"denom":"denom","amount":"17"}],"deadline_height":%d}}}`, addr, walletA, ctx.BlockHeight()+10)
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, _ uint64, _ string, addr, walletA, _ sdk.AccAddress) {
+				require.Equal(t, "983denom", keeper.bankKeeper.GetAllBalances(ctx, addr).String())
+				require.Equal(t, "199017denom", keeper.bankKeeper.GetAllBalances(ctx, walletA).String())
+			},
+		},
+		{
+			name: "bank burn",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, _, _ sdk.AccAddress) string {
+				return `{"bank":{"burn":{"amount":[{"denom":"denom","amount":"17"}]}}}`
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, _ uint64, _ string, addr, _, _ sdk.AccAddress) {
+				require.Equal(t, "983denom", keeper.bankKeeper.GetAllBalances(ctx, addr).String())
+			},
+		},
+		{
+			name: "bank multi send",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, addr, walletA, walletB sdk.AccAddress) string {
+				return fmt.Sprintf(`{"bank":{"multi_send":{"inputs":[{"address":"%s","coins":[{"denom":"denom","amount":"30"}]}],"outputs":[{"address":"%s","coins":[{"denom":"denom","amount":"10"}]},{"address":"%s","coins":[{"denom":"denom","amount":"20"}]}]}}}`, addr, walletA, walletB)
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, _ uint64, _ string, addr, walletA, walletB sdk.AccAddress) {
+				require.Equal(t, "970denom", keeper.bankKeeper.GetAllBalances(ctx, addr).String())
+				require.Equal(t, "199010denom", keeper.bankKeeper.GetAllBalances(ctx, walletA).String())
+				require.Equal(t, "5020denom", keeper.bankKeeper.GetAllBalances(ctx, walletB).String())
+			},
+		},
+		{
+			name: "gov submit proposal",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, _, _ sdk.AccAddress) string {
+				return `{"gov":{"submit_proposal":{"title":"title","description":"description","initial_deposit":[{"denom":"denom","amount":"17"}]}}}`
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, _ uint64, _ string, addr, _, _ sdk.AccAddress) {
+				require.Equal(t, "983denom", keeper.bankKeeper.GetAllBalances(ctx, addr).String())
+			},
+		},
+		{
+			name: "distribution set withdraw address",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, walletA, _ sdk.AccAddress) string {
+				return fmt.Sprintf(`{"distribution":{"set_withdraw_address":{"address":"%s"}}}`, walletA)
+			},
+		},
+		{
+			name: "distribution fund community pool",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, _, _ sdk.AccAddress) string {
+				return `{"distribution":{"fund_community_pool":{"amount":[{"denom":"denom","amount":"17"}]}}}`
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, _ uint64, _ string, addr, _, _ sdk.AccAddress) {
+				require.Equal(t, "983denom", keeper.bankKeeper.GetAllBalances(ctx, addr).String())
+			},
+		},
+		{
+			name: "stargate",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, addr, walletA, _ sdk.AccAddress) string {
+				send := banktypes.MsgSend{
+					FromAddress: addr.String(),
+					ToAddress:   walletA.String(),
+					Amount:      sdk.NewCoins(sdk.NewInt64Coin("denom", 17)),
+				}
+				bz, err := send.Marshal()
+				if err != nil {
+					panic(err)
+				}
+				return fmt.Sprintf(`{"stargate":{"type_url":"%s","value":"%s"}}`, sdk.MsgTypeURL(&send), base64.StdEncoding.EncodeToString(bz))
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, _ uint64, _ string, addr, walletA, _ sdk.AccAddress) {
+				require.Equal(t, "983denom", keeper.bankKeeper.GetAllBalances(ctx, addr).String())
+				require.Equal(t, "199017denom", keeper.bankKeeper.GetAllBalances(ctx, walletA).String())
+			},
+		},
+		{
+			name: "wasm execute with a gas limit",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, codeHash string, addr, _, _ sdk.AccAddress) string {
+				return fmt.Sprintf(`{"wasm":{"execute":{"contract_addr":"%s","callback_code_hash":"%s","msg":"%s","send":[],"gas_limit":200000}}}`, addr, codeHash, base64.StdEncoding.EncodeToString([]byte(`{"no_data":{}}`)))
+			},
+		},
+		{
+			name: "wasm store code",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, _, _ sdk.AccAddress) string {
+				wasmCode, err := ioutil.ReadFile("./testdata/plaintext_logs.wasm")
+				if err != nil {
+					panic(err)
+				}
+				return fmt.Sprintf(`{"wasm":{"store_code":{"wasm_bytes":"%s"}}}`, base64.StdEncoding.EncodeToString(wasmCode))
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, codeID uint64, _ string, addr, _, _ sdk.AccAddress) {
+				codeInfo := keeper.GetCodeInfo(ctx, codeID+1)
+				require.NotNil(t, codeInfo)
+				require.Equal(t, addr, codeInfo.Creator)
+			},
+		},
+		{
+			name: "wasm instantiate2",
+			msg: func(_ sdk.Context, _ Keeper, codeID uint64, codeHash string, _, _, _ sdk.AccAddress) string {
+				return fmt.Sprintf(`{"wasm":{"instantiate2":{"code_id":%d,"callback_code_hash":"%s","msg":"%s","send":[],"label":"instantiate2","salt":"%s"}}}`, codeID, codeHash, base64.StdEncoding.EncodeToString([]byte(`{"nop":{}}`)), base64.StdEncoding.EncodeToString([]byte("salt")))
+			},
+			check: func(t *testing.T, ctx sdk.Context, keeper Keeper, codeID uint64, _ string, addr, _, _ sdk.AccAddress) {
+				expected := PredictableContractAddress(keeper.GetCodeInfo(ctx, codeID).CodeHash, addr, []byte("salt"))
+				require.Equal(t, expected, keeper.GetContractAddress(ctx, "instantiate2"))
+			},
+		},
+		{
+			name: "ibc transfer",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, walletA, _ sdk.AccAddress) string {
+				return fmt.Sprintf(`{"ibc":{"transfer":{"channel_id":"channel-0","to_address":"%s","amount":{"denom":"denom","amount":"17"},"timeout":{"relative_blocks":100}}}}`, walletA)
+			},
+			expErr: "unrecognized message route",
+		},
+		{
+			name: "vesting create vesting account",
+			msg: func(ctx sdk.Context, _ Keeper, _ uint64, _ string, _, _, _ sdk.AccAddress) string {
+				to := sdk.AccAddress(append(make([]byte, 19), 1))
+				return fmt.Sprintf(`{"vesting":{"create_vesting_account":{"to":"%s","amount":[{"denom":"denom","amount":"17"}],"end_time":%d,"delayed":true}}}`, to, ctx.BlockTime().Add(time.Hour).Unix())
+			},
+			expErr: "unrecognized message route",
+		},
+		{
+			name: "feegrant grant allowance",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, walletA, _ sdk.AccAddress) string {
+				return fmt.Sprintf(`{"feegrant":{"grant_allowance":{"grantee":"%s","allowance":{"basic":{"spend_limit":[{"denom":"denom","amount":"17"}]}}}}}`, walletA)
+			},
+		},
+		{
+			name: "authz exec",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, _, walletA, walletB sdk.AccAddress) string {
+				return fmt.Sprintf(`{"authz":{"exec":{"granter":"%s","msgs":[{"bank":{"send":{"from_address":"%s","to_address":"%s","amount":[{"denom":"denom","amount":"17"}]}}}]}}}`, walletA, walletA, walletB)
+			},
+			expErr: "authorization not found",
+		},
+		{
+			name: "slashing unjail",
+			msg: func(_ sdk.Context, _ Keeper, _ uint64, _ string, addr, _, _ sdk.AccAddress) string {
+				return fmt.Sprintf(`{"slashing":{"unjail":{"validator":"%s"}}}`, sdk.ValAddress(addr))
+			},
+			expErr: "unrecognized message route",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, keeper, codeID, codeHash, walletA, privKeyA, walletB, _ := setupTest(t, "./testdata/test-contract/contract.wasm")
+
+			addr, _, initErr := initHelper(t, keeper, ctx, codeID, walletA, privKeyA, `{"nop":{}}`, true, defaultGasForTests)
+			require.Empty(t, initErr)
+
+			_, _, _, execErr := execHelper(t, keeper, ctx, addr, walletA, privKeyA, `{"deposit_to_contract":{}}`, false, defaultGasForTests, 1000)
+			require.Empty(t, execErr)
+
+			msg := tc.msg(ctx, keeper, codeID, codeHash, addr, walletA, walletB)
+			_, _, _, execErr = execHelper(t, keeper, ctx, addr, walletA, privKeyA, fmt.Sprintf(`{"send_msgs":{"msgs":[%s]}}`, msg), false, 10_000_000, 0)
+
+			if tc.expErr != "" {
+				// the enclave passed the message on, or it would have failed to parse it
+				require.NotNil(t, execErr.GenericErr)
+				require.Contains(t, execErr.GenericErr.Msg, tc.expErr)
+				return
+			}
+			require.Empty(t, execErr)
+			if tc.check != nil {
+				tc.check(t, ctx, keeper, codeID, codeHash, addr, walletA, walletB)
+			}
+		})
+	}
+}
+
 func TestContractSendFundsToExecCallbackNotEnough(t *testing.T) {
 	ctx, keeper, codeID, codeHash, walletA, privKeyA, _, _ := setupTest(t, "./testdata/test-contract/contract.wasm")
 
@@ -2771,3 +2949,190 @@ func TestBenchmarkEd25519BatchVerifyAPI(t *testing.T) {
 	elapsed := time.Since(start)
 	fmt.Printf("TestBenchmarkEd25519BatchVerifyAPI took %s\n", elapsed)
 }
+
+func TestContractReceivesReply(t *testing.T) {
+	ctx, keeper, codeID, codeHash, walletA, privKeyA, _, _ := setupTest(t, "./testdata/test-contract/contract.wasm")
+
+	contractAddress, _, initErr := initHelper(t, keeper, ctx, codeID, walletA, privKeyA, `{"nop":{}}`, true, defaultGasForTests)
+	require.Empty(t, initErr)
+
+	// the submessage executes the contract itself, so its data comes back encrypted
+	inner := base64.StdEncoding.EncodeToString([]byte(`{"unicode_data":{}}`))
+	msg := fmt.Sprintf(`{"send_submsgs":{"submsgs":[{"id":1,"msg":{"wasm":{"execute":{"contract_addr":"%s","callback_code_hash":"%s","msg":"%s","send":[]}}},"reply_on":"always"}]}}`, contractAddress, codeHash, inner)
+
+	data, events, _, err := execHelper(t, keeper, ctx, contractAddress, walletA, privKeyA, msg, true, defaultGasForTests, 0)
+	require.Empty(t, err)
+	require.Equal(t, "reply 1", string(data))
+	require.Equal(t,
+		[]ContractEvent{
+			{
+				{Key: "contract_address", Value: contractAddress.String()},
+			},
+			{
+				{Key: "contract_address", Value: contractAddress.String()},
+			},
+			{
+				{Key: "contract_address", Value: contractAddress.String()},
+				{Key: "reply", Value: "1"},
+				{Key: "result", Value: "ok"},
+			},
+		},
+		events,
+	)
+
+	res, qErr := queryHelper(t, keeper, ctx, contractAddress, `{"last_reply":{}}`, true, defaultGasForTests)
+	require.Empty(t, qErr)
+
+	var reply cosmwasm.Reply
+	require.NoError(t, json.Unmarshal([]byte(res), &reply))
+	require.Equal(t, uint64(1), reply.ID)
+	require.NotNil(t, reply.Result.Ok)
+	// the enclave decrypts the submessage data before handing it to the contract
+	require.Equal(t, "🍆🥑🍄", string(reply.Result.Ok.Data))
+}
+
+// selfSubmsg builds a submessage that executes msg on the contract itself
+func selfSubmsg(id uint64, contractAddress sdk.AccAddress, codeHash string, msg string, replyOn string) string {
+	return fmt.Sprintf(
+		`{"id":%d,"msg":{"wasm":{"execute":{"contract_addr":"%s","callback_code_hash":"%s","msg":"%s","send":[]}}},"reply_on":"%s"}`,
+		id, contractAddress, codeHash, base64.StdEncoding.EncodeToString([]byte(msg)), replyOn,
+	)
+}
+
+func lastReply(t *testing.T, keeper Keeper, ctx sdk.Context, contractAddress sdk.AccAddress) *cosmwasm.Reply {
+	res, qErr := queryHelper(t, keeper, ctx, contractAddress, `{"last_reply":{}}`, true, defaultGasForTests)
+	require.Empty(t, qErr)
+	if res == "" {
+		return nil
+	}
+
+	var reply cosmwasm.Reply
+	require.NoError(t, json.Unmarshal([]byte(res), &reply))
+	return &reply
+}
+
+func TestSubmessageReplyOn(t *testing.T) {
+	const (
+		succeeds = `{"unicode_data":{}}`
+		fails    = `{"contract_error":{"error_type":"generic_err"}}`
+	)
+
+	for _, test := range []struct {
+		description string
+		replyOn     string
+		inner       string
+		// the reply the contract receives, if any
+		replied   bool
+		succeeded bool
+		// the execution fails with the error of the submessage
+		aborted bool
+	}{
+		{description: "always, submessage succeeds", replyOn: "always", inner: succeeds, replied: true, succeeded: true},
+		{description: "always, submessage fails", replyOn: "always", inner: fails, replied: true},
+		{description: "success, submessage succeeds", replyOn: "success", inner: succeeds, replied: true, succeeded: true},
+		{description: "success, submessage fails", replyOn: "success", inner: fails, aborted: true},
+		{description: "error, submessage succeeds", replyOn: "error", inner: succeeds},
+		{description: "error, submessage fails", replyOn: "error", inner: fails, replied: true},
+		{description: "never, submessage succeeds", replyOn: "never", inner: succeeds},
+		{description: "never, submessage fails", replyOn: "never", inner: fails, aborted: true},
+	} {
+		t.Run(test.description, func(t *testing.T) {
+			ctx, keeper, codeID, codeHash, walletA, privKeyA, _, _ := setupTest(t, "./testdata/test-contract/contract.wasm")
+
+			contractAddress, _, initErr := initHelper(t, keeper, ctx, codeID, walletA, privKeyA, `{"nop":{}}`, true, defaultGasForTests)
+			require.Empty(t, initErr)
+
+			msg := fmt.Sprintf(`{"send_submsgs":{"submsgs":[%s]}}`, selfSubmsg(1, contractAddress, codeHash, test.inner, test.replyOn))
+			data, _, _, err := execHelper(t, keeper, ctx, contractAddress, walletA, privKeyA, msg, true, defaultGasForTests, 0)
+
+			if test.aborted {
+				require.NotNil(t, err.GenericErr)
+				require.Equal(t, "la la 🤯", err.GenericErr.Msg)
+				require.Nil(t, lastReply(t, keeper, ctx, contractAddress))
+				return
+			}
+			require.Empty(t, err)
+
+			reply := lastReply(t, keeper, ctx, contractAddress)
+			if !test.replied {
+				require.Empty(t, data)
+				require.Nil(t, reply)
+				return
+			}
+
+			// the data of the reply overrides the data of the execution
+			require.Equal(t, "reply 1", string(data))
+			require.NotNil(t, reply)
+			require.Equal(t, uint64(1), reply.ID)
+			if test.succeeded {
+				require.NotNil(t, reply.Result.Ok)
+				require.Equal(t, "🍆🥑🍄", string(reply.Result.Ok.Data))
+			} else {
+				require.Nil(t, reply.Result.Ok)
+				require.NotEmpty(t, reply.Result.Err)
+			}
+		})
+	}
+}
+
+func TestSubmessageGasLimit(t *testing.T) {
+	ctx, keeper, codeID, codeHash, walletA, privKeyA, _, _ := setupTest(t, "./testdata/test-contract/contract.wasm")
+
+	contractAddress, _, initErr := initHelper(t, keeper, ctx, codeID, walletA, privKeyA, `{"nop":{}}`, true, defaultGasForTests)
+	require.Empty(t, initErr)
+
+	// the submessage runs out of its own gas, which only fails the submessage
+	msg := fmt.Sprintf(
+		`{"send_submsgs":{"submsgs":[{"id":1,"msg":{"wasm":{"execute":{"contract_addr":"%s","callback_code_hash":"%s","msg":"%s","send":[]}}},"gas_limit":30000,"reply_on":"error"}]}}`,
+		contractAddress, codeHash, base64.StdEncoding.EncodeToString([]byte(`{"burn_gas":{}}`)),
+	)
+	data, _, gasUsed, err := execHelper(t, keeper, ctx, contractAddress, walletA, privKeyA, msg, true, 200_000, 0)
+	require.Empty(t, err)
+	require.Equal(t, "reply 1", string(data))
+	// all the gas of the submessage is charged to the execution
+	require.GreaterOrEqual(t, gasUsed, uint64(30_000))
+
+	reply := lastReply(t, keeper, ctx, contractAddress)
+	require.NotNil(t, reply)
+	require.Equal(t, uint64(1), reply.ID)
+	require.Nil(t, reply.Result.Ok)
+	require.NotEmpty(t, reply.Result.Err)
+}
+
+func TestSubmessageFailingReply(t *testing.T) {
+	ctx, keeper, codeID, codeHash, walletA, privKeyA, _, _ := setupTest(t, "./testdata/test-contract/contract.wasm")
+
+	contractAddress, _, initErr := initHelper(t, keeper, ctx, codeID, walletA, privKeyA, `{"nop":{}}`, true, defaultGasForTests)
+	require.Empty(t, initErr)
+
+	// the contract fails the reply to submessage 666, which fails the execution
+	submsg := selfSubmsg(666, contractAddress, codeHash, `{"unicode_data":{}}`, "always")
+	_, _, _, err := execHelper(t, keeper, ctx, contractAddress, walletA, privKeyA, fmt.Sprintf(`{"send_submsgs":{"submsgs":[%s]}}`, submsg), true, defaultGasForTests, 0)
+	require.NotNil(t, err.GenericErr)
+	require.Equal(t, "reply failed", err.GenericErr.Msg)
+}
+
+func TestSubmessageStateIsRevertedOnError(t *testing.T) {
+	ctx, keeper, codeID, codeHash, walletA, privKeyA, _, _ := setupTest(t, "./testdata/test-contract/contract.wasm")
+
+	contractAddress, _, initErr := initHelper(t, keeper, ctx, codeID, walletA, privKeyA, `{"nop":{}}`, true, defaultGasForTests)
+	require.Empty(t, initErr)
+
+	// the submessage sets the state in a submessage of its own, and then fails
+	inner := fmt.Sprintf(`{"send_submsgs":{"submsgs":[%s,%s]}}`,
+		selfSubmsg(2, contractAddress, codeHash, `{"set_state":{"key":"banana","value":"🍌"}}`, "never"),
+		selfSubmsg(3, contractAddress, codeHash, `{"contract_error":{"error_type":"generic_err"}}`, "never"),
+	)
+	msg := fmt.Sprintf(`{"send_submsgs":{"submsgs":[%s]}}`, selfSubmsg(1, contractAddress, codeHash, inner, "error"))
+	data, _, _, err := execHelper(t, keeper, ctx, contractAddress, walletA, privKeyA, msg, true, 200_000, 0)
+	require.Empty(t, err)
+	require.Equal(t, "reply 1", string(data))
+
+	reply := lastReply(t, keeper, ctx, contractAddress)
+	require.NotNil(t, reply)
+	require.NotEmpty(t, reply.Result.Err)
+
+	data, _, _, err = execHelper(t, keeper, ctx, contractAddress, walletA, privKeyA, `{"get_state":{"key":"banana"}}`, true, defaultGasForTests, 0)
+	require.Empty(t, err)
+	require.Empty(t, data)
+}
@@ -0,0 +1,55 @@
+package keeper
+
+import (
+	"encoding/json"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	wasmTypes "github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+// snip721TransferMsg is the execute message of a SNIP-721 token contract that transfers one token
+type snip721TransferMsg struct {
+	TransferNft snip721Transfer `json:"transfer_nft"`
+}
+
+type snip721Transfer struct {
+	Recipient string `json:"recipient"`
+	TokenID   string `json:"token_id"`
+	Memo      string `json:"memo,omitempty"`
+}
+
+// NewSnip721TransferMsg returns the message that transfers the token tokenID of the SNIP-721 contract at
+// contractAddr, which has the code hash codeHash, to recipient. The memo is optional.
+func NewSnip721TransferMsg(contractAddr string, codeHash string, recipient string, tokenID string, memo string) (wasmTypes.CosmosMsg, error) {
+	if _, err := sdk.AccAddressFromBech32(contractAddr); err != nil {
+		return wasmTypes.CosmosMsg{}, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, contractAddr)
+	}
+	if _, err := sdk.AccAddressFromBech32(recipient); err != nil {
+		return wasmTypes.CosmosMsg{}, sdkerrors.Wrap(types.ErrInvalidRecipient, recipient)
+	}
+	if tokenID == "" {
+		return wasmTypes.CosmosMsg{}, sdkerrors.Wrap(types.ErrEmpty, "token id")
+	}
+	msg, err := json.Marshal(snip721TransferMsg{
+		TransferNft: snip721Transfer{
+			Recipient: recipient,
+			TokenID:   tokenID,
+			Memo:      memo,
+		},
+	})
+	if err != nil {
+		return wasmTypes.CosmosMsg{}, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return wasmTypes.CosmosMsg{
+		Wasm: &wasmTypes.WasmMsg{
+			Execute: &wasmTypes.ExecuteMsg{
+				ContractAddr:     contractAddr,
+				CallbackCodeHash: codeHash,
+				Msg:              msg,
+			},
+		},
+	}, nil
+}
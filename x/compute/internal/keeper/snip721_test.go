@@ -0,0 +1,41 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/x/compute/internal/types"
+)
+
+func TestNewSnip721TransferMsg(t *testing.T) {
+	_, _, contractAddr := keyPubAddr()
+	_, _, rcpt := keyPubAddr()
+	codeHash := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	msg, err := NewSnip721TransferMsg(contractAddr.String(), codeHash, rcpt.String(), "token-1", "")
+	require.NoError(t, err)
+	require.NotNil(t, msg.Wasm)
+	require.NotNil(t, msg.Wasm.Execute)
+	assert.Equal(t, contractAddr.String(), msg.Wasm.Execute.ContractAddr)
+	assert.Equal(t, codeHash, msg.Wasm.Execute.CallbackCodeHash)
+	assert.Empty(t, msg.Wasm.Execute.Send)
+	assert.JSONEq(t, `{"transfer_nft":{"recipient":"`+rcpt.String()+`","token_id":"token-1"}}`, string(msg.Wasm.Execute.Msg))
+
+	msg, err = NewSnip721TransferMsg(contractAddr.String(), codeHash, rcpt.String(), "token-1", "gift")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"transfer_nft":{"recipient":"`+rcpt.String()+`","token_id":"token-1","memo":"gift"}}`, string(msg.Wasm.Execute.Msg))
+
+	// the message passes the encoder
+	encoder := NewMessageHandler(nil, nil, MakeEncodingConfig().InterfaceRegistry).encoders
+	_, err = encoder.Encode(encodingTestContext(), rcpt, msg)
+	require.NoError(t, err)
+
+	_, err = NewSnip721TransferMsg("invalid", codeHash, rcpt.String(), "token-1", "")
+	require.Error(t, err)
+	_, err = NewSnip721TransferMsg(contractAddr.String(), codeHash, "invalid", "token-1", "")
+	assert.True(t, types.ErrInvalidRecipient.Is(err), err)
+	_, err = NewSnip721TransferMsg(contractAddr.String(), codeHash, rcpt.String(), "", "")
+	assert.True(t, types.ErrEmpty.Is(err), err)
+}
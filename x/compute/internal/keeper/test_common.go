@@ -37,6 +37,10 @@ import (
 	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	authzkeeper "github.com/cosmos/cosmos-sdk/x/authz/keeper"
+	authzmodule "github.com/cosmos/cosmos-sdk/x/authz/module"
+
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	bankkeeper "github.com/cosmos/cosmos-sdk/x/bank/keeper"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
@@ -53,6 +57,9 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/x/evidence"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	feegrantkeeper "github.com/cosmos/cosmos-sdk/x/feegrant/keeper"
+	feegrantmodule "github.com/cosmos/cosmos-sdk/x/feegrant/module"
 	"github.com/cosmos/cosmos-sdk/x/gov"
 	govkeeper "github.com/cosmos/cosmos-sdk/x/gov/keeper"
 	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
@@ -86,6 +93,7 @@ const flagQueryGasLimit = "query_gas_limit"
 
 var ModuleBasics = module.NewBasicManager(
 	auth.AppModuleBasic{},
+	authzmodule.AppModuleBasic{},
 	bank.AppModuleBasic{},
 	capability.AppModuleBasic{},
 	staking.AppModuleBasic{},
@@ -100,6 +108,7 @@ var ModuleBasics = module.NewBasicManager(
 	//ibc.AppModuleBasic{},
 	upgrade.AppModuleBasic{},
 	evidence.AppModuleBasic{},
+	feegrantmodule.AppModuleBasic{},
 	//transfer.AppModuleBasic{},
 	registration.AppModuleBasic{},
 )
@@ -137,13 +146,15 @@ var TestingStakeParams = stakingtypes.Params{
 }
 
 type TestKeepers struct {
-	AccountKeeper authkeeper.AccountKeeper
-	StakingKeeper stakingkeeper.Keeper
-	WasmKeeper    Keeper
-	DistKeeper    distrkeeper.Keeper
-	GovKeeper     govkeeper.Keeper
-	BankKeeper    bankkeeper.Keeper
-	MintKeeper    mintkeeper.Keeper
+	AccountKeeper  authkeeper.AccountKeeper
+	StakingKeeper  stakingkeeper.Keeper
+	WasmKeeper     Keeper
+	DistKeeper     distrkeeper.Keeper
+	GovKeeper      govkeeper.Keeper
+	BankKeeper     bankkeeper.Keeper
+	MintKeeper     mintkeeper.Keeper
+	AuthzKeeper    authzkeeper.Keeper
+	FeegrantKeeper feegrantkeeper.Keeper
 }
 
 var TestConfig = TestConfigType{
@@ -167,8 +178,11 @@ func CreateTestInput(t *testing.T, isCheckTx bool, supportedFeatures string, enc
 	mintStore := sdk.NewKVStoreKey(minttypes.StoreKey)
 	keyParams := sdk.NewKVStoreKey(paramstypes.StoreKey)
 	tkeyParams := sdk.NewTransientStoreKey(paramstypes.TStoreKey)
+	tkeyContract := sdk.NewTransientStoreKey(wasmtypes.TStoreKey)
 	keyGov := sdk.NewKVStoreKey(govtypes.StoreKey)
 	keyBank := sdk.NewKVStoreKey(banktypes.StoreKey)
+	keyAuthz := sdk.NewKVStoreKey(authzkeeper.StoreKey)
+	keyFeegrant := sdk.NewKVStoreKey(feegrant.StoreKey)
 
 	db := dbm.NewMemDB()
 	ms := store.NewCommitMultiStore(db)
@@ -179,8 +193,11 @@ func CreateTestInput(t *testing.T, isCheckTx bool, supportedFeatures string, enc
 	ms.MountStoreWithDB(mintStore, sdk.StoreTypeIAVL, db)
 	ms.MountStoreWithDB(keyDistro, sdk.StoreTypeIAVL, db)
 	ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
+	ms.MountStoreWithDB(tkeyContract, sdk.StoreTypeTransient, db)
 	ms.MountStoreWithDB(keyGov, sdk.StoreTypeIAVL, db)
 	ms.MountStoreWithDB(keyBank, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyAuthz, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyFeegrant, sdk.StoreTypeIAVL, db)
 	require.NoError(t, ms.LoadLatestVersion())
 
 	ctx := sdk.NewContext(ms, tmproto.Header{
@@ -197,6 +214,7 @@ func CreateTestInput(t *testing.T, isCheckTx bool, supportedFeatures string, enc
 	paramsKeeper.Subspace(distrtypes.ModuleName)
 	paramsKeeper.Subspace(slashingtypes.ModuleName)
 	paramsKeeper.Subspace(crisistypes.ModuleName)
+	paramsKeeper.Subspace(wasmtypes.ModuleName)
 
 	// this is also used to initialize module accounts (so nil is meaningful here)
 	maccPerms := map[string][]string{
@@ -207,6 +225,7 @@ func CreateTestInput(t *testing.T, isCheckTx bool, supportedFeatures string, enc
 		stakingtypes.BondedPoolName:    {authtypes.Burner, authtypes.Staking},
 		stakingtypes.NotBondedPoolName: {authtypes.Burner, authtypes.Staking},
 		govtypes.ModuleName:            {authtypes.Burner},
+		wasmtypes.ModuleName:           {authtypes.Burner},
 	}
 	authSubsp, _ := paramsKeeper.GetSubspace(authtypes.ModuleName)
 	authKeeper := authkeeper.NewAccountKeeper(
@@ -331,30 +350,29 @@ func CreateTestInput(t *testing.T, isCheckTx bool, supportedFeatures string, enc
 	// Load default wasm config
 	wasmConfig := wasmtypes.DefaultWasmConfig()
 
-	// todo: new grpc routing
-	//serviceRouter := baseapp.NewMsgServiceRouter()
-
-	//serviceRouter.SetInterfaceRegistry(encodingConfig.InterfaceRegistry)
-	//bankMsgServer := bankkeeper.NewMsgServerImpl(bankKeeper)
-	//stakingMsgServer := stakingkeeper.NewMsgServerImpl(stakingKeeper)
-	//distrMsgServer := distrkeeper.NewMsgServerImpl(distKeeper)
-	//wasmMsgServer := NewMsgServerImpl(keeper)
-
-	//banktypes.RegisterMsgServer(serviceRouter, bankMsgServer)
-	//stakingtypes.RegisterMsgServer(serviceRouter, stakingMsgServer)
-	//distrtypes.RegisterMsgServer(serviceRouter, distrMsgServer)
+	// the modules without a legacy route are reached through their Msg service
+	serviceRouter := baseapp.NewMsgServiceRouter()
+	serviceRouter.SetInterfaceRegistry(encodingConfig.InterfaceRegistry)
+	banktypes.RegisterMsgServer(serviceRouter, bankkeeper.NewMsgServerImpl(bankKeeper))
+	authzKeeper := authzkeeper.NewKeeper(keyAuthz, encodingConfig.Marshaler, serviceRouter)
+	authz.RegisterMsgServer(serviceRouter, authzKeeper)
+	feegrantKeeper := feegrantkeeper.NewKeeper(encodingConfig.Marshaler, keyFeegrant, authKeeper)
+	feegrant.RegisterMsgServer(serviceRouter, feegrantkeeper.NewMsgServerImpl(feegrantKeeper))
 
+	computeSubsp, _ := paramsKeeper.GetSubspace(wasmtypes.ModuleName)
 	keeper := NewKeeper(
 		encodingConfig.Marshaler,
 		*encodingConfig.Amino,
 		keyContract,
+		tkeyContract,
+		computeSubsp,
 		authKeeper,
 		bankKeeper,
 		govKeeper,
 		distKeeper,
 		mintKeeper,
 		stakingKeeper,
-		// serviceRouter,
+		serviceRouter,
 		router,
 		tempDir,
 		wasmConfig,
@@ -367,13 +385,15 @@ func CreateTestInput(t *testing.T, isCheckTx bool, supportedFeatures string, enc
 	router.AddRoute(sdk.NewRoute(wasmtypes.RouterKey, TestHandler(keeper)))
 
 	keepers := TestKeepers{
-		AccountKeeper: authKeeper,
-		StakingKeeper: stakingKeeper,
-		DistKeeper:    distKeeper,
-		WasmKeeper:    keeper,
-		GovKeeper:     govKeeper,
-		BankKeeper:    bankKeeper,
-		MintKeeper:    mintKeeper,
+		AccountKeeper:  authKeeper,
+		StakingKeeper:  stakingKeeper,
+		DistKeeper:     distKeeper,
+		WasmKeeper:     keeper,
+		GovKeeper:      govKeeper,
+		BankKeeper:     bankKeeper,
+		MintKeeper:     mintKeeper,
+		AuthzKeeper:    authzKeeper,
+		FeegrantKeeper: feegrantKeeper,
 	}
 
 	return ctx, keepers
@@ -385,6 +405,9 @@ func TestHandler(k Keeper) sdk.Handler {
 		ctx = ctx.WithEventManager(sdk.NewEventManager())
 
 		switch msg := msg.(type) {
+		case *wasmtypes.MsgStoreCode:
+			return handleStoreCode(ctx, k, msg)
+
 		case *wasmtypes.MsgInstantiateContract:
 			return handleInstantiate(ctx, k, msg)
 
@@ -398,6 +421,18 @@ func TestHandler(k Keeper) sdk.Handler {
 	}
 }
 
+func handleStoreCode(ctx sdk.Context, k Keeper, msg *wasmtypes.MsgStoreCode) (*sdk.Result, error) {
+	codeID, err := k.Create(ctx, msg.Sender, msg.WASMByteCode, msg.Source, msg.Builder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdk.Result{
+		Data:   []byte(fmt.Sprintf("%d", codeID)),
+		Events: ctx.EventManager().Events().ToABCIEvents(),
+	}, nil
+}
+
 func handleInstantiate(ctx sdk.Context, k Keeper, msg *wasmtypes.MsgInstantiateContract) (*sdk.Result, error) {
 	contractAddr, err := k.Instantiate(ctx, msg.CodeID, msg.Sender /* msg.Admin, */, msg.InitMsg, msg.Label, msg.InitFunds, msg.CallbackSig)
 	if err != nil {
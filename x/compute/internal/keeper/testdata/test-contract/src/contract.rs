@@ -1,9 +1,10 @@
 use cosmwasm_storage::PrefixedStorage;
 
 use cosmwasm_std::{
-    log, to_binary, Api, BankMsg, Binary, Coin, CosmosMsg, Env, Extern, HandleResponse,
+    log, to_binary, to_vec, Api, BankMsg, Binary, Coin, CosmosMsg, Env, Extern, HandleResponse,
     HandleResult, HumanAddr, InitResponse, InitResult, Querier, QueryRequest, QueryResult,
-    ReadonlyStorage, StdError, StdResult, Storage, Uint128, WasmMsg, WasmQuery,
+    ReadonlyStorage, Reply, StdError, StdResult, Storage, SubMsg, SubcallResult, Uint128, WasmMsg,
+    WasmQuery,
 };
 use secp256k1::Secp256k1;
 
@@ -161,6 +162,13 @@ pub enum HandleMsg {
         to: HumanAddr,
         from: HumanAddr,
     },
+    SendMsgs {
+        msgs: Vec<CosmosMsg>,
+    },
+    SendSubmsgs {
+        submsgs: Vec<SubMsg>,
+    },
+    BurnGas {},
     SendFundsToInitCallback {
         amount: u32,
         denom: String,
@@ -282,6 +290,7 @@ pub enum QueryMsg {
         code_hash: String,
         msg: String,
     },
+    LastReply {},
 }
 
 /////////////////////////////// Init ///////////////////////////////
@@ -295,6 +304,7 @@ pub fn init<S: Storage, A: Api, Q: Querier>(
         InitMsg::Nop {} => Ok(InitResponse {
             messages: vec![],
             log: vec![log("init", "🌈")],
+            submessages: vec![],
         }),
         InitMsg::Callback {
             contract_addr,
@@ -327,6 +337,7 @@ pub fn init<S: Storage, A: Api, Q: Querier>(
                 ),
                 "",
             )],
+            submessages: vec![],
         }),
         InitMsg::SendExternalQueryRecursionLimit {
             to,
@@ -338,6 +349,7 @@ pub fn init<S: Storage, A: Api, Q: Querier>(
                 "message",
                 send_external_query_recursion_limit(deps, to, depth, code_hash)?,
             )],
+            submessages: vec![],
         }),
         InitMsg::CallToInit {
             code_id,
@@ -353,6 +365,7 @@ pub fn init<S: Storage, A: Api, Q: Querier>(
                 label: label,
             })],
             log: vec![log("a", "a")],
+            submessages: vec![],
         }),
         InitMsg::CallToExec {
             addr,
@@ -364,8 +377,10 @@ pub fn init<S: Storage, A: Api, Q: Querier>(
                 callback_code_hash: code_hash,
                 msg: Binary(msg.as_bytes().into()),
                 send: vec![],
+                gas_limit: None,
             })],
             log: vec![log("b", "b")],
+            submessages: vec![],
         }),
         InitMsg::CallToQuery {
             addr,
@@ -386,6 +401,7 @@ pub fn init<S: Storage, A: Api, Q: Querier>(
             Ok(InitResponse {
                 messages: vec![],
                 log: vec![log("c", format!("{}", answer))],
+                submessages: vec![],
             })
         }
     }
@@ -413,8 +429,10 @@ fn init_with_callback_contract_error(contract_addr: HumanAddr, code_hash: String
             callback_code_hash: code_hash,
             msg: Binary::from(r#"{"contract_error":{"error_type":"generic_err"}}"#.as_bytes()),
             send: vec![],
+            gas_limit: None,
         })],
         log: vec![log("init with a callback with contract error", "🤷‍♀️")],
+        submessages: vec![],
     }
 }
 
@@ -425,8 +443,10 @@ fn init_callback_bad_params(contract_addr: HumanAddr, code_hash: String) -> Init
             callback_code_hash: code_hash,
             msg: Binary::from(r#"{"c":{"x":"banana","y":3}}"#.as_bytes().to_vec()),
             send: vec![],
+            gas_limit: None,
         })],
         log: vec![],
+        submessages: vec![],
     }
 }
 
@@ -442,8 +462,10 @@ fn init_with_callback<S: Storage, A: Api, Q: Querier>(
             contract_addr: contract_addr.clone(),
             msg: Binary::from("{\"c\":{\"x\":0,\"y\":13}}".as_bytes().to_vec()),
             send: vec![],
+            gas_limit: None,
         })],
         log: vec![log("init with a callback", "🦄")],
+        submessages: vec![],
     }
 }
 
@@ -462,6 +484,7 @@ pub fn init_callback_to_init<S: Storage, A: Api, Q: Querier>(
             label: String::from("fi"),
         })],
         log: vec![log("instantiating a new contract from init!", "🐙")],
+        submessages: vec![],
     }
 }
 
@@ -516,6 +539,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
             messages: vec![],
             log: vec![],
             data: Some(vec![send_external_query(deps, to, code_hash)].into()),
+            submessages: vec![],
         }),
         HandleMsg::SendExternalQueryDepthCounter {
             to,
@@ -530,6 +554,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 )]
                 .into(),
             ),
+            submessages: vec![],
         }),
         HandleMsg::SendExternalQueryRecursionLimit {
             to,
@@ -541,6 +566,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
             data: Some(to_binary(&send_external_query_recursion_limit(
                 deps, to, depth, code_hash,
             )?)?),
+            submessages: vec![],
         }),
         HandleMsg::SendExternalQueryPanic { to, code_hash } => {
             send_external_query_panic(deps, to, code_hash)
@@ -558,6 +584,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
             messages: vec![],
             log: vec![log("msg.sender", env.message.sender.to_string())],
             data: None,
+            submessages: vec![],
         }),
         HandleMsg::CallbackToLogMsgSender { to, code_hash } => Ok(HandleResponse {
             messages: vec![CosmosMsg::Wasm(WasmMsg::Execute {
@@ -565,14 +592,17 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 callback_code_hash: code_hash,
                 msg: Binary::from(r#"{"log_msg_sender":{}}"#.as_bytes().to_vec()),
                 send: vec![],
+                gas_limit: None,
             })],
             log: vec![log("hi", "hey")],
             data: None,
+            submessages: vec![],
         }),
         HandleMsg::DepositToContract {} => Ok(HandleResponse {
             messages: vec![],
             log: vec![],
             data: Some(to_binary(&env.message.sent_funds).unwrap()),
+            submessages: vec![],
         }),
         HandleMsg::SendFunds {
             amount,
@@ -587,10 +617,27 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                     amount: Uint128(amount as u128),
                     denom: denom,
                 }],
+                deadline_height: None,
             })],
             log: vec![],
             data: None,
+            submessages: vec![],
+        }),
+        HandleMsg::SendMsgs { msgs } => Ok(HandleResponse {
+            messages: msgs,
+            log: vec![],
+            data: None,
+            submessages: vec![],
         }),
+        HandleMsg::SendSubmsgs { submsgs } => Ok(HandleResponse {
+            messages: vec![],
+            log: vec![],
+            data: None,
+            submessages: submsgs,
+        }),
+        HandleMsg::BurnGas {} => loop {
+            deps.storage.set(b"burn", b"gas");
+        },
         HandleMsg::SendFundsToInitCallback {
             amount,
             denom,
@@ -609,6 +656,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
             })],
             log: vec![],
             data: None,
+            submessages: vec![],
         }),
         HandleMsg::SendFundsToExecCallback {
             amount,
@@ -624,9 +672,11 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                     amount: Uint128(amount as u128),
                     denom: denom,
                 }],
+                gas_limit: None,
             })],
             log: vec![],
             data: None,
+            submessages: vec![],
         }),
         HandleMsg::Sleep { ms } => {
             thread::sleep(time::Duration::from_millis(ms));
@@ -635,12 +685,14 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             })
         }
         HandleMsg::WithFloats { x, y } => Ok(HandleResponse {
             messages: vec![],
             log: vec![],
             data: Some(use_floats(x, y)),
+            submessages: vec![],
         }),
         HandleMsg::CallToInit {
             code_id,
@@ -657,6 +709,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
             })],
             log: vec![log("a", "a")],
             data: None,
+            submessages: vec![],
         }),
         HandleMsg::CallToExec {
             addr,
@@ -668,9 +721,11 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 callback_code_hash: code_hash,
                 msg: Binary(msg.as_bytes().into()),
                 send: vec![],
+                gas_limit: None,
             })],
             log: vec![log("b", "b")],
             data: None,
+            submessages: vec![],
         }),
         HandleMsg::CallToQuery {
             addr,
@@ -692,6 +747,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![log("c", format!("{}", answer))],
                 data: None,
+                submessages: vec![],
             })
         }
         HandleMsg::StoreReallyLongKey {} => {
@@ -719,6 +775,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             });
 
             // loop for benchmarking
@@ -732,6 +789,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                         messages: vec![],
                         log: vec![log("result", format!("{}", result))],
                         data: None,
+                        submessages: vec![],
                     }),
                     Err(err) => Err(StdError::generic_err(format!("{:?}", err))),
                 };
@@ -749,6 +807,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             });
 
             // loop for benchmarking
@@ -778,11 +837,13 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                         messages: vec![],
                         log: vec![log("result", "true")],
                         data: None,
+                        submessages: vec![],
                     }),
                     Err(_err) => Ok(HandleResponse {
                         messages: vec![],
                         log: vec![log("result", "false")],
                         data: None,
+                        submessages: vec![],
                     }),
                 };
             }
@@ -799,6 +860,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             });
 
             // loop for benchmarking
@@ -812,6 +874,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                             messages: vec![],
                             log: vec![log("result", format!("{}", result))],
                             data: None,
+                            submessages: vec![],
                         }),
                         Err(err) => Err(StdError::generic_err(format!("{:?}", err))),
                     };
@@ -829,6 +892,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             });
 
             // loop for benchmarking
@@ -852,6 +916,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                         messages: vec![],
                         log: vec![log("result", format!("{}", result))],
                         data: None,
+                        submessages: vec![],
                     }),
                     Err(err) => Err(StdError::generic_err(format!("{:?}", err))),
                 };
@@ -869,6 +934,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             });
 
             // loop for benchmarking
@@ -882,6 +948,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                         messages: vec![],
                         log: vec![log("result", format!("{}", Binary(result).to_base64()))],
                         data: None,
+                        submessages: vec![],
                     }),
                     Err(err) => Err(StdError::generic_err(format!("{:?}", err))),
                 };
@@ -898,6 +965,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             });
 
             // loop for benchmarking
@@ -907,6 +975,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                         messages: vec![],
                         log: vec![log("result", format!("{}", Binary(result).to_base64()))],
                         data: None,
+                        submessages: vec![],
                     }),
                     Err(err) => Err(StdError::generic_err(format!("{:?}", err))),
                 };
@@ -923,6 +992,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                 messages: vec![],
                 log: vec![],
                 data: None,
+                submessages: vec![],
             });
 
             // loop for benchmarking
@@ -932,6 +1002,7 @@ pub fn handle<S: Storage, A: Api, Q: Querier>(
                         messages: vec![],
                         log: vec![log("result", format!("{}", Binary(result).to_base64()))],
                         data: None,
+                        submessages: vec![],
                     }),
                     Err(err) => Err(StdError::generic_err(format!("{:?}", err))),
                 };
@@ -1077,6 +1148,7 @@ fn send_external_query_stderror<S: Storage, A: Api, Q: Querier>(
             messages: vec![],
             log: vec![],
             data: Some(wtf),
+            submessages: vec![],
         }),
         Err(e) => Err(e),
     }
@@ -1102,6 +1174,7 @@ fn send_external_query_bad_abi<S: Storage, A: Api, Q: Querier>(
             messages: vec![],
             log: vec![],
             data: Some(wtf),
+            submessages: vec![],
         }),
         Err(e) => Err(e),
     }
@@ -1125,6 +1198,7 @@ fn send_external_query_bad_abi_receiver<S: Storage, A: Api, Q: Querier>(
             messages: vec![],
             log: vec![log("wtf", wtf)],
             data: None,
+            submessages: vec![],
         }),
         Err(e) => Err(e),
     }
@@ -1137,9 +1211,11 @@ fn exec_callback_bad_params(contract_addr: HumanAddr, code_hash: String) -> Hand
             callback_code_hash: code_hash,
             msg: Binary::from(r#"{"c":{"x":"banana","y":3}}"#.as_bytes().to_vec()),
             send: vec![],
+            gas_limit: None,
         })],
         log: vec![],
         data: None,
+        submessages: vec![],
     }
 }
 
@@ -1165,9 +1241,11 @@ pub fn a<S: Storage, A: Api, Q: Querier>(
                 .as_bytes()
                 .to_vec()),
             send: vec![],
+            gas_limit: None,
         })],
         log: vec![log("banana", "🍌")],
         data: Some(Binary(vec![x, y])),
+        submessages: vec![],
     }
 }
 
@@ -1189,9 +1267,11 @@ pub fn b<S: Storage, A: Api, Q: Querier>(
                     .to_vec(),
             ),
             send: vec![],
+            gas_limit: None,
         })],
         log: vec![log("kiwi", "🥝")],
         data: Some(Binary(vec![x + y])),
+        submessages: vec![],
     }
 }
 
@@ -1205,6 +1285,7 @@ pub fn c<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![log("watermelon", "🍉")],
         data: Some(Binary(vec![x + y])),
+        submessages: vec![],
     }
 }
 
@@ -1216,6 +1297,7 @@ pub fn empty_log_key_value<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![log("my value is empty", ""), log("", "my key is empty")],
         data: None,
+        submessages: vec![],
     }
 }
 
@@ -1227,6 +1309,7 @@ pub fn empty_data<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![],
         data: Some(Binary(vec![])),
+        submessages: vec![],
     }
 }
 
@@ -1238,6 +1321,7 @@ pub fn unicode_data<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![],
         data: Some(Binary("🍆🥑🍄".as_bytes().to_vec())),
+        submessages: vec![],
     }
 }
 
@@ -1249,6 +1333,7 @@ pub fn no_data<S: Storage, A: Api, Q: Querier>(
         messages: vec![],
         log: vec![],
         data: None,
+        submessages: vec![],
     }
 }
 
@@ -1268,6 +1353,7 @@ pub fn exec_callback_to_init<S: Storage, A: Api, Q: Querier>(
         })],
         log: vec![log("instantiating a new contract", "🪂")],
         data: None,
+        submessages: vec![],
     }
 }
 
@@ -1285,9 +1371,11 @@ fn exec_with_callback_contract_error(
                     .to_vec(),
             ),
             send: vec![],
+            gas_limit: None,
         })],
         log: vec![log("exec with a callback with contract error", "🤷‍♂️")],
         data: None,
+        submessages: vec![],
     }
 }
 
@@ -1299,6 +1387,7 @@ fn allocate_on_heap(bytes: usize) -> HandleResponse {
         data: Some(Binary("😅".as_bytes().to_vec())),
         log: vec![],
         messages: vec![],
+        submessages: vec![],
     }
 }
 
@@ -1313,6 +1402,7 @@ fn get_state<S: Storage, A: Api, Q: Querier>(
             data: Some(Binary(value)),
             log: vec![],
             messages: vec![],
+            submessages: vec![],
         },
         None => HandleResponse::default(),
     }
@@ -1447,6 +1537,33 @@ fn test_canonicalize_address_errors<S: Storage, A: Api, Q: Querier>(
         data: Some(Binary("🤟".as_bytes().to_vec())),
         log: vec![],
         messages: vec![],
+        submessages: vec![],
+    })
+}
+
+/////////////////////////////// Reply ///////////////////////////////
+
+/// Stores the reply, so it can be queried with LastReply, and returns its id as data.
+/// The reply to the submessage with id 666 fails.
+pub fn reply<S: Storage, A: Api, Q: Querier>(
+    deps: &mut Extern<S, A, Q>,
+    _env: Env,
+    reply: Reply,
+) -> HandleResult {
+    if reply.id == 666 {
+        return Err(StdError::generic_err("reply failed"));
+    }
+
+    deps.storage.set(b"last_reply", &to_vec(&reply)?);
+    let result = match reply.result {
+        SubcallResult::Ok(_) => "ok",
+        SubcallResult::Err(_) => "error",
+    };
+    Ok(HandleResponse {
+        messages: vec![],
+        log: vec![log("reply", reply.id), log("result", result)],
+        data: Some(Binary(format!("reply {}", reply.id).into_bytes())),
+        submessages: vec![],
     })
 }
 
@@ -1499,6 +1616,7 @@ pub fn query<S: Storage, A: Api, Q: Querier>(
                 })?;
             return Ok(to_binary(&answer)?);
         }
+        QueryMsg::LastReply {} => Ok(Binary(deps.storage.get(b"last_reply").unwrap_or_default())),
     }
 }
 
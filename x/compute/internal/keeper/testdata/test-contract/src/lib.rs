@@ -4,7 +4,7 @@ pub mod contract;
 mod wasm {
     use super::contract;
     use cosmwasm_std::{
-        do_handle, do_init, do_query, ExternalApi, ExternalQuerier, ExternalStorage,
+        do_handle, do_init, do_query, do_reply, ExternalApi, ExternalQuerier, ExternalStorage,
     };
 
     #[no_mangle]
@@ -25,6 +25,15 @@ mod wasm {
         )
     }
 
+    #[no_mangle]
+    extern "C" fn reply(env_ptr: u32, msg_ptr: u32) -> u32 {
+        do_reply(
+            &contract::reply::<ExternalStorage, ExternalApi, ExternalQuerier>,
+            env_ptr,
+            msg_ptr,
+        )
+    }
+
     #[no_mangle]
     extern "C" fn query(msg_ptr: u32) -> u32 {
         do_query(
@@ -13,6 +13,7 @@ func RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
 	cdc.RegisterConcrete(&MsgStoreCode{}, "wasm/MsgStoreCode", nil)
 	cdc.RegisterConcrete(&MsgInstantiateContract{}, "wasm/MsgInstantiateContract", nil)
 	cdc.RegisterConcrete(&MsgExecuteContract{}, "wasm/MsgExecuteContract", nil)
+	cdc.RegisterConcrete(&MsgUpdateComputeParams{}, "wasm/MsgUpdateComputeParams", nil)
 	/*
 		cdc.RegisterConcrete(MsgMigrateContract{}, "wasm/MsgMigrateContract", nil)
 		cdc.RegisterConcrete(MsgUpdateAdmin{}, "wasm/MsgUpdateAdmin", nil)
@@ -32,6 +33,7 @@ func RegisterInterfaces(registry types.InterfaceRegistry) {
 		&MsgStoreCode{},
 		&MsgInstantiateContract{},
 		&MsgExecuteContract{},
+		&MsgUpdateComputeParams{},
 	)
 }
 
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"strings"
 
 	sdkErrors "github.com/cosmos/cosmos-sdk/types/errors"
@@ -57,6 +58,33 @@ var (
 
 	// ErrSigFailed error for wasm code that has already been uploaded or failed
 	ErrSigFailed = sdkErrors.Register(DefaultCodespace, 16, "parse signature failed")
+
+	// ErrInvalidRecipient error for a contract message with a malformed or empty recipient
+	ErrInvalidRecipient = sdkErrors.Register(DefaultCodespace, 17, "invalid recipient")
+
+	// ErrInvalidAmount error for a contract message with a malformed or non-positive amount
+	ErrInvalidAmount = sdkErrors.Register(DefaultCodespace, 18, "invalid amount")
+
+	// ErrUnknownMsgVariant error for a contract message of a variant the encoder doesn't know
+	ErrUnknownMsgVariant = sdkErrors.Register(DefaultCodespace, 19, "unknown message variant")
+
+	// ErrMaxQueryStackSize error for a chain of contract queries that is nested too deep
+	ErrMaxQueryStackSize = sdkErrors.Register(DefaultCodespace, 20, "max query stack size exceeded")
+
+	// ErrContractNotFound error for a contract message addressed to a contract that doesn't exist
+	ErrContractNotFound = sdkErrors.Register(DefaultCodespace, 21, "contract not found")
+
+	// ErrTooManyContractMsgs error for a contract execution that returns more messages than the MaxMessagesPerCall param
+	ErrTooManyContractMsgs = sdkErrors.Register(DefaultCodespace, 22, "too many contract messages")
+
+	// ErrInvalidEvent error for contract event attributes that exceed the limits of the params
+	ErrInvalidEvent = sdkErrors.Register(DefaultCodespace, 23, "invalid event")
+
+	// ErrDispatchPaused error for a contract message dispatched while the DispatchEnabled param is unset
+	ErrDispatchPaused = sdkErrors.Register(DefaultCodespace, 24, "dispatch paused")
+
+	// ErrDeadlineExceeded error for a contract message dispatched after its deadline
+	ErrDeadlineExceeded = sdkErrors.Register(DefaultCodespace, 25, "deadline exceeded")
 )
 
 func IsEncryptedErrorCode(code uint32) bool {
@@ -67,6 +95,43 @@ func ErrContainsQueryError(err error) bool {
 	return strings.Contains(err.Error(), ErrQueryFailed.Error())
 }
 
+// FieldError is an error of a single field of a message, e.g. an address that doesn't parse. Field is the JSON
+// path of the field, like bank.send.to_address.
+type FieldError struct {
+	Field string
+	err   error
+}
+
+// NewFieldError wraps err as an error of the field. The wrapped error keeps its ABCI code.
+func NewFieldError(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Field: field, err: err}
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.err.Error()
+}
+
+// Cause is used by the sdk errors to find the ABCI code and Is to find wrapped errors
+func (e *FieldError) Cause() error {
+	return e.err
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.err
+}
+
+// ErrorField returns the field of the FieldError wrapped in err, if any
+func ErrorField(err error) (string, bool) {
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		return "", false
+	}
+	return fieldErr.Field, true
+}
+
 // ** Warning **
 // Below are functions that check for magic strings that depends on the output of the enclave.
 // Beware when changing this, or the rust error string
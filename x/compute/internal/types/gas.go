@@ -19,3 +19,12 @@ const InstanceCost uint64 = 10_000
 
 // CompileCost is how much SDK gas we charge *per byte* for compiling WASM code.
 const CompileCost uint64 = 2
+
+// EncodeMsgCost is how much SDK gas we charge for each message a contract asks us to dispatch, to pay for encoding it.
+const EncodeMsgCost uint64 = 100
+
+// EncodeByteCost is how much SDK gas we charge *per byte* of the protobuf encoded sdk.Msgs we encode for a contract.
+const EncodeByteCost uint64 = 2
+
+// QueryResultByteCost is how much SDK gas we charge *per byte* of the result of a query a contract makes.
+const QueryResultByteCost uint64 = 1
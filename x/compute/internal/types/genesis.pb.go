@@ -26,7 +26,7 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
 // GenesisState - genesis state of x/wasm
 type GenesisState struct {
-	//    Params params = 1 [(gogoproto.nullable) = false];
+	Params    Params     `protobuf:"bytes,1,opt,name=params,proto3" json:"params"`
 	Codes     []Code     `protobuf:"bytes,2,rep,name=codes,proto3" json:"codes,omitempty"`
 	Contracts []Contract `protobuf:"bytes,3,rep,name=contracts,proto3" json:"contracts,omitempty"`
 	Sequences []Sequence `protobuf:"bytes,4,rep,name=sequences,proto3" json:"sequences,omitempty"`
@@ -65,6 +65,13 @@ func (m *GenesisState) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GenesisState proto.InternalMessageInfo
 
+func (m *GenesisState) GetParams() Params {
+	if m != nil {
+		return m.Params
+	}
+	return Params{}
+}
+
 func (m *GenesisState) GetCodes() []Code {
 	if m != nil {
 		return m.Codes
@@ -281,41 +288,42 @@ func init() {
 }
 
 var fileDescriptor_e737d858048ffc2a = []byte{
-	// 543 bytes of a gzipped FileDescriptorProto
+	// 559 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x93, 0x4d, 0x6f, 0x12, 0x41,
-	0x18, 0xc7, 0x59, 0xba, 0x20, 0x1d, 0xd1, 0x9a, 0x91, 0x28, 0xa9, 0x76, 0x21, 0xd8, 0x03, 0x31,
-	0x96, 0x0d, 0x7a, 0xf4, 0xd4, 0xa5, 0x89, 0x41, 0xa2, 0x26, 0x4b, 0xbc, 0x68, 0x13, 0xb2, 0xcc,
-	0x3c, 0xc5, 0x0d, 0xec, 0x0e, 0xee, 0x0c, 0xd5, 0xfd, 0x14, 0x7a, 0xf1, 0x3b, 0xf5, 0xd8, 0xa3,
-	0x27, 0x62, 0xe0, 0xe6, 0x47, 0xf0, 0x64, 0xe6, 0x85, 0xed, 0x26, 0x4a, 0x7b, 0x82, 0x7d, 0xe6,
-	0xff, 0xfc, 0xe6, 0xff, 0xbc, 0x0c, 0x3a, 0xe4, 0x40, 0x12, 0x10, 0x2e, 0x61, 0xd1, 0x7c, 0x21,
-	0xc0, 0x3d, 0xef, 0x8e, 0x41, 0x04, 0x5d, 0x77, 0x02, 0x31, 0xf0, 0x90, 0x77, 0xe6, 0x09, 0x13,
-	0x0c, 0x3f, 0xd0, 0xaa, 0x8e, 0x51, 0x75, 0x8c, 0x6a, 0xbf, 0x36, 0x61, 0x13, 0xa6, 0x24, 0xae,
-	0xfc, 0xa7, 0xd5, 0xfb, 0xad, 0x2d, 0x4c, 0x91, 0xce, 0xc1, 0x10, 0x5b, 0x3f, 0x8a, 0xa8, 0xfa,
-	0x4a, 0xdf, 0x31, 0x14, 0x81, 0x00, 0x3c, 0x40, 0x25, 0xc2, 0x28, 0xf0, 0x7a, 0xb1, 0xb9, 0xd3,
-	0xbe, 0xfd, 0xfc, 0x71, 0xe7, 0xff, 0x57, 0x76, 0x7a, 0x8c, 0x82, 0xf7, 0xf0, 0x62, 0xd9, 0x28,
-	0xfc, 0x5e, 0x36, 0xf6, 0x54, 0xca, 0x33, 0x16, 0x85, 0x02, 0xa2, 0xb9, 0x48, 0x7d, 0xcd, 0xc0,
-	0x1f, 0xd1, 0x2e, 0x61, 0xb1, 0x48, 0x02, 0x22, 0x78, 0x7d, 0x47, 0x01, 0x9b, 0xdb, 0x81, 0x5a,
-	0xe8, 0x3d, 0x32, 0xd0, 0xfb, 0x59, 0x6a, 0x0e, 0x7c, 0xc5, 0x93, 0x70, 0x0e, 0x9f, 0x17, 0x10,
-	0x13, 0xe0, 0x75, 0xfb, 0x7a, 0xf8, 0xd0, 0x08, 0xaf, 0xe0, 0x59, 0x6a, 0x1e, 0x9e, 0x05, 0x5b,
-	0xdf, 0x2c, 0x64, 0xcb, 0x12, 0xf1, 0x13, 0x74, 0x4b, 0xd6, 0x32, 0x0a, 0x69, 0xdd, 0x6a, 0x5a,
-	0x6d, 0xdb, 0x43, 0xab, 0x65, 0xa3, 0x2c, 0x8f, 0xfa, 0x27, 0x7e, 0x59, 0x1e, 0xf5, 0x29, 0xee,
-	0xc9, 0x3a, 0xa5, 0x28, 0x3e, 0x63, 0xf5, 0x62, 0xd3, 0xba, 0xbe, 0x4e, 0x0a, 0xfd, 0xf8, 0x8c,
-	0x79, 0xb6, 0xb4, 0xe2, 0x57, 0x88, 0xf9, 0xc6, 0x07, 0x08, 0x29, 0xc8, 0x38, 0x15, 0x20, 0xbb,
-	0x65, 0xb5, 0xab, 0xbe, 0xc2, 0x7a, 0x32, 0xd0, 0x5a, 0x17, 0x51, 0x65, 0xd3, 0x23, 0x7c, 0x8a,
-	0xee, 0x6d, 0x1a, 0x31, 0x0a, 0x28, 0x4d, 0x80, 0x73, 0x65, 0xaf, 0xea, 0x75, 0xff, 0x2c, 0x1b,
-	0x47, 0x93, 0x50, 0x7c, 0x5a, 0x8c, 0xe5, 0xd5, 0x2e, 0x61, 0x3c, 0x62, 0xdc, 0xfc, 0x1c, 0x71,
-	0x3a, 0x35, 0xe3, 0x3f, 0x26, 0xe4, 0x58, 0x27, 0xfa, 0x7b, 0x1b, 0x94, 0x09, 0xe0, 0x77, 0xe8,
-	0x4e, 0x46, 0xcf, 0x95, 0x74, 0x78, 0xd3, 0xe8, 0x72, 0x65, 0x55, 0x49, 0x2e, 0x86, 0x5f, 0xa3,
-	0xbb, 0x19, 0x90, 0xcb, 0x35, 0x33, 0xcb, 0x70, 0xb0, 0x8d, 0xf8, 0x86, 0x51, 0x98, 0x19, 0x54,
-	0xe6, 0x45, 0x2f, 0xe8, 0x29, 0xaa, 0x65, 0x2c, 0xb2, 0xe0, 0x82, 0x45, 0xda, 0xa3, 0xad, 0x3c,
-	0x3e, 0xbd, 0xc9, 0x63, 0x4f, 0xa5, 0x48, 0x57, 0x3e, 0x26, 0xff, 0xc4, 0x5a, 0x1e, 0xaa, 0x6c,
-	0x76, 0x05, 0x37, 0x51, 0x39, 0xa4, 0xa3, 0x29, 0xa4, 0xa6, 0xb5, 0xbb, 0xab, 0x65, 0xa3, 0xd4,
-	0x3f, 0x19, 0x40, 0xea, 0x97, 0x42, 0x3a, 0x80, 0x14, 0xd7, 0x50, 0xe9, 0x3c, 0x98, 0x2d, 0x40,
-	0x35, 0xc8, 0xf6, 0xf5, 0x87, 0xf7, 0xfe, 0x62, 0xe5, 0x58, 0x97, 0x2b, 0xc7, 0xfa, 0xb5, 0x72,
-	0xac, 0xef, 0x6b, 0xa7, 0x70, 0xb9, 0x76, 0x0a, 0x3f, 0xd7, 0x4e, 0xe1, 0xc3, 0xcb, 0xdc, 0x60,
-	0x20, 0x0e, 0x27, 0x51, 0x10, 0xcd, 0x89, 0x3b, 0x54, 0x8e, 0xdf, 0x82, 0xf8, 0xc2, 0x92, 0xa9,
-	0xfb, 0x35, 0x7b, 0xaf, 0x61, 0x2c, 0x20, 0x89, 0x83, 0x99, 0x9e, 0xd8, 0xb8, 0xac, 0x5e, 0xec,
-	0x8b, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x49, 0x67, 0xf4, 0x82, 0x2b, 0x04, 0x00, 0x00,
+	0x18, 0xc7, 0x59, 0xba, 0x60, 0x99, 0xa2, 0x35, 0x23, 0x51, 0x52, 0xed, 0x42, 0xb0, 0x07, 0x62,
+	0x2c, 0x1b, 0xea, 0x51, 0x2f, 0x5d, 0x9a, 0x18, 0x24, 0xbe, 0x64, 0x89, 0x17, 0x6d, 0x42, 0x96,
+	0xd9, 0xa7, 0xb8, 0x81, 0xdd, 0x59, 0x77, 0x86, 0xea, 0x7e, 0x0a, 0xfd, 0x58, 0x3d, 0x36, 0xf1,
+	0xe2, 0x89, 0x18, 0xb8, 0xf9, 0x11, 0x3c, 0x99, 0x79, 0x61, 0xbb, 0x89, 0xd2, 0x9e, 0x60, 0x9f,
+	0xf9, 0xff, 0x7f, 0xf3, 0xbc, 0x0d, 0x3a, 0x60, 0x40, 0x12, 0xe0, 0x36, 0xa1, 0x61, 0x3c, 0xe7,
+	0x60, 0x9f, 0x77, 0xc7, 0xc0, 0xbd, 0xae, 0x3d, 0x81, 0x08, 0x58, 0xc0, 0x3a, 0x71, 0x42, 0x39,
+	0xc5, 0xf7, 0x95, 0xaa, 0xa3, 0x55, 0x1d, 0xad, 0xda, 0xab, 0x4d, 0xe8, 0x84, 0x4a, 0x89, 0x2d,
+	0xfe, 0x29, 0xf5, 0x5e, 0x6b, 0x03, 0x93, 0xa7, 0x31, 0x68, 0x62, 0xeb, 0x47, 0x11, 0x55, 0x5f,
+	0xaa, 0x3b, 0x86, 0xdc, 0xe3, 0x80, 0x5f, 0xa0, 0x72, 0xec, 0x25, 0x5e, 0xc8, 0xea, 0x46, 0xd3,
+	0x68, 0xef, 0x1c, 0x59, 0x9d, 0xff, 0xdf, 0xd9, 0x79, 0x27, 0x55, 0x8e, 0x79, 0xb1, 0x68, 0x14,
+	0x5c, 0xed, 0xc1, 0x03, 0x54, 0x22, 0xd4, 0x07, 0x56, 0x2f, 0x36, 0xb7, 0xda, 0x3b, 0x47, 0x8f,
+	0x36, 0x99, 0x7b, 0xd4, 0x07, 0xe7, 0x81, 0xb0, 0xfe, 0x5e, 0x34, 0x76, 0xa5, 0xe5, 0x29, 0x0d,
+	0x03, 0x0e, 0x61, 0xcc, 0x53, 0x57, 0x31, 0xf0, 0x47, 0x54, 0x21, 0x34, 0xe2, 0x89, 0x47, 0x38,
+	0xab, 0x6f, 0x49, 0x60, 0x73, 0x33, 0x50, 0x09, 0x9d, 0x87, 0x1a, 0x7a, 0x2f, 0xb3, 0xe6, 0xc0,
+	0x57, 0x3c, 0x01, 0x67, 0xf0, 0x79, 0x0e, 0x11, 0x01, 0x56, 0x37, 0xaf, 0x87, 0x0f, 0xb5, 0xf0,
+	0x0a, 0x9e, 0x59, 0xf3, 0xf0, 0x2c, 0xd8, 0xfa, 0x66, 0x20, 0x53, 0x94, 0x88, 0x1f, 0xa3, 0x5b,
+	0xa2, 0x96, 0x51, 0xe0, 0xcb, 0x76, 0x9a, 0x0e, 0x5a, 0x2e, 0x1a, 0x65, 0x71, 0xd4, 0x3f, 0x71,
+	0xcb, 0xe2, 0xa8, 0xef, 0xe3, 0x1e, 0xaa, 0x28, 0x51, 0x74, 0x46, 0xeb, 0xc5, 0xa6, 0x71, 0x5d,
+	0x2a, 0xd2, 0x1a, 0x9d, 0x51, 0xdd, 0xf7, 0x6d, 0xa2, 0xbf, 0xf1, 0x3e, 0x42, 0x12, 0x32, 0x4e,
+	0x39, 0x88, 0x6e, 0x19, 0xed, 0xaa, 0x2b, 0xb1, 0x8e, 0x08, 0xb4, 0x56, 0x45, 0xb4, 0xbd, 0xee,
+	0x11, 0x3e, 0x45, 0x77, 0xd7, 0x8d, 0x18, 0x79, 0xbe, 0x9f, 0x00, 0x53, 0xd3, 0xae, 0x3a, 0xdd,
+	0x3f, 0x8b, 0xc6, 0xe1, 0x24, 0xe0, 0x9f, 0xe6, 0x63, 0x71, 0xb5, 0x4d, 0x28, 0x0b, 0x29, 0xd3,
+	0x3f, 0x87, 0xcc, 0x9f, 0xea, 0xe5, 0x39, 0x26, 0xe4, 0x58, 0x19, 0xdd, 0xdd, 0x35, 0x4a, 0x07,
+	0xf0, 0x5b, 0x74, 0x3b, 0xa3, 0xe7, 0x4a, 0x3a, 0xb8, 0x69, 0x74, 0xb9, 0xb2, 0xaa, 0x24, 0x17,
+	0xc3, 0xaf, 0xd0, 0x9d, 0x0c, 0xc8, 0xc4, 0x92, 0xea, 0x65, 0xd8, 0xdf, 0x44, 0x7c, 0x4d, 0x7d,
+	0x98, 0x69, 0x54, 0x96, 0x8b, 0x5a, 0xef, 0x53, 0x54, 0xcb, 0x58, 0x64, 0xce, 0x38, 0x0d, 0x55,
+	0x8e, 0xa6, 0xcc, 0xf1, 0xc9, 0x4d, 0x39, 0xf6, 0xa4, 0x45, 0x64, 0xe5, 0x62, 0xf2, 0x4f, 0xac,
+	0xe5, 0xa0, 0xed, 0xf5, 0xae, 0xe0, 0x26, 0x2a, 0x07, 0xfe, 0x68, 0x0a, 0xa9, 0x6e, 0x6d, 0x65,
+	0xb9, 0x68, 0x94, 0xfa, 0x27, 0x03, 0x48, 0xdd, 0x52, 0xe0, 0x0f, 0x20, 0xc5, 0x35, 0x54, 0x3a,
+	0xf7, 0x66, 0x73, 0x90, 0x0d, 0x32, 0x5d, 0xf5, 0xe1, 0xbc, 0xbf, 0x58, 0x5a, 0xc6, 0xe5, 0xd2,
+	0x32, 0x7e, 0x2d, 0x2d, 0xe3, 0xfb, 0xca, 0x2a, 0x5c, 0xae, 0xac, 0xc2, 0xcf, 0x95, 0x55, 0xf8,
+	0xf0, 0x3c, 0x37, 0x18, 0x88, 0x82, 0x49, 0xe8, 0x85, 0x31, 0xb1, 0x87, 0x32, 0xe3, 0x37, 0xc0,
+	0xbf, 0xd0, 0x64, 0x6a, 0x7f, 0xcd, 0x5e, 0x7b, 0x10, 0x71, 0x48, 0x22, 0x6f, 0xa6, 0x26, 0x36,
+	0x2e, 0xcb, 0xf7, 0xfe, 0xec, 0xef, 0x00, 0xe9, 0x9c, 0x9c, 0x7a, 0x69, 0x04, 0x00, 0x00,
 }
 
 func (m *GenesisState) Marshal() (dAtA []byte, err error) {
@@ -380,6 +388,16 @@ func (m *GenesisState) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 			dAtA[i] = 0x12
 		}
 	}
+	{
+		size, err := m.Params.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenesis(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
 }
 
@@ -546,6 +564,8 @@ func (m *GenesisState) Size() (n int) {
 	}
 	var l int
 	_ = l
+	l = m.Params.Size()
+	n += 1 + l + sovGenesis(uint64(l))
 	if len(m.Codes) > 0 {
 		for _, e := range m.Codes {
 			l = e.Size()
@@ -661,6 +681,39 @@ func (m *GenesisState) Unmarshal(dAtA []byte) error {
 			return fmt.Errorf("proto: GenesisState: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Params", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenesis
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenesis
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenesis
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Params.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Codes", wireType)
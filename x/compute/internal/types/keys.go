@@ -23,10 +23,17 @@ const (
 	RouterKey = ModuleName
 )
 
+const ( // event types
+	// EventTypeEncodedMsg is emitted for every message a contract dispatches that was encoded into sdk.Msgs
+	EventTypeEncodedMsg = "wasm_encoded_msg"
+)
+
 const ( // event attributes
-	AttributeKeyContract = "contract_address"
-	AttributeKeyCodeID   = "code_id"
-	AttributeKeySigner   = "signer"
+	AttributeKeyContract   = "contract_address"
+	AttributeKeyCodeID     = "code_id"
+	AttributeKeySigner     = "signer"
+	AttributeKeyMsgVariant = "msg_variant"
+	AttributeKeyMsgHash    = "msg_hash"
 )
 
 // nolint
@@ -38,6 +45,11 @@ var (
 	// ContractHistoryStorePrefix = []byte{0x05}
 	ContractEnclaveIdPrefix = []byte{0x06}
 	ContractLabelPrefix     = []byte{0x07}
+	CodeMsgVariantsPrefix   = []byte{0x08}
+	ContractCodeIndexPrefix = []byte{0x09}
+
+	// DispatchCountPrefix is in the transient store
+	DispatchCountPrefix = []byte{0x01}
 
 	KeyLastCodeID     = append(SequenceKeyPrefix, []byte("lastCodeId")...)
 	KeyLastInstanceID = append(SequenceKeyPrefix, []byte("lastContractId")...)
@@ -53,6 +65,26 @@ func decodeCodeKey(src []byte) uint64 {
 	return binary.BigEndian.Uint64(src[len(CodeKeyPrefix):])
 }
 
+// GetCodeMsgVariantsKey constructs the key for the message variants contracts of the code may dispatch
+func GetCodeMsgVariantsKey(codeID uint64) []byte {
+	return append(CodeMsgVariantsPrefix, sdk.Uint64ToBigEndian(codeID)...)
+}
+
+// GetContractCodeIndexPrefix returns the prefix of the index of the contracts instantiated from the code
+func GetContractCodeIndexPrefix(codeID uint64) []byte {
+	return append(ContractCodeIndexPrefix, sdk.Uint64ToBigEndian(codeID)...)
+}
+
+// GetContractCodeIndexKey returns the key of the contract in the index of the contracts of its code
+func GetContractCodeIndexKey(codeID uint64, addr sdk.AccAddress) []byte {
+	return append(GetContractCodeIndexPrefix(codeID), addr...)
+}
+
+// GetDispatchCountKey returns the transient store key of the number of messages the contract dispatched this block
+func GetDispatchCountKey(addr sdk.AccAddress) []byte {
+	return append(DispatchCountPrefix, addr...)
+}
+
 // GetContractAddressKey returns the key for the WASM contract instance
 func GetContractAddressKey(addr sdk.AccAddress) []byte {
 	return append(ContractKeyPrefix, addr...)
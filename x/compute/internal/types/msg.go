@@ -64,7 +64,7 @@ func (msg MsgInstantiateContract) ValidateBasic() error {
 		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "code_id is required")
 	}
 
-	if err := validateLabel(msg.Label); err != nil {
+	if err := ValidateLabel(msg.Label); err != nil {
 		return err
 	}
 
@@ -128,6 +128,33 @@ func (msg MsgExecuteContract) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Sender}
 }
 
+func (msg MsgUpdateComputeParams) Route() string {
+	return RouterKey
+}
+
+func (msg MsgUpdateComputeParams) Type() string {
+	return "update-compute-params"
+}
+
+// ValidateBasic checks the params on their own, the keeper checks the authority and the resulting set of params
+func (msg MsgUpdateComputeParams) ValidateBasic() error {
+	if err := sdk.VerifyAddressFormat(msg.Authority); err != nil {
+		return sdkerrors.Wrap(err, "authority")
+	}
+	if err := validateMaxQueryStackSize(msg.MaxQueryStackSize); err != nil {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+	return nil
+}
+
+func (msg MsgUpdateComputeParams) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(&msg))
+}
+
+func (msg MsgUpdateComputeParams) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Authority}
+}
+
 /*
 type MsgMigrateContract struct {
 	Sender     sdk.AccAddress  `json:"sender" yaml:"sender"`
@@ -155,52 +155,107 @@ func (m *MsgExecuteContract) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_MsgExecuteContract proto.InternalMessageInfo
 
+// MsgUpdateComputeParams sets the params of the compute module that bound the work of a contract call.
+// Only the account of the ParamsAuthority param may send it. The params that decide what contracts may do, like the
+// send denom allowlist or whether dispatch is enabled, are policy and stay with parameter change proposals.
+type MsgUpdateComputeParams struct {
+	Authority github_com_cosmos_cosmos_sdk_types.AccAddress `protobuf:"bytes,1,opt,name=authority,proto3,casttype=github.com/cosmos/cosmos-sdk/types.AccAddress" json:"authority,omitempty"`
+	// MaxQueryStackSize is how deep contracts may nest smart queries to other contracts
+	MaxQueryStackSize uint32 `protobuf:"varint,2,opt,name=max_query_stack_size,json=maxQueryStackSize,proto3" json:"max_query_stack_size,omitempty"`
+	// MaxMessagesPerCall is how many messages and submessages a single contract call may return, 0 for no limit
+	MaxMessagesPerCall uint32 `protobuf:"varint,3,opt,name=max_messages_per_call,json=maxMessagesPerCall,proto3" json:"max_messages_per_call,omitempty"`
+	// MsgDispatchCost is the SDK gas charged for each message a contract dispatches
+	MsgDispatchCost uint64 `protobuf:"varint,4,opt,name=msg_dispatch_cost,json=msgDispatchCost,proto3" json:"msg_dispatch_cost,omitempty"`
+}
+
+func (m *MsgUpdateComputeParams) Reset()         { *m = MsgUpdateComputeParams{} }
+func (m *MsgUpdateComputeParams) String() string { return proto.CompactTextString(m) }
+func (*MsgUpdateComputeParams) ProtoMessage()    {}
+func (*MsgUpdateComputeParams) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6815433faf72a133, []int{3}
+}
+func (m *MsgUpdateComputeParams) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *MsgUpdateComputeParams) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_MsgUpdateComputeParams.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *MsgUpdateComputeParams) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MsgUpdateComputeParams.Merge(m, src)
+}
+func (m *MsgUpdateComputeParams) XXX_Size() int {
+	return m.Size()
+}
+func (m *MsgUpdateComputeParams) XXX_DiscardUnknown() {
+	xxx_messageInfo_MsgUpdateComputeParams.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MsgUpdateComputeParams proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*MsgStoreCode)(nil), "secret.compute.v1beta1.MsgStoreCode")
 	proto.RegisterType((*MsgInstantiateContract)(nil), "secret.compute.v1beta1.MsgInstantiateContract")
 	proto.RegisterType((*MsgExecuteContract)(nil), "secret.compute.v1beta1.MsgExecuteContract")
+	proto.RegisterType((*MsgUpdateComputeParams)(nil), "secret.compute.v1beta1.MsgUpdateComputeParams")
 }
 
 func init() { proto.RegisterFile("secret/compute/v1beta1/msg.proto", fileDescriptor_6815433faf72a133) }
 
 var fileDescriptor_6815433faf72a133 = []byte{
-	// 572 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0x31, 0x6f, 0xd3, 0x40,
-	0x18, 0x8d, 0x49, 0xea, 0xb4, 0xd7, 0x08, 0xaa, 0x53, 0x55, 0xb9, 0x1d, 0xec, 0xa8, 0x2c, 0x19,
-	0xa8, 0x4d, 0x8b, 0xc4, 0x00, 0x53, 0x53, 0x40, 0x64, 0x08, 0x83, 0x23, 0x84, 0xc4, 0x12, 0x9d,
-	0xcf, 0xc7, 0xe5, 0xda, 0xf8, 0x2e, 0xf2, 0x77, 0xa6, 0xed, 0x3f, 0x60, 0x41, 0x62, 0xe0, 0x07,
-	0x30, 0xf3, 0x4b, 0xca, 0xd6, 0x91, 0x29, 0x20, 0xf7, 0x5f, 0x30, 0xa1, 0x3b, 0x3b, 0x2d, 0x03,
-	0x95, 0x2a, 0x44, 0xa7, 0xdc, 0xcb, 0x7b, 0x7e, 0xf7, 0xbd, 0x7b, 0xa7, 0x43, 0x5d, 0x60, 0x34,
-	0x67, 0x3a, 0xa2, 0x2a, 0x9b, 0x15, 0x9a, 0x45, 0xef, 0x77, 0x13, 0xa6, 0xc9, 0x6e, 0x94, 0x01,
-	0x0f, 0x67, 0xb9, 0xd2, 0x0a, 0x6f, 0x54, 0x8a, 0xb0, 0x56, 0x84, 0xb5, 0x62, 0x6b, 0x9d, 0x2b,
-	0xae, 0xac, 0x24, 0x32, 0xab, 0x4a, 0xbd, 0xe5, 0x53, 0x05, 0x99, 0x82, 0x28, 0x21, 0x70, 0x65,
-	0x46, 0x95, 0x90, 0x15, 0xbf, 0xfd, 0xcd, 0x41, 0x9d, 0x21, 0xf0, 0x91, 0x56, 0x39, 0x3b, 0x50,
-	0x29, 0xc3, 0x03, 0xe4, 0x02, 0x93, 0x29, 0xcb, 0x3d, 0xa7, 0xeb, 0xf4, 0x3a, 0xfd, 0xdd, 0x5f,
-	0xf3, 0x60, 0x87, 0x0b, 0x3d, 0x29, 0x12, 0xb3, 0x65, 0x54, 0xfb, 0x55, 0x3f, 0x3b, 0x90, 0x1e,
-	0x45, 0xfa, 0x74, 0xc6, 0x20, 0xdc, 0xa7, 0x74, 0x3f, 0x4d, 0x73, 0x06, 0x10, 0xd7, 0x06, 0xf8,
-	0x31, 0xba, 0x7b, 0x4c, 0x20, 0x1b, 0x27, 0xa7, 0x9a, 0x8d, 0xa9, 0x4a, 0x99, 0x77, 0xc7, 0x5a,
-	0xae, 0x95, 0xf3, 0xa0, 0xf3, 0x66, 0x7f, 0x34, 0xec, 0x9f, 0x6a, 0xbb, 0x69, 0xdc, 0x31, 0xba,
-	0x05, 0xc2, 0x1b, 0xc8, 0x05, 0x55, 0xe4, 0x94, 0x79, 0xcd, 0xae, 0xd3, 0x5b, 0x89, 0x6b, 0x84,
-	0x3d, 0xd4, 0x4e, 0x0a, 0x31, 0x35, 0xb3, 0xb5, 0x2c, 0xb1, 0x80, 0x4f, 0x5a, 0x1f, 0xbe, 0x04,
-	0x8d, 0xed, 0x8f, 0x4d, 0xb4, 0x31, 0x04, 0x3e, 0x90, 0xa0, 0x89, 0xd4, 0x82, 0x18, 0x3b, 0xa9,
-	0x73, 0x42, 0xf5, 0xff, 0x4c, 0xf5, 0x00, 0x61, 0x4a, 0xa6, 0xd3, 0x84, 0xd0, 0x23, 0x1b, 0x6a,
-	0x3c, 0x21, 0x30, 0xb1, 0xc9, 0x56, 0xe2, 0xb5, 0x05, 0x63, 0x72, 0xbc, 0x24, 0x30, 0xc1, 0xf7,
-	0x51, 0xdb, 0x8a, 0x44, 0x6a, 0xc3, 0xb4, 0xfa, 0xa8, 0x9c, 0x07, 0xae, 0xa1, 0x07, 0xcf, 0x62,
-	0xd7, 0x50, 0x83, 0x14, 0xaf, 0xa3, 0xa5, 0x29, 0x49, 0xd8, 0xb4, 0x8e, 0x55, 0x01, 0xbc, 0x89,
-	0x96, 0x85, 0x14, 0x7a, 0x9c, 0x01, 0xf7, 0x96, 0xcc, 0xd4, 0x71, 0xdb, 0xe0, 0x21, 0x70, 0x7c,
-	0x88, 0x90, 0xa5, 0xde, 0x15, 0x32, 0x05, 0xcf, 0xed, 0x36, 0x7b, 0xab, 0x7b, 0x9b, 0x61, 0x35,
-	0x7d, 0x68, 0xaa, 0x5e, 0xdc, 0x8a, 0xf0, 0x40, 0x09, 0xd9, 0x7f, 0x78, 0x36, 0x0f, 0x1a, 0x5f,
-	0x7f, 0x04, 0xbd, 0x1b, 0x24, 0x36, 0x1f, 0x40, 0xbc, 0x62, 0xec, 0x5f, 0x18, 0x77, 0xbc, 0x87,
-	0x3a, 0x97, 0x79, 0x41, 0x70, 0xaf, 0x6d, 0x0f, 0xf0, 0x5e, 0x39, 0x0f, 0x56, 0x0f, 0xea, 0xff,
-	0x47, 0x82, 0xc7, 0xab, 0xf4, 0x0a, 0xd4, 0x7d, 0x7c, 0x6e, 0x22, 0x3c, 0x04, 0xfe, 0xfc, 0x84,
-	0xd1, 0xe2, 0x76, 0xba, 0x18, 0xa2, 0x65, 0x5a, 0xdb, 0xd6, 0x77, 0xeb, 0x1f, 0xcc, 0x2e, 0x2d,
-	0xf0, 0x1a, 0x6a, 0x9a, 0xc3, 0x6e, 0xda, 0xc3, 0x36, 0xcb, 0x6b, 0xca, 0x6e, 0x5d, 0x53, 0xf6,
-	0x21, 0x42, 0xc0, 0xe4, 0xa2, 0x96, 0xa5, 0x5b, 0xa8, 0xc5, 0xd8, 0xff, 0xbd, 0x16, 0xf7, 0xa6,
-	0xb5, 0xf4, 0x5f, 0x9f, 0x95, 0xbe, 0x73, 0x5e, 0xfa, 0xce, 0xcf, 0xd2, 0x77, 0x3e, 0x5d, 0xf8,
-	0x8d, 0xf3, 0x0b, 0xbf, 0xf1, 0xfd, 0xc2, 0x6f, 0xbc, 0x7d, 0xfa, 0xc7, 0x20, 0x4c, 0x0a, 0x9e,
-	0x91, 0x6c, 0x46, 0xa3, 0x91, 0x7d, 0x6f, 0x5e, 0x31, 0x7d, 0xac, 0xf2, 0xa3, 0xe8, 0xe4, 0xf2,
-	0x69, 0x12, 0x52, 0xb3, 0x5c, 0x92, 0x69, 0x35, 0x61, 0xe2, 0xda, 0x07, 0xe5, 0xd1, 0xef, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xea, 0x28, 0x0f, 0x71, 0xc2, 0x04, 0x00, 0x00,
+	// 692 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0xc1, 0x6e, 0xd3, 0x4c,
+	0x10, 0x8e, 0x9b, 0x34, 0x69, 0xb6, 0xe9, 0xdf, 0x76, 0xd5, 0xbf, 0x72, 0x7b, 0x48, 0xa2, 0x72,
+	0x89, 0x10, 0x8d, 0x49, 0x91, 0x38, 0xc0, 0xa9, 0x49, 0x41, 0xe4, 0x10, 0x28, 0x8e, 0x2a, 0x24,
+	0x2e, 0xd6, 0x7a, 0xbd, 0x6c, 0xdc, 0xc6, 0x5e, 0xe3, 0x59, 0xd3, 0xa4, 0x4f, 0xc0, 0x05, 0x89,
+	0x03, 0x0f, 0xc0, 0x99, 0x27, 0x29, 0xb7, 0x1e, 0x39, 0x05, 0x94, 0xbe, 0x05, 0x17, 0xd0, 0xae,
+	0x9d, 0x96, 0x03, 0x95, 0xaa, 0x8a, 0x9e, 0xbc, 0xb3, 0xdf, 0xec, 0xb7, 0xf3, 0xcd, 0xe7, 0x1d,
+	0x54, 0x07, 0x46, 0x63, 0x26, 0x2d, 0x2a, 0x82, 0x28, 0x91, 0xcc, 0x7a, 0xd7, 0x72, 0x99, 0x24,
+	0x2d, 0x2b, 0x00, 0xde, 0x8c, 0x62, 0x21, 0x05, 0x5e, 0x4f, 0x33, 0x9a, 0x59, 0x46, 0x33, 0xcb,
+	0xd8, 0x5c, 0xe3, 0x82, 0x0b, 0x9d, 0x62, 0xa9, 0x55, 0x9a, 0xbd, 0x59, 0xa5, 0x02, 0x02, 0x01,
+	0x96, 0x4b, 0xe0, 0x92, 0x8c, 0x0a, 0x3f, 0x4c, 0xf1, 0xad, 0xaf, 0x06, 0xaa, 0xf4, 0x80, 0xf7,
+	0xa5, 0x88, 0x59, 0x47, 0x78, 0x0c, 0x77, 0x51, 0x11, 0x58, 0xe8, 0xb1, 0xd8, 0x34, 0xea, 0x46,
+	0xa3, 0xd2, 0x6e, 0xfd, 0x9c, 0xd4, 0xb6, 0xb9, 0x2f, 0x07, 0x89, 0xab, 0xae, 0xb4, 0x32, 0xbe,
+	0xf4, 0xb3, 0x0d, 0xde, 0x91, 0x25, 0xc7, 0x11, 0x83, 0xe6, 0x2e, 0xa5, 0xbb, 0x9e, 0x17, 0x33,
+	0x00, 0x3b, 0x23, 0xc0, 0x0f, 0xd1, 0x7f, 0xc7, 0x04, 0x02, 0xc7, 0x1d, 0x4b, 0xe6, 0x50, 0xe1,
+	0x31, 0x73, 0x4e, 0x53, 0xae, 0x4c, 0x27, 0xb5, 0xca, 0xab, 0xdd, 0x7e, 0xaf, 0x3d, 0x96, 0xfa,
+	0x52, 0xbb, 0xa2, 0xf2, 0x66, 0x11, 0x5e, 0x47, 0x45, 0x10, 0x49, 0x4c, 0x99, 0x99, 0xaf, 0x1b,
+	0x8d, 0xb2, 0x9d, 0x45, 0xd8, 0x44, 0x25, 0x37, 0xf1, 0x87, 0xaa, 0xb6, 0x82, 0x06, 0x66, 0xe1,
+	0xa3, 0xc2, 0xfb, 0xcf, 0xb5, 0xdc, 0xd6, 0x87, 0x3c, 0x5a, 0xef, 0x01, 0xef, 0x86, 0x20, 0x49,
+	0x28, 0x7d, 0xa2, 0xe8, 0x42, 0x19, 0x13, 0x2a, 0xff, 0xa5, 0xaa, 0x7b, 0x08, 0x53, 0x32, 0x1c,
+	0xba, 0x84, 0x1e, 0x69, 0x51, 0xce, 0x80, 0xc0, 0x40, 0x2b, 0x2b, 0xdb, 0x2b, 0x33, 0x44, 0xe9,
+	0x78, 0x46, 0x60, 0x80, 0xef, 0xa0, 0x92, 0x4e, 0xf2, 0x3d, 0x2d, 0xa6, 0xd0, 0x46, 0xd3, 0x49,
+	0xad, 0xa8, 0xe0, 0xee, 0x9e, 0x5d, 0x54, 0x50, 0xd7, 0xc3, 0x6b, 0x68, 0x7e, 0x48, 0x5c, 0x36,
+	0xcc, 0x64, 0xa5, 0x01, 0xde, 0x40, 0x0b, 0x7e, 0xe8, 0x4b, 0x27, 0x00, 0x6e, 0xce, 0xab, 0xaa,
+	0xed, 0x92, 0x8a, 0x7b, 0xc0, 0xf1, 0x21, 0x42, 0x1a, 0x7a, 0x93, 0x84, 0x1e, 0x98, 0xc5, 0x7a,
+	0xbe, 0xb1, 0xb8, 0xb3, 0xd1, 0x4c, 0xab, 0x6f, 0x2a, 0xab, 0x67, 0x7f, 0x45, 0xb3, 0x23, 0xfc,
+	0xb0, 0x7d, 0xff, 0x74, 0x52, 0xcb, 0x7d, 0xf9, 0x5e, 0x6b, 0x5c, 0x43, 0xb1, 0x3a, 0x00, 0x76,
+	0x59, 0xd1, 0x3f, 0x55, 0xec, 0x78, 0x07, 0x55, 0x2e, 0xf4, 0x82, 0xcf, 0xcd, 0x92, 0x6e, 0xe0,
+	0xf2, 0x74, 0x52, 0x5b, 0xec, 0x64, 0xfb, 0x7d, 0x9f, 0xdb, 0x8b, 0xf4, 0x32, 0xc8, 0xfc, 0xf8,
+	0x94, 0x47, 0xb8, 0x07, 0xfc, 0xc9, 0x88, 0xd1, 0xe4, 0x76, 0xbc, 0xe8, 0xa1, 0x05, 0x9a, 0xd1,
+	0x9a, 0x73, 0x37, 0x25, 0xbb, 0xa0, 0xc0, 0x2b, 0x28, 0xaf, 0x9a, 0x9d, 0xd7, 0xcd, 0x56, 0xcb,
+	0x2b, 0xcc, 0x2e, 0x5c, 0x61, 0xf6, 0x21, 0x42, 0xc0, 0xc2, 0x99, 0x2d, 0xf3, 0xb7, 0x60, 0x8b,
+	0xa2, 0xff, 0xbb, 0x2d, 0xc5, 0x6b, 0xdb, 0xf2, 0xcb, 0xd0, 0xcf, 0xe4, 0x20, 0xf2, 0xf4, 0x0b,
+	0xd1, 0x53, 0x64, 0x9f, 0xc4, 0x24, 0x00, 0xfc, 0x02, 0x95, 0x49, 0x22, 0x07, 0x22, 0xf6, 0xe5,
+	0xf8, 0xe6, 0xee, 0x5c, 0x72, 0x60, 0x0b, 0xad, 0x05, 0x64, 0xe4, 0xbc, 0x4d, 0x58, 0x3c, 0x76,
+	0x40, 0xa6, 0xc5, 0x9e, 0xa4, 0x83, 0x60, 0xc9, 0x5e, 0x0d, 0xc8, 0xe8, 0xa5, 0x82, 0xfa, 0x52,
+	0x57, 0x78, 0xc2, 0x70, 0x0b, 0xfd, 0xaf, 0x0e, 0x04, 0x0c, 0x80, 0x70, 0x06, 0x4e, 0xc4, 0x62,
+	0x47, 0x49, 0xd0, 0xa6, 0x2c, 0xd9, 0x38, 0x20, 0xa3, 0x5e, 0x86, 0xed, 0xb3, 0x58, 0x29, 0xc5,
+	0x77, 0xd1, 0x6a, 0x00, 0xdc, 0xf1, 0x7c, 0x88, 0x88, 0xa4, 0x03, 0x87, 0x0a, 0x90, 0xda, 0xa2,
+	0x82, 0xbd, 0x1c, 0x00, 0xdf, 0xcb, 0xf6, 0x3b, 0x02, 0x64, 0xda, 0x81, 0xf6, 0xc1, 0xe9, 0xb4,
+	0x6a, 0x9c, 0x4d, 0xab, 0xc6, 0x8f, 0x69, 0xd5, 0xf8, 0x78, 0x5e, 0xcd, 0x9d, 0x9d, 0x57, 0x73,
+	0xdf, 0xce, 0xab, 0xb9, 0xd7, 0x8f, 0xff, 0x50, 0xca, 0x42, 0x9f, 0x07, 0x24, 0x88, 0xa8, 0xd5,
+	0xd7, 0x13, 0xf7, 0x39, 0x93, 0xc7, 0x22, 0x3e, 0xb2, 0x46, 0x17, 0xc3, 0xd9, 0x0f, 0x25, 0x8b,
+	0x43, 0x32, 0x4c, 0x5b, 0xe0, 0x16, 0xf5, 0x48, 0x7d, 0xf0, 0x7b, 0x00, 0xa8, 0xfd, 0x6f, 0x09,
+	0xc4, 0x05, 0x00, 0x00,
 }
 
 func (m *MsgStoreCode) Marshal() (dAtA []byte, err error) {
@@ -403,6 +458,51 @@ func (m *MsgExecuteContract) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *MsgUpdateComputeParams) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MsgUpdateComputeParams) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *MsgUpdateComputeParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.MsgDispatchCost != 0 {
+		i = encodeVarintMsg(dAtA, i, uint64(m.MsgDispatchCost))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.MaxMessagesPerCall != 0 {
+		i = encodeVarintMsg(dAtA, i, uint64(m.MaxMessagesPerCall))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.MaxQueryStackSize != 0 {
+		i = encodeVarintMsg(dAtA, i, uint64(m.MaxQueryStackSize))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Authority) > 0 {
+		i -= len(m.Authority)
+		copy(dAtA[i:], m.Authority)
+		i = encodeVarintMsg(dAtA, i, uint64(len(m.Authority)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintMsg(dAtA []byte, offset int, v uint64) int {
 	offset -= sovMsg(v)
 	base := offset
@@ -512,6 +612,28 @@ func (m *MsgExecuteContract) Size() (n int) {
 	return n
 }
 
+func (m *MsgUpdateComputeParams) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Authority)
+	if l > 0 {
+		n += 1 + l + sovMsg(uint64(l))
+	}
+	if m.MaxQueryStackSize != 0 {
+		n += 1 + sovMsg(uint64(m.MaxQueryStackSize))
+	}
+	if m.MaxMessagesPerCall != 0 {
+		n += 1 + sovMsg(uint64(m.MaxMessagesPerCall))
+	}
+	if m.MsgDispatchCost != 0 {
+		n += 1 + sovMsg(uint64(m.MsgDispatchCost))
+	}
+	return n
+}
+
 func sovMsg(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -1221,6 +1343,147 @@ func (m *MsgExecuteContract) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *MsgUpdateComputeParams) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MsgUpdateComputeParams: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MsgUpdateComputeParams: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Authority", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthMsg
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Authority = append(m.Authority[:0], dAtA[iNdEx:postIndex]...)
+			if m.Authority == nil {
+				m.Authority = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxQueryStackSize", wireType)
+			}
+			m.MaxQueryStackSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxQueryStackSize |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxMessagesPerCall", wireType)
+			}
+			m.MaxMessagesPerCall = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxMessagesPerCall |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgDispatchCost", wireType)
+			}
+			m.MsgDispatchCost = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MsgDispatchCost |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipMsg(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
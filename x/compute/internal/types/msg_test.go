@@ -347,6 +347,52 @@ func TestExecuteContractValidation(t *testing.T) {
 	}
 }
 
+func TestUpdateComputeParamsValidation(t *testing.T) {
+	badAddress := sdk.AccAddress(make([]byte, 2000))
+	goodAddress := sdk.AccAddress(make([]byte, 20))
+
+	cases := map[string]struct {
+		msg   MsgUpdateComputeParams
+		valid bool
+	}{
+		"empty": {
+			msg:   MsgUpdateComputeParams{},
+			valid: false,
+		},
+		"correct minimal": {
+			msg: MsgUpdateComputeParams{
+				Authority:         goodAddress,
+				MaxQueryStackSize: 1,
+			},
+			valid: true,
+		},
+		"bad authority": {
+			msg: MsgUpdateComputeParams{
+				Authority:         badAddress,
+				MaxQueryStackSize: 1,
+			},
+			valid: false,
+		},
+		"no query stack": {
+			msg: MsgUpdateComputeParams{
+				Authority: goodAddress,
+			},
+			valid: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.msg.ValidateBasic()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
 /*
 func TestMsgUpdateAdministrator(t *testing.T) {
 	badAddress, err := sdk.AccAddressFromHex("012345")
@@ -1,14 +1,10 @@
 package types
 
-/*
 import (
 	"fmt"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
-	"github.com/cosmos/cosmos-sdk/x/params"
-	"github.com/pkg/errors"
-	"gopkg.in/yaml.v2"
+	paramtypes "github.com/cosmos/cosmos-sdk/x/params/types"
 )
 
 const (
@@ -16,161 +12,186 @@ const (
 	DefaultParamspace = ModuleName
 )
 
-var ParamStoreKeyUploadAccess = []byte("uploadAccess")
-var ParamStoreKeyInstantiateAccess = []byte("instantiateAccess")
-
-type AccessType string
-
-const (
-	Undefined   AccessType = "Undefined"
-	Nobody      AccessType = "Nobody"
-	OnlyAddress AccessType = "OnlyAddress"
-	Everybody   AccessType = "Everybody"
-)
-
-var AllAccessTypes = map[AccessType]struct{}{
-	Nobody:      {},
-	OnlyAddress: {},
-	Everybody:   {},
-}
-
-func (a AccessType) With(addr sdk.AccAddress) AccessConfig {
-	switch a {
-	case Nobody:
-		return AllowNobody
-	case OnlyAddress:
-		if err := sdk.VerifyAddressFormat(addr); err != nil {
-			panic(err)
-		}
-		return AccessConfig{Type: OnlyAddress, Address: addr}
-	case Everybody:
-		return AllowEverybody
-	}
-	panic("unsupported access type")
-}
-
-func (a *AccessType) UnmarshalText(text []byte) error {
-	s := AccessType(text)
-	if _, ok := AllAccessTypes[s]; ok {
-		*a = s
-		return nil
-	}
-	*a = Undefined
-	return nil
-}
-
-func (a AccessType) MarshalText() ([]byte, error) {
-	if _, ok := AllAccessTypes[a]; ok {
-		return []byte(a), nil
-	}
-	return []byte(Undefined), nil
-}
-
-type AccessConfig struct {
-	Type    AccessType     `json:"permission" yaml:"permission"`
-	Address sdk.AccAddress `json:"address,omitempty" yaml:"address"`
-}
-
-func (a AccessConfig) Equals(o AccessConfig) bool {
-	return a.Type == o.Type && a.Address.Equals(o.Address)
-}
-
 var (
-	DefaultUploadAccess = AllowEverybody
-	AllowEverybody      = AccessConfig{Type: Everybody}
-	AllowNobody         = AccessConfig{Type: Nobody}
+	ParamStoreKeyRejectSelfSends              = []byte("RejectSelfSends")
+	ParamStoreKeyRejectSelfExecutes           = []byte("RejectSelfExecutes")
+	ParamStoreKeyMaxQueryStackSize            = []byte("MaxQueryStackSize")
+	ParamStoreKeyMaxMessagesPerCall           = []byte("MaxMessagesPerCall")
+	ParamStoreKeyMsgDispatchCost              = []byte("MsgDispatchCost")
+	ParamStoreKeyMaxEventAttributes           = []byte("MaxEventAttributes")
+	ParamStoreKeyMaxEventAttributeKeyLength   = []byte("MaxEventAttributeKeyLength")
+	ParamStoreKeyMaxEventAttributeValueLength = []byte("MaxEventAttributeValueLength")
+	ParamStoreKeySendDenomAllowlist           = []byte("SendDenomAllowlist")
+	ParamStoreKeyRejectModuleAccountSends     = []byte("RejectModuleAccountSends")
+	ParamStoreKeyDispatchEnabled              = []byte("DispatchEnabled")
+	ParamStoreKeyMaxWasmMsgSize               = []byte("MaxWasmMsgSize")
+	ParamStoreKeyMinInstantiateFunds          = []byte("MinInstantiateFunds")
+	ParamStoreKeyMaxDispatchesPerBlock        = []byte("MaxDispatchesPerBlock")
+	ParamStoreKeyCanonicalWasmMsgs            = []byte("CanonicalWasmMsgs")
+	ParamStoreKeyParamsAuthority              = []byte("ParamsAuthority")
 )
 
-// Params defines the set of wasm parameters.
-type Params struct {
-	UploadAccess                 AccessConfig `json:"code_upload_access" yaml:"code_upload_access"`
-	DefaultInstantiatePermission AccessType   `json:"instantiate_default_permission" yaml:"instantiate_default_permission"`
-}
+// DefaultMaxQueryStackSize is how deep contracts may nest smart queries to other contracts by default
+const DefaultMaxQueryStackSize uint32 = 10
+
+var _ paramtypes.ParamSet = &Params{}
 
 // ParamKeyTable returns the parameter key table.
-func ParamKeyTable() params.KeyTable {
-	return params.NewKeyTable().RegisterParamSet(&Params{})
+func ParamKeyTable() paramtypes.KeyTable {
+	return paramtypes.NewKeyTable().RegisterParamSet(&Params{})
 }
 
-// DefaultParams returns default wasm parameters
+// DefaultParams returns default compute parameters
 func DefaultParams() Params {
 	return Params{
-		UploadAccess:                 AllowEverybody,
-		DefaultInstantiatePermission: Everybody,
+		RejectSelfSends:              false,
+		RejectSelfExecutes:           false,
+		MaxQueryStackSize:            DefaultMaxQueryStackSize,
+		MaxMessagesPerCall:           0,
+		MsgDispatchCost:              0,
+		MaxEventAttributes:           0,
+		MaxEventAttributeKeyLength:   0,
+		MaxEventAttributeValueLength: 0,
+		SendDenomAllowlist:           nil,
+		RejectModuleAccountSends:     false,
+		DispatchEnabled:              true,
+		MaxWasmMsgSize:               0,
+		MinInstantiateFunds:          nil,
+		MaxDispatchesPerBlock:        0,
+		CanonicalWasmMsgs:            false,
+		ParamsAuthority:              "",
 	}
 }
 
-func (p Params) String() string {
-	out, _ := yaml.Marshal(p)
-	return string(out)
-}
-
 // ParamSetPairs returns the parameter set pairs.
-func (p *Params) ParamSetPairs() params.ParamSetPairs {
-	return params.ParamSetPairs{
-		params.NewParamSetPair(ParamStoreKeyUploadAccess, &p.UploadAccess, validateAccessConfig),
-		params.NewParamSetPair(ParamStoreKeyInstantiateAccess, &p.DefaultInstantiatePermission, validateAccessType),
+func (p *Params) ParamSetPairs() paramtypes.ParamSetPairs {
+	return paramtypes.ParamSetPairs{
+		paramtypes.NewParamSetPair(ParamStoreKeyRejectSelfSends, &p.RejectSelfSends, validateBool),
+		paramtypes.NewParamSetPair(ParamStoreKeyRejectSelfExecutes, &p.RejectSelfExecutes, validateBool),
+		paramtypes.NewParamSetPair(ParamStoreKeyMaxQueryStackSize, &p.MaxQueryStackSize, validateMaxQueryStackSize),
+		paramtypes.NewParamSetPair(ParamStoreKeyMaxMessagesPerCall, &p.MaxMessagesPerCall, validateUint32),
+		paramtypes.NewParamSetPair(ParamStoreKeyMsgDispatchCost, &p.MsgDispatchCost, validateUint64),
+		paramtypes.NewParamSetPair(ParamStoreKeyMaxEventAttributes, &p.MaxEventAttributes, validateUint32),
+		paramtypes.NewParamSetPair(ParamStoreKeyMaxEventAttributeKeyLength, &p.MaxEventAttributeKeyLength, validateUint32),
+		paramtypes.NewParamSetPair(ParamStoreKeyMaxEventAttributeValueLength, &p.MaxEventAttributeValueLength, validateUint32),
+		paramtypes.NewParamSetPair(ParamStoreKeySendDenomAllowlist, &p.SendDenomAllowlist, validateDenomList),
+		paramtypes.NewParamSetPair(ParamStoreKeyRejectModuleAccountSends, &p.RejectModuleAccountSends, validateBool),
+		paramtypes.NewParamSetPair(ParamStoreKeyDispatchEnabled, &p.DispatchEnabled, validateBool),
+		paramtypes.NewParamSetPair(ParamStoreKeyMaxWasmMsgSize, &p.MaxWasmMsgSize, validateUint32),
+		paramtypes.NewParamSetPair(ParamStoreKeyMinInstantiateFunds, &p.MinInstantiateFunds, validateCoins),
+		paramtypes.NewParamSetPair(ParamStoreKeyMaxDispatchesPerBlock, &p.MaxDispatchesPerBlock, validateUint32),
+		paramtypes.NewParamSetPair(ParamStoreKeyCanonicalWasmMsgs, &p.CanonicalWasmMsgs, validateBool),
+		paramtypes.NewParamSetPair(ParamStoreKeyParamsAuthority, &p.ParamsAuthority, validateAuthority),
 	}
 }
 
-// ValidateBasic performs basic validation on wasm parameters
+// ValidateBasic performs basic validation on compute parameters
 func (p Params) ValidateBasic() error {
-	if err := validateAccessType(p.DefaultInstantiatePermission); err != nil {
-		return errors.Wrap(err, "instantiate default permission")
+	if err := validateBool(p.RejectSelfSends); err != nil {
+		return err
 	}
-	if err := validateAccessConfig(p.UploadAccess); err != nil {
-		return errors.Wrap(err, "upload access")
+	if err := validateBool(p.RejectSelfExecutes); err != nil {
+		return err
 	}
-	return nil
+	if err := validateMaxQueryStackSize(p.MaxQueryStackSize); err != nil {
+		return err
+	}
+	if err := validateUint32(p.MaxMessagesPerCall); err != nil {
+		return err
+	}
+	if err := validateUint64(p.MsgDispatchCost); err != nil {
+		return err
+	}
+	for _, v := range []uint32{p.MaxEventAttributes, p.MaxEventAttributeKeyLength, p.MaxEventAttributeValueLength, p.MaxWasmMsgSize, p.MaxDispatchesPerBlock} {
+		if err := validateUint32(v); err != nil {
+			return err
+		}
+	}
+	if err := validateDenomList(p.SendDenomAllowlist); err != nil {
+		return err
+	}
+	if err := validateBool(p.RejectModuleAccountSends); err != nil {
+		return err
+	}
+	if err := validateBool(p.DispatchEnabled); err != nil {
+		return err
+	}
+	if err := validateBool(p.CanonicalWasmMsgs); err != nil {
+		return err
+	}
+	if err := validateAuthority(p.ParamsAuthority); err != nil {
+		return err
+	}
+	return validateCoins(p.MinInstantiateFunds)
 }
 
-func validateAccessConfig(i interface{}) error {
-	v, ok := i.(AccessConfig)
-	if !ok {
+func validateBool(i interface{}) error {
+	if _, ok := i.(bool); !ok {
 		return fmt.Errorf("invalid parameter type: %T", i)
 	}
-	return v.ValidateBasic()
+	return nil
 }
 
-func validateAccessType(i interface{}) error {
-	v, ok := i.(AccessType)
+func validateMaxQueryStackSize(i interface{}) error {
+	v, ok := i.(uint32)
 	if !ok {
 		return fmt.Errorf("invalid parameter type: %T", i)
 	}
-	if v == Undefined {
-		return sdkerrors.Wrap(ErrEmpty, "type")
+	// a stack size of 0 would reject every smart query, including the ones of users
+	if v == 0 {
+		return fmt.Errorf("max query stack size must be positive")
 	}
-	if _, ok := AllAccessTypes[v]; !ok {
-		return sdkerrors.Wrapf(ErrInvalid, "unknown type: %q", v)
+	return nil
+}
+
+func validateUint32(i interface{}) error {
+	if _, ok := i.(uint32); !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
 	}
 	return nil
 }
 
-func (v AccessConfig) ValidateBasic() error {
-	switch v.Type {
-	case Undefined, "":
-		return sdkerrors.Wrap(ErrEmpty, "type")
-	case Nobody, Everybody:
-		if len(v.Address) != 0 {
-			return sdkerrors.Wrap(ErrInvalid, "address not allowed for this type")
-		}
+func validateUint64(i interface{}) error {
+	if _, ok := i.(uint64); !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return nil
+}
+
+func validateAuthority(i interface{}) error {
+	authority, ok := i.(string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	if authority == "" {
 		return nil
-	case OnlyAddress:
-		return sdk.VerifyAddressFormat(v.Address)
 	}
-	return sdkerrors.Wrapf(ErrInvalid, "unknown type: %q", v.Type)
+	_, err := sdk.AccAddressFromBech32(authority)
+	return err
+}
+
+func validateCoins(i interface{}) error {
+	coins, ok := i.(sdk.Coins)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+	return coins.Validate()
 }
 
-func (v AccessConfig) Allowed(actor sdk.AccAddress) bool {
-	switch v.Type {
-	case Nobody:
-		return false
-	case Everybody:
-		return true
-	case OnlyAddress:
-		return v.Address.Equals(actor)
-	default:
-		panic("unknown type")
+func validateDenomList(i interface{}) error {
+	denoms, ok := i.([]string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
 	}
+	seen := make(map[string]bool, len(denoms))
+	for _, denom := range denoms {
+		if err := sdk.ValidateDenom(denom); err != nil {
+			return err
+		}
+		if seen[denom] {
+			return fmt.Errorf("duplicate denom %s", denom)
+		}
+		seen[denom] = true
+	}
+	return nil
 }
-*/
@@ -101,7 +101,7 @@ func (c *ContractInfo) ValidateBasic() error {
 			}
 		}
 	*/
-	if err := validateLabel(c.Label); err != nil {
+	if err := ValidateLabel(c.Label); err != nil {
 		return sdkerrors.Wrap(err, "label")
 	}
 	return nil
@@ -164,6 +164,15 @@ func NewAbsoluteTxPosition(ctx sdk.Context) *AbsoluteTxPosition {
 	}
 }
 
+// NewBlockInfo returns the block info of ctx as contracts see it
+func NewBlockInfo(ctx sdk.Context) wasmTypes.BlockInfo {
+	return wasmTypes.BlockInfo{
+		Height:  uint64(ctx.BlockHeight()),
+		Time:    uint64(ctx.BlockTime().Unix()),
+		ChainID: ctx.ChainID(),
+	}
+}
+
 // NewEnv initializes the environment for a contract instance
 func NewEnv(ctx sdk.Context, creator sdk.AccAddress, deposit sdk.Coins, contractAddr sdk.AccAddress, contractKey []byte) wasmTypes.Env {
 	// safety checks before casting below
@@ -174,11 +183,7 @@ func NewEnv(ctx sdk.Context, creator sdk.AccAddress, deposit sdk.Coins, contract
 		panic("Block (unix) time must never be negative ")
 	}
 	env := wasmTypes.Env{
-		Block: wasmTypes.BlockInfo{
-			Height:  uint64(ctx.BlockHeight()),
-			Time:    uint64(ctx.BlockTime().Unix()),
-			ChainID: ctx.ChainID(),
-		},
+		Block: NewBlockInfo(ctx),
 		Message: wasmTypes.MessageInfo{
 			Sender:    creator.String(),
 			SentFunds: NewWasmCoins(deposit),
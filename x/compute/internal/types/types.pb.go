@@ -7,6 +7,7 @@ import (
 	bytes "bytes"
 	fmt "fmt"
 	github_com_cosmos_cosmos_sdk_types "github.com/cosmos/cosmos-sdk/types"
+	types "github.com/cosmos/cosmos-sdk/types"
 	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
 	github_com_tendermint_tendermint_libs_bytes "github.com/tendermint/tendermint/libs/bytes"
@@ -90,6 +91,84 @@ func (m *AccessTypeParam) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_AccessTypeParam proto.InternalMessageInfo
 
+// Params defines the set of compute parameters. They are part of the genesis state, and are changed with parameter
+// change proposals, or some of them with a MsgUpdateComputeParams of the ParamsAuthority account.
+type Params struct {
+	// RejectSelfSends makes bank sends from a contract to itself fail, instead of wasting gas on a no-op
+	RejectSelfSends bool `protobuf:"varint,1,opt,name=reject_self_sends,json=rejectSelfSends,proto3" json:"reject_self_sends" yaml:"reject_self_sends"`
+	// RejectSelfExecutes makes a contract executing itself with funds fail, as it's usually a reentrancy bug
+	RejectSelfExecutes bool `protobuf:"varint,2,opt,name=reject_self_executes,json=rejectSelfExecutes,proto3" json:"reject_self_executes" yaml:"reject_self_executes"`
+	// MaxQueryStackSize is how deep contracts may nest smart queries to other contracts
+	MaxQueryStackSize uint32 `protobuf:"varint,3,opt,name=max_query_stack_size,json=maxQueryStackSize,proto3" json:"max_query_stack_size" yaml:"max_query_stack_size"`
+	// MaxMessagesPerCall is how many messages and submessages a single contract call may return, 0 for no limit
+	MaxMessagesPerCall uint32 `protobuf:"varint,4,opt,name=max_messages_per_call,json=maxMessagesPerCall,proto3" json:"max_messages_per_call" yaml:"max_messages_per_call"`
+	// MsgDispatchCost is the SDK gas charged for each message a contract dispatches, on top of the encoding cost
+	MsgDispatchCost uint64 `protobuf:"varint,5,opt,name=msg_dispatch_cost,json=msgDispatchCost,proto3" json:"msg_dispatch_cost" yaml:"msg_dispatch_cost"`
+	// MaxEventAttributes is how many event attributes a single contract call may emit, 0 for no limit
+	MaxEventAttributes uint32 `protobuf:"varint,6,opt,name=max_event_attributes,json=maxEventAttributes,proto3" json:"max_event_attributes" yaml:"max_event_attributes"`
+	// MaxEventAttributeKeyLength is the longest key of an event attribute a contract may emit, 0 for no limit
+	MaxEventAttributeKeyLength uint32 `protobuf:"varint,7,opt,name=max_event_attribute_key_length,json=maxEventAttributeKeyLength,proto3" json:"max_event_attribute_key_length" yaml:"max_event_attribute_key_length"`
+	// MaxEventAttributeValueLength is the longest value of an event attribute a contract may emit, 0 for no limit
+	MaxEventAttributeValueLength uint32 `protobuf:"varint,8,opt,name=max_event_attribute_value_length,json=maxEventAttributeValueLength,proto3" json:"max_event_attribute_value_length" yaml:"max_event_attribute_value_length"`
+	// SendDenomAllowlist is the denoms contracts may send to other accounts, with any message, empty to allow all
+	SendDenomAllowlist []string `protobuf:"bytes,9,rep,name=send_denom_allowlist,json=sendDenomAllowlist,proto3" json:"send_denom_allowlist" yaml:"send_denom_allowlist"`
+	// RejectModuleAccountSends makes bank sends from a contract to a module account fail, as they can break its invariants
+	RejectModuleAccountSends bool `protobuf:"varint,10,opt,name=reject_module_account_sends,json=rejectModuleAccountSends,proto3" json:"reject_module_account_sends" yaml:"reject_module_account_sends"`
+	// DispatchEnabled is whether contracts may dispatch messages. Unsetting it pauses them without halting the chain,
+	// e.g. during an incident.
+	DispatchEnabled bool `protobuf:"varint,11,opt,name=dispatch_enabled,json=dispatchEnabled,proto3" json:"dispatch_enabled" yaml:"dispatch_enabled"`
+	// MaxWasmMsgSize is the largest plaintext inner msg in bytes a contract may execute or instantiate another
+	// contract with, 0 for no limit
+	MaxWasmMsgSize uint32 `protobuf:"varint,12,opt,name=max_wasm_msg_size,json=maxWasmMsgSize,proto3" json:"max_wasm_msg_size" yaml:"max_wasm_msg_size"`
+	// MinInstantiateFunds is the least a contract must send along when it instantiates another contract, to make
+	// spamming contracts costly. Empty for no minimum.
+	MinInstantiateFunds github_com_cosmos_cosmos_sdk_types.Coins `protobuf:"bytes,13,rep,name=min_instantiate_funds,json=minInstantiateFunds,proto3,castrepeated=github.com/cosmos/cosmos-sdk/types.Coins" json:"min_instantiate_funds" yaml:"min_instantiate_funds"`
+	// MaxDispatchesPerBlock is how many messages and submessages a single contract may dispatch in a block, across
+	// all its calls, 0 for no limit.
+	MaxDispatchesPerBlock uint32 `protobuf:"varint,14,opt,name=max_dispatches_per_block,json=maxDispatchesPerBlock,proto3" json:"max_dispatches_per_block" yaml:"max_dispatches_per_block"`
+	// CanonicalWasmMsgs makes the enclave re-encode the JSON msg of the Wasm messages contracts send, with sorted keys
+	// and without whitespace, before encrypting it, so logically identical msgs are encrypted and signed identically.
+	// Off by default, as the called contract then no longer sees the exact bytes the caller sent.
+	CanonicalWasmMsgs bool `protobuf:"varint,15,opt,name=canonical_wasm_msgs,json=canonicalWasmMsgs,proto3" json:"canonical_wasm_msgs" yaml:"canonical_wasm_msgs"`
+	// ParamsAuthority is the bech32 address of the account that may send a MsgUpdateComputeParams, empty for none.
+	// Governance can't send messages in this SDK version, so it sets this with a parameter change proposal, e.g. to
+	// a multisig that tunes the limits of contract calls without waiting for a vote. The message can't change it.
+	ParamsAuthority string `protobuf:"bytes,16,opt,name=params_authority,json=paramsAuthority,proto3" json:"params_authority" yaml:"params_authority"`
+}
+
+func (m *Params) Reset()         { *m = Params{} }
+func (m *Params) String() string { return proto.CompactTextString(m) }
+func (*Params) ProtoMessage()    {}
+func (*Params) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8ba7f40a6d1951b3, []int{1}
+}
+func (m *Params) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Params) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Params.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Params) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params.Merge(m, src)
+}
+func (m *Params) XXX_Size() int {
+	return m.Size()
+}
+func (m *Params) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params proto.InternalMessageInfo
+
 // CodeInfo is data for the uploaded contract WASM code
 type CodeInfo struct {
 	CodeHash []byte                                        `protobuf:"bytes,1,opt,name=code_hash,json=codeHash,proto3" json:"code_hash,omitempty"`
@@ -102,7 +181,7 @@ func (m *CodeInfo) Reset()         { *m = CodeInfo{} }
 func (m *CodeInfo) String() string { return proto.CompactTextString(m) }
 func (*CodeInfo) ProtoMessage()    {}
 func (*CodeInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ba7f40a6d1951b3, []int{1}
+	return fileDescriptor_8ba7f40a6d1951b3, []int{2}
 }
 func (m *CodeInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -140,7 +219,7 @@ func (m *ContractCustomInfo) Reset()         { *m = ContractCustomInfo{} }
 func (m *ContractCustomInfo) String() string { return proto.CompactTextString(m) }
 func (*ContractCustomInfo) ProtoMessage()    {}
 func (*ContractCustomInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ba7f40a6d1951b3, []int{2}
+	return fileDescriptor_8ba7f40a6d1951b3, []int{3}
 }
 func (m *ContractCustomInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -184,7 +263,7 @@ func (m *ContractInfo) Reset()         { *m = ContractInfo{} }
 func (m *ContractInfo) String() string { return proto.CompactTextString(m) }
 func (*ContractInfo) ProtoMessage()    {}
 func (*ContractInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ba7f40a6d1951b3, []int{3}
+	return fileDescriptor_8ba7f40a6d1951b3, []int{4}
 }
 func (m *ContractInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -225,7 +304,7 @@ func (m *AbsoluteTxPosition) Reset()         { *m = AbsoluteTxPosition{} }
 func (m *AbsoluteTxPosition) String() string { return proto.CompactTextString(m) }
 func (*AbsoluteTxPosition) ProtoMessage()    {}
 func (*AbsoluteTxPosition) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ba7f40a6d1951b3, []int{4}
+	return fileDescriptor_8ba7f40a6d1951b3, []int{5}
 }
 func (m *AbsoluteTxPosition) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -266,7 +345,7 @@ func (m *Model) Reset()         { *m = Model{} }
 func (m *Model) String() string { return proto.CompactTextString(m) }
 func (*Model) ProtoMessage()    {}
 func (*Model) Descriptor() ([]byte, []int) {
-	return fileDescriptor_8ba7f40a6d1951b3, []int{5}
+	return fileDescriptor_8ba7f40a6d1951b3, []int{6}
 }
 func (m *Model) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -298,6 +377,7 @@ var xxx_messageInfo_Model proto.InternalMessageInfo
 func init() {
 	proto.RegisterEnum("secret.compute.v1beta1.AccessType", AccessType_name, AccessType_value)
 	proto.RegisterType((*AccessTypeParam)(nil), "secret.compute.v1beta1.AccessTypeParam")
+	proto.RegisterType((*Params)(nil), "secret.compute.v1beta1.Params")
 	proto.RegisterType((*CodeInfo)(nil), "secret.compute.v1beta1.CodeInfo")
 	proto.RegisterType((*ContractCustomInfo)(nil), "secret.compute.v1beta1.ContractCustomInfo")
 	proto.RegisterType((*ContractInfo)(nil), "secret.compute.v1beta1.ContractInfo")
@@ -310,52 +390,94 @@ func init() {
 }
 
 var fileDescriptor_8ba7f40a6d1951b3 = []byte{
-	// 710 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0x4b, 0x6b, 0xdb, 0x4a,
-	0x14, 0xb6, 0xe2, 0xf7, 0xc4, 0xe4, 0x9a, 0xb9, 0xb9, 0xb9, 0x8e, 0x2f, 0xc8, 0xbe, 0x2a, 0x94,
-	0x90, 0x12, 0x8b, 0xa4, 0x5d, 0x94, 0x74, 0x65, 0xc7, 0x2e, 0x71, 0xd2, 0xda, 0x41, 0x79, 0x40,
-	0x5a, 0x8a, 0xd1, 0xe3, 0xc4, 0x16, 0x96, 0x34, 0x46, 0x33, 0x76, 0xad, 0x7f, 0x50, 0xb2, 0xea,
-	0xb2, 0x9b, 0x40, 0xa1, 0xa5, 0xe4, 0x0f, 0xf4, 0x3f, 0x64, 0x99, 0x65, 0x17, 0xc5, 0xb4, 0xce,
-	0x3f, 0xe8, 0x32, 0xab, 0xa2, 0x91, 0xfc, 0xa0, 0x8f, 0x5d, 0x57, 0x3a, 0x8f, 0x6f, 0xbe, 0x73,
-	0xce, 0x77, 0x0e, 0x42, 0x12, 0x05, 0xdd, 0x05, 0x26, 0xeb, 0xc4, 0xee, 0xf5, 0x19, 0xc8, 0x83,
-	0x4d, 0x0d, 0x98, 0xba, 0x29, 0x33, 0xaf, 0x07, 0xb4, 0xd4, 0x73, 0x09, 0x23, 0x78, 0x25, 0xc0,
-	0x94, 0x42, 0x4c, 0x29, 0xc4, 0xe4, 0x97, 0xdb, 0xa4, 0x4d, 0x38, 0x44, 0xf6, 0xad, 0x00, 0x2d,
-	0xe9, 0xe8, 0xaf, 0xb2, 0xae, 0x03, 0xa5, 0x47, 0x5e, 0x0f, 0x0e, 0x54, 0x57, 0xb5, 0xf1, 0x1e,
-	0x8a, 0x0f, 0x54, 0xab, 0x0f, 0x39, 0xa1, 0x28, 0xac, 0x2d, 0x6d, 0x49, 0xa5, 0x5f, 0x13, 0x96,
-	0x66, 0xef, 0x2a, 0xd9, 0x6f, 0xa3, 0x42, 0xc6, 0x53, 0x6d, 0x6b, 0x5b, 0xe2, 0x4f, 0x25, 0x25,
-	0xa0, 0xd8, 0x8e, 0xbd, 0x79, 0x5b, 0x10, 0xa4, 0x0f, 0x02, 0x4a, 0xed, 0x10, 0x03, 0xea, 0xce,
-	0x19, 0xc1, 0xff, 0xa1, 0xb4, 0x4e, 0x0c, 0x68, 0x75, 0x54, 0xda, 0xe1, 0x25, 0x32, 0x4a, 0xca,
-	0x0f, 0xec, 0xaa, 0xb4, 0x83, 0xf7, 0x51, 0x52, 0x77, 0x41, 0x65, 0xc4, 0xcd, 0x2d, 0xf8, 0xa9,
-	0xca, 0xe6, 0xed, 0xa8, 0xb0, 0xd1, 0x36, 0x59, 0xa7, 0xaf, 0xf9, 0x0d, 0xc8, 0x3a, 0xa1, 0x36,
-	0xa1, 0xe1, 0x67, 0x83, 0x1a, 0xdd, 0x70, 0xf6, 0xb2, 0xae, 0x97, 0x0d, 0xc3, 0x05, 0x4a, 0x95,
-	0x09, 0x03, 0x5e, 0x41, 0x09, 0x4a, 0xfa, 0xae, 0x0e, 0xb9, 0x68, 0x51, 0x58, 0x4b, 0x2b, 0xa1,
-	0x87, 0x73, 0x28, 0xa9, 0xf5, 0x4d, 0xcb, 0x00, 0x37, 0x17, 0xe3, 0x89, 0x89, 0x2b, 0x3d, 0x47,
-	0x78, 0x87, 0x38, 0xcc, 0x55, 0x75, 0xb6, 0xd3, 0xa7, 0x8c, 0xd8, 0xbc, 0x63, 0x19, 0x2d, 0x82,
-	0xa3, 0x5b, 0xea, 0x00, 0x5a, 0x5d, 0xf0, 0x82, 0x9e, 0x2b, 0x4b, 0xe3, 0x51, 0x01, 0xd5, 0x82,
-	0xf0, 0x3e, 0x78, 0x0a, 0x82, 0xa9, 0x8d, 0x97, 0x51, 0xdc, 0x52, 0x35, 0xb0, 0xf8, 0x0c, 0x69,
-	0x25, 0x70, 0xa4, 0xcf, 0x02, 0xca, 0x4c, 0xd8, 0x39, 0xef, 0x1d, 0x94, 0xe4, 0x4a, 0x98, 0x06,
-	0xe7, 0x8c, 0x55, 0xd0, 0x78, 0x54, 0x48, 0x70, 0xa1, 0xaa, 0x4a, 0xc2, 0x4f, 0xd5, 0x8d, 0x3f,
-	0xab, 0xc8, 0xb4, 0xb1, 0xd8, 0x5c, 0x63, 0xb8, 0x1a, 0x96, 0x00, 0x23, 0x17, 0x2f, 0x0a, 0x6b,
-	0x8b, 0x5b, 0xeb, 0xbf, 0x5d, 0xb9, 0x46, 0x89, 0xd5, 0x67, 0x70, 0x34, 0x3c, 0x20, 0xd4, 0x64,
-	0x26, 0x71, 0x94, 0xc9, 0x53, 0x49, 0x41, 0xf8, 0xe7, 0x34, 0xfe, 0x1f, 0x65, 0x34, 0x8b, 0xe8,
-	0xdd, 0x56, 0x07, 0xcc, 0x76, 0x87, 0xf1, 0x41, 0xa3, 0xca, 0x22, 0x8f, 0xed, 0xf2, 0x10, 0x5e,
-	0x45, 0x29, 0x36, 0x6c, 0x99, 0x8e, 0x01, 0x43, 0x3e, 0x62, 0x4c, 0x49, 0xb2, 0x61, 0xdd, 0x77,
-	0x25, 0x13, 0xc5, 0x9f, 0x12, 0x03, 0x2c, 0xbc, 0x87, 0xa2, 0xfb, 0x53, 0xe9, 0x1f, 0xde, 0x8e,
-	0x0a, 0x0f, 0xe6, 0x14, 0x60, 0xe0, 0x18, 0xe0, 0xda, 0xa6, 0xc3, 0xe6, 0x4d, 0xcb, 0xd4, 0xa8,
-	0xac, 0x79, 0x0c, 0x68, 0x69, 0x17, 0x86, 0x15, 0xdf, 0x50, 0xa2, 0xe1, 0x76, 0x4e, 0xf8, 0x7d,
-	0x73, 0x3d, 0x95, 0xc0, 0x59, 0xff, 0x28, 0x20, 0x34, 0xbb, 0x68, 0x7c, 0x17, 0xa5, 0x8f, 0x1b,
-	0xd5, 0xda, 0xe3, 0x7a, 0xa3, 0x56, 0xcd, 0x46, 0xf2, 0xff, 0x9e, 0x5f, 0x14, 0xff, 0x9e, 0xa5,
-	0x8f, 0x1d, 0x03, 0xce, 0x4c, 0x07, 0x0c, 0x5c, 0x44, 0x89, 0x46, 0xb3, 0xd2, 0xac, 0x9e, 0x66,
-	0x85, 0xfc, 0xf2, 0xf9, 0x45, 0x31, 0x3b, 0x03, 0x35, 0x88, 0x46, 0x0c, 0x0f, 0xdf, 0x43, 0x99,
-	0x66, 0xe3, 0xc9, 0x69, 0xab, 0x5c, 0xad, 0x2a, 0xb5, 0xc3, 0xc3, 0xec, 0x42, 0x7e, 0xf5, 0xfc,
-	0xa2, 0xf8, 0xcf, 0x0c, 0xd7, 0x74, 0x2c, 0x2f, 0xdc, 0x94, 0x5f, 0xb6, 0x76, 0x52, 0x53, 0x4e,
-	0x39, 0x63, 0xf4, 0xc7, 0xb2, 0xb5, 0x01, 0xb8, 0x9e, 0x4f, 0x9a, 0x4f, 0xbd, 0x7a, 0x27, 0x46,
-	0x2e, 0xdf, 0x8b, 0x91, 0xca, 0x8b, 0xab, 0xaf, 0x62, 0xe4, 0x72, 0x2c, 0x0a, 0x57, 0x63, 0x51,
-	0xb8, 0x1e, 0x8b, 0xc2, 0x97, 0xb1, 0x28, 0xbc, 0xbe, 0x11, 0x23, 0xd7, 0x37, 0x62, 0xe4, 0xd3,
-	0x8d, 0x18, 0x79, 0xf6, 0x68, 0x4e, 0x2a, 0x70, 0xcc, 0xb6, 0xad, 0xda, 0x3d, 0x5d, 0x3e, 0xe4,
-	0x1b, 0x6e, 0x00, 0x7b, 0x49, 0xdc, 0xae, 0x3c, 0x9c, 0xfe, 0x52, 0x4c, 0x87, 0x81, 0xeb, 0xa8,
-	0x56, 0x70, 0x45, 0x5a, 0x82, 0xff, 0x26, 0xee, 0x7f, 0x0f, 0x00, 0x00, 0xff, 0xff, 0x21, 0xd2,
-	0xf1, 0xe2, 0x7a, 0x04, 0x00, 0x00,
+	// 1392 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xcf, 0x6f, 0x1b, 0xc5,
+	0x17, 0xf7, 0x36, 0x3f, 0x3d, 0x49, 0x93, 0x74, 0x9b, 0xb6, 0x5b, 0xb7, 0xf2, 0xfa, 0xbb, 0x5f,
+	0x01, 0x51, 0x51, 0x6d, 0xa5, 0x80, 0x80, 0x72, 0x40, 0x76, 0xec, 0xaa, 0x69, 0x9a, 0x1f, 0x8c,
+	0xdb, 0xa2, 0x16, 0xaa, 0xd5, 0x78, 0xf7, 0xc5, 0x5e, 0xb2, 0xbb, 0x63, 0x76, 0xc6, 0xa9, 0xdd,
+	0x33, 0x07, 0xd4, 0x0b, 0xdc, 0xe0, 0x52, 0x09, 0x09, 0x84, 0x2a, 0xee, 0x48, 0xfc, 0x01, 0x1c,
+	0x7a, 0xec, 0x91, 0x03, 0x5a, 0x20, 0xbd, 0xf9, 0xe8, 0x63, 0x4f, 0x68, 0x66, 0xd7, 0x3f, 0x6a,
+	0x6f, 0x28, 0x07, 0x4e, 0xf6, 0x7c, 0x3e, 0x9f, 0x79, 0xef, 0xcd, 0x7b, 0x33, 0xef, 0x2d, 0x32,
+	0x18, 0x58, 0x01, 0xf0, 0x82, 0x45, 0xbd, 0x66, 0x8b, 0x43, 0xe1, 0x70, 0xbd, 0x06, 0x9c, 0xac,
+	0x17, 0x78, 0xa7, 0x09, 0x2c, 0xdf, 0x0c, 0x28, 0xa7, 0xea, 0xd9, 0x48, 0x93, 0x8f, 0x35, 0xf9,
+	0x58, 0x93, 0x59, 0xad, 0xd3, 0x3a, 0x95, 0x92, 0x82, 0xf8, 0x17, 0xa9, 0x33, 0x59, 0x8b, 0x32,
+	0x8f, 0xb2, 0x42, 0x8d, 0xb0, 0xa1, 0x39, 0x8b, 0x3a, 0x7e, 0xc4, 0x1b, 0x16, 0x5a, 0x2e, 0x5a,
+	0x16, 0x30, 0x76, 0xab, 0xd3, 0x84, 0x3d, 0x12, 0x10, 0x4f, 0xbd, 0x81, 0x66, 0x0e, 0x89, 0xdb,
+	0x02, 0x4d, 0xc9, 0x29, 0x6b, 0x4b, 0x57, 0x8c, 0x7c, 0xb2, 0xc3, 0xfc, 0x70, 0x5f, 0x69, 0xa5,
+	0x17, 0xea, 0x8b, 0x1d, 0xe2, 0xb9, 0x57, 0x0d, 0xb9, 0xd5, 0xc0, 0x91, 0x89, 0xab, 0xd3, 0xdf,
+	0x7e, 0xa7, 0x2b, 0xc6, 0xaf, 0x4b, 0x68, 0x56, 0xda, 0x66, 0xea, 0x7d, 0x74, 0x2a, 0x80, 0xcf,
+	0xc0, 0xe2, 0x26, 0x03, 0x77, 0xdf, 0x64, 0xe0, 0xdb, 0x4c, 0x3a, 0x9a, 0x2f, 0xad, 0x77, 0x43,
+	0x7d, 0x92, 0xec, 0x85, 0xba, 0x16, 0x59, 0x9e, 0xa0, 0x0c, 0xbc, 0x1c, 0x61, 0x55, 0x70, 0xf7,
+	0xab, 0x02, 0x51, 0x1d, 0xb4, 0x3a, 0x2a, 0x83, 0x36, 0x58, 0x2d, 0x0e, 0x4c, 0x3b, 0x21, 0x3d,
+	0xbc, 0xdb, 0x0d, 0xf5, 0x44, 0xbe, 0x17, 0xea, 0x17, 0x26, 0x9d, 0xf4, 0x59, 0x03, 0xab, 0x43,
+	0x3f, 0x95, 0x18, 0x54, 0x1b, 0x68, 0xd5, 0x23, 0x6d, 0xf3, 0xf3, 0x16, 0x04, 0x1d, 0x93, 0x71,
+	0x62, 0x1d, 0x98, 0xcc, 0x79, 0x08, 0xda, 0x54, 0x4e, 0x59, 0x3b, 0x19, 0xb9, 0x4a, 0xe2, 0x87,
+	0xae, 0x92, 0x58, 0x03, 0x9f, 0xf2, 0x48, 0xfb, 0x23, 0x81, 0x56, 0x05, 0x58, 0x75, 0x1e, 0x82,
+	0xea, 0xa2, 0x33, 0x42, 0xeb, 0x01, 0x63, 0xa4, 0x0e, 0xcc, 0x6c, 0x42, 0x60, 0x5a, 0xc4, 0x75,
+	0xb5, 0x69, 0xe9, 0xea, 0xfd, 0x6e, 0xa8, 0x27, 0x0b, 0x7a, 0xa1, 0x7e, 0x71, 0xe8, 0x6b, 0x82,
+	0x36, 0xb0, 0xea, 0x91, 0xf6, 0x76, 0x0c, 0xef, 0x41, 0xb0, 0x41, 0x5c, 0x57, 0x54, 0xc8, 0x63,
+	0x75, 0xd3, 0x76, 0x58, 0x93, 0x70, 0xab, 0x61, 0x5a, 0x94, 0x71, 0x6d, 0x26, 0xa7, 0xac, 0x4d,
+	0x47, 0x15, 0x9a, 0x20, 0x87, 0x15, 0x9a, 0xa0, 0x0c, 0xbc, 0xec, 0xb1, 0x7a, 0x39, 0x86, 0x36,
+	0x28, 0xe3, 0xa2, 0x42, 0x22, 0x18, 0x38, 0x04, 0x9f, 0x9b, 0x84, 0xf3, 0xc0, 0xa9, 0xc9, 0x0a,
+	0xcd, 0xbe, 0x9c, 0xb6, 0x71, 0xfe, 0xe5, 0xb4, 0x8d, 0xb3, 0xd1, 0x49, 0x2a, 0x02, 0x2d, 0x0e,
+	0x40, 0xf5, 0x2b, 0x05, 0x65, 0x13, 0xd4, 0xe6, 0x01, 0x74, 0x4c, 0x17, 0xfc, 0x3a, 0x6f, 0x68,
+	0x73, 0xd2, 0xeb, 0x56, 0x37, 0xd4, 0x5f, 0xa1, 0xec, 0x85, 0xfa, 0x6b, 0xc7, 0xfa, 0x1f, 0xd1,
+	0x19, 0x38, 0x33, 0x11, 0xc9, 0x16, 0x74, 0x6e, 0x4a, 0x52, 0xfd, 0x46, 0x41, 0xb9, 0xa4, 0xfd,
+	0xf2, 0xb1, 0xf4, 0x63, 0x9a, 0x97, 0x31, 0xed, 0x76, 0x43, 0xfd, 0x95, 0xda, 0x5e, 0xa8, 0xbf,
+	0x71, 0x7c, 0x54, 0xa3, 0x4a, 0x03, 0x5f, 0x9c, 0x88, 0xeb, 0x8e, 0xe0, 0xe3, 0xc8, 0x1c, 0xb4,
+	0x2a, 0xde, 0x94, 0x69, 0x83, 0x4f, 0x3d, 0x93, 0xb8, 0x2e, 0x7d, 0xe0, 0x3a, 0x8c, 0x6b, 0xe9,
+	0xdc, 0xd4, 0x5a, 0x3a, 0x2a, 0x4b, 0x12, 0x3f, 0x2c, 0x4b, 0x12, 0x6b, 0x60, 0x55, 0xc0, 0x65,
+	0x81, 0x16, 0xfb, 0xa0, 0xfa, 0x85, 0x82, 0x2e, 0xc4, 0xcf, 0xcc, 0xa3, 0x76, 0xcb, 0x05, 0x93,
+	0x58, 0x16, 0x6d, 0xf9, 0x3c, 0xee, 0x06, 0x48, 0xbe, 0xd5, 0x4a, 0x37, 0xd4, 0xff, 0x49, 0xd6,
+	0x0b, 0x75, 0xe3, 0xa5, 0x27, 0x9b, 0x24, 0x32, 0xb0, 0x16, 0xb1, 0xdb, 0x92, 0x2c, 0x46, 0x5c,
+	0xd4, 0x2a, 0xee, 0xa1, 0x95, 0xc1, 0x5d, 0x05, 0x9f, 0xd4, 0x5c, 0xb0, 0xb5, 0x05, 0xe9, 0xba,
+	0xd0, 0x0d, 0xf5, 0x09, 0xae, 0x17, 0xea, 0xe7, 0x22, 0x7f, 0xe3, 0x8c, 0x81, 0x97, 0xfb, 0x50,
+	0x25, 0x42, 0xd4, 0x4f, 0x91, 0x78, 0xc6, 0xe6, 0x03, 0xc2, 0x3c, 0x53, 0x3c, 0x0a, 0xd9, 0x18,
+	0x16, 0x65, 0x5d, 0xa3, 0x37, 0x34, 0x4e, 0x8e, 0xbc, 0xa1, 0x71, 0xca, 0xc0, 0x4b, 0x1e, 0x69,
+	0x7f, 0x4c, 0x98, 0xb7, 0xcd, 0xea, 0xb2, 0x1f, 0xfc, 0xa2, 0xa0, 0x33, 0x9e, 0xe3, 0x9b, 0x8e,
+	0xcf, 0x38, 0xf1, 0xb9, 0x43, 0x38, 0x98, 0xfb, 0x2d, 0x91, 0xba, 0x93, 0xb9, 0xa9, 0xb5, 0x85,
+	0x2b, 0xe7, 0xf3, 0x51, 0xd3, 0xcf, 0x8b, 0xa6, 0x3f, 0x68, 0xd7, 0x1b, 0xd4, 0xf1, 0x4b, 0xce,
+	0xd3, 0x50, 0x4f, 0xc9, 0x7e, 0x91, 0xb4, 0x7f, 0xa4, 0x5f, 0x24, 0xd1, 0xc6, 0x4f, 0x7f, 0xe8,
+	0x6b, 0x75, 0x87, 0x37, 0x5a, 0x35, 0x31, 0x0c, 0x0a, 0xf1, 0x68, 0x89, 0x7e, 0x2e, 0x33, 0xfb,
+	0x20, 0x9e, 0x53, 0xc2, 0x13, 0xc3, 0xa7, 0x3d, 0xc7, 0xdf, 0x1c, 0x9a, 0xb8, 0x26, 0x2c, 0xa8,
+	0x6d, 0xa4, 0x89, 0x03, 0xf6, 0xf3, 0x15, 0x37, 0xa3, 0x9a, 0x4b, 0xad, 0x03, 0x6d, 0x49, 0xe6,
+	0xe7, 0xc3, 0x6e, 0xa8, 0x1f, 0xab, 0xe9, 0x85, 0xba, 0x3e, 0x4c, 0x53, 0x92, 0xc2, 0xc0, 0xa2,
+	0x15, 0x96, 0x07, 0xcc, 0x1e, 0x04, 0x25, 0x81, 0xab, 0x80, 0x4e, 0x5b, 0xc4, 0xa7, 0xbe, 0x63,
+	0x11, 0x77, 0x90, 0x60, 0xa6, 0x2d, 0xcb, 0x8a, 0xbf, 0xd3, 0x0d, 0xf5, 0x24, 0xba, 0x17, 0xea,
+	0x99, 0xc8, 0x5f, 0x02, 0x69, 0xe0, 0x53, 0x03, 0x34, 0x2e, 0x8f, 0xbc, 0x55, 0x4d, 0x39, 0xe9,
+	0x4c, 0xd2, 0xe2, 0x0d, 0x1a, 0x38, 0xbc, 0xa3, 0xad, 0xe4, 0x94, 0xb5, 0x74, 0x74, 0xab, 0xc6,
+	0xb9, 0xe1, 0xad, 0x1a, 0x67, 0x0c, 0xbc, 0x1c, 0x41, 0xc5, 0x01, 0xf2, 0xa3, 0x82, 0xe6, 0x37,
+	0xa8, 0x0d, 0x9b, 0xfe, 0x3e, 0x55, 0x2f, 0xa0, 0xb4, 0x45, 0x6d, 0x30, 0x1b, 0x84, 0x35, 0xe4,
+	0x00, 0x5d, 0xc4, 0xf3, 0x02, 0xb8, 0x4e, 0x58, 0x43, 0xdd, 0x42, 0x73, 0x56, 0x00, 0x84, 0xd3,
+	0x40, 0x4e, 0xbe, 0xc5, 0xd2, 0xfa, 0x8b, 0x50, 0xbf, 0xfc, 0x2f, 0x4a, 0x57, 0xb4, 0xac, 0xa2,
+	0x6d, 0x07, 0xc0, 0x18, 0xee, 0x5b, 0x50, 0xcf, 0xa2, 0x59, 0x46, 0x5b, 0x81, 0x15, 0x8d, 0xb6,
+	0x34, 0x8e, 0x57, 0xaa, 0x86, 0xe6, 0x6a, 0x2d, 0xc7, 0xb5, 0x21, 0x90, 0x83, 0x28, 0x8d, 0xfb,
+	0x4b, 0xe3, 0x13, 0xa4, 0x6e, 0x50, 0x9f, 0x07, 0xc4, 0xe2, 0x1b, 0x2d, 0xc6, 0xa9, 0x27, 0x23,
+	0x2e, 0xa0, 0x05, 0xf0, 0x2d, 0x97, 0x1c, 0xca, 0x7e, 0x19, 0xc5, 0x5c, 0x5a, 0x3a, 0x0a, 0x75,
+	0x54, 0x89, 0xe0, 0x2d, 0xe8, 0x60, 0x04, 0x83, 0xff, 0xea, 0x2a, 0x9a, 0x71, 0x49, 0x0d, 0x5c,
+	0x79, 0x86, 0x34, 0x8e, 0x16, 0xc6, 0xef, 0x0a, 0x5a, 0xec, 0x5b, 0x97, 0x76, 0xff, 0x8f, 0xe6,
+	0x64, 0x26, 0x1c, 0x5b, 0xda, 0x9c, 0x2e, 0xa1, 0xa3, 0x50, 0x9f, 0x95, 0x89, 0x2a, 0xe3, 0x59,
+	0x41, 0x6d, 0xda, 0xff, 0x6d, 0x46, 0x06, 0x81, 0x4d, 0x8f, 0x04, 0xa6, 0x96, 0x63, 0x17, 0x60,
+	0xcb, 0x71, 0xb9, 0x70, 0xe5, 0xd2, 0xb1, 0x5f, 0x4e, 0x35, 0x46, 0xdd, 0x16, 0x87, 0x5b, 0xed,
+	0x3d, 0xca, 0x1c, 0xee, 0x50, 0x1f, 0xf7, 0xb7, 0x1a, 0x18, 0xa9, 0x93, 0xb4, 0xfa, 0x3f, 0xb4,
+	0x28, 0xaf, 0xb7, 0xd9, 0x00, 0xa7, 0xde, 0xe0, 0xf2, 0xa0, 0x53, 0x78, 0x41, 0x62, 0xd7, 0x25,
+	0xa4, 0x9e, 0x47, 0xf3, 0xbc, 0x6d, 0x3a, 0xbe, 0x0d, 0x6d, 0x79, 0xc4, 0x69, 0x3c, 0xc7, 0xdb,
+	0x9b, 0x62, 0x69, 0x38, 0x68, 0x66, 0x9b, 0xda, 0xe0, 0xaa, 0x37, 0xd0, 0xd4, 0xd6, 0x20, 0xf5,
+	0xef, 0xbd, 0x08, 0xf5, 0xb7, 0x47, 0x32, 0xc0, 0xc1, 0xb7, 0x21, 0xf0, 0x1c, 0x9f, 0x8f, 0xfe,
+	0x75, 0x9d, 0x1a, 0x2b, 0xd4, 0x3a, 0x1c, 0x58, 0xfe, 0x3a, 0xb4, 0x4b, 0xe2, 0x0f, 0x9e, 0x8a,
+	0xab, 0x23, 0x07, 0x48, 0x94, 0x4f, 0x1c, 0x2d, 0x2e, 0xfd, 0xac, 0x20, 0x34, 0xfc, 0x30, 0x54,
+	0x5f, 0x47, 0xe9, 0xdb, 0x3b, 0xe5, 0xca, 0xb5, 0xcd, 0x9d, 0x4a, 0x79, 0x25, 0x95, 0x39, 0xf7,
+	0xe8, 0x71, 0xee, 0xf4, 0x90, 0xbe, 0xed, 0xdb, 0xb0, 0xef, 0xf8, 0x60, 0xab, 0x39, 0x34, 0xbb,
+	0xb3, 0x5b, 0xda, 0x2d, 0xdf, 0x5d, 0x51, 0x32, 0xab, 0x8f, 0x1e, 0xe7, 0x56, 0x86, 0xa2, 0x1d,
+	0x5a, 0xa3, 0x76, 0x47, 0x7d, 0x13, 0x2d, 0xee, 0xee, 0xdc, 0xbc, 0x6b, 0x16, 0xcb, 0x65, 0x5c,
+	0xa9, 0x56, 0x57, 0x4e, 0x64, 0xce, 0x3f, 0x7a, 0x9c, 0x3b, 0x33, 0xd4, 0xed, 0xfa, 0x6e, 0x27,
+	0xae, 0x94, 0x70, 0x5b, 0xb9, 0x53, 0xc1, 0x77, 0xa5, 0xc5, 0xa9, 0x71, 0xb7, 0x95, 0x43, 0x08,
+	0x3a, 0xc2, 0x68, 0x66, 0xfe, 0xcb, 0xef, 0xb3, 0xa9, 0x27, 0x3f, 0x64, 0x53, 0xa5, 0xfb, 0x4f,
+	0xff, 0xca, 0xa6, 0x9e, 0x1c, 0x65, 0x95, 0xa7, 0x47, 0x59, 0xe5, 0xd9, 0x51, 0x56, 0xf9, 0xf3,
+	0x28, 0xab, 0x7c, 0xfd, 0x3c, 0x9b, 0x7a, 0xf6, 0x3c, 0x9b, 0xfa, 0xed, 0x79, 0x36, 0x75, 0xef,
+	0x83, 0x91, 0x54, 0x81, 0xef, 0xd4, 0x3d, 0xe2, 0x35, 0xad, 0x42, 0x55, 0x56, 0x78, 0x07, 0xf8,
+	0x03, 0x1a, 0x1c, 0x14, 0xda, 0x83, 0x2f, 0x77, 0xc7, 0xe7, 0x10, 0xf8, 0xc4, 0x8d, 0x6e, 0x51,
+	0x6d, 0x56, 0x7e, 0x6d, 0xbf, 0xf5, 0xf7, 0x00, 0x36, 0x49, 0x4e, 0xdc, 0xe1, 0x0b, 0x00, 0x00,
 }
 
 func (this *AccessTypeParam) Equal(that interface{}) bool {
@@ -382,6 +504,85 @@ func (this *AccessTypeParam) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *Params) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Params)
+	if !ok {
+		that2, ok := that.(Params)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.RejectSelfSends != that1.RejectSelfSends {
+		return false
+	}
+	if this.RejectSelfExecutes != that1.RejectSelfExecutes {
+		return false
+	}
+	if this.MaxQueryStackSize != that1.MaxQueryStackSize {
+		return false
+	}
+	if this.MaxMessagesPerCall != that1.MaxMessagesPerCall {
+		return false
+	}
+	if this.MsgDispatchCost != that1.MsgDispatchCost {
+		return false
+	}
+	if this.MaxEventAttributes != that1.MaxEventAttributes {
+		return false
+	}
+	if this.MaxEventAttributeKeyLength != that1.MaxEventAttributeKeyLength {
+		return false
+	}
+	if this.MaxEventAttributeValueLength != that1.MaxEventAttributeValueLength {
+		return false
+	}
+	if len(this.SendDenomAllowlist) != len(that1.SendDenomAllowlist) {
+		return false
+	}
+	for i := range this.SendDenomAllowlist {
+		if this.SendDenomAllowlist[i] != that1.SendDenomAllowlist[i] {
+			return false
+		}
+	}
+	if this.RejectModuleAccountSends != that1.RejectModuleAccountSends {
+		return false
+	}
+	if this.DispatchEnabled != that1.DispatchEnabled {
+		return false
+	}
+	if this.MaxWasmMsgSize != that1.MaxWasmMsgSize {
+		return false
+	}
+	if len(this.MinInstantiateFunds) != len(that1.MinInstantiateFunds) {
+		return false
+	}
+	for i := range this.MinInstantiateFunds {
+		if !this.MinInstantiateFunds[i].Equal(&that1.MinInstantiateFunds[i]) {
+			return false
+		}
+	}
+	if this.MaxDispatchesPerBlock != that1.MaxDispatchesPerBlock {
+		return false
+	}
+	if this.CanonicalWasmMsgs != that1.CanonicalWasmMsgs {
+		return false
+	}
+	if this.ParamsAuthority != that1.ParamsAuthority {
+		return false
+	}
+	return true
+}
 func (this *CodeInfo) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -557,6 +758,151 @@ func (m *AccessTypeParam) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *Params) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Params) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Params) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.ParamsAuthority) > 0 {
+		i -= len(m.ParamsAuthority)
+		copy(dAtA[i:], m.ParamsAuthority)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.ParamsAuthority)))
+		i--
+		dAtA[i] = 0x1
+		i--
+		dAtA[i] = 0x82
+	}
+	if m.CanonicalWasmMsgs {
+		i--
+		if m.CanonicalWasmMsgs {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x78
+	}
+	if m.MaxDispatchesPerBlock != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxDispatchesPerBlock))
+		i--
+		dAtA[i] = 0x70
+	}
+	if len(m.MinInstantiateFunds) > 0 {
+		for iNdEx := len(m.MinInstantiateFunds) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.MinInstantiateFunds[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTypes(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x6a
+		}
+	}
+	if m.MaxWasmMsgSize != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxWasmMsgSize))
+		i--
+		dAtA[i] = 0x60
+	}
+	if m.DispatchEnabled {
+		i--
+		if m.DispatchEnabled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.RejectModuleAccountSends {
+		i--
+		if m.RejectModuleAccountSends {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x50
+	}
+	if len(m.SendDenomAllowlist) > 0 {
+		for iNdEx := len(m.SendDenomAllowlist) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.SendDenomAllowlist[iNdEx])
+			copy(dAtA[i:], m.SendDenomAllowlist[iNdEx])
+			i = encodeVarintTypes(dAtA, i, uint64(len(m.SendDenomAllowlist[iNdEx])))
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
+	if m.MaxEventAttributeValueLength != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxEventAttributeValueLength))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.MaxEventAttributeKeyLength != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxEventAttributeKeyLength))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.MaxEventAttributes != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxEventAttributes))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.MsgDispatchCost != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MsgDispatchCost))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.MaxMessagesPerCall != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxMessagesPerCall))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.MaxQueryStackSize != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxQueryStackSize))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.RejectSelfExecutes {
+		i--
+		if m.RejectSelfExecutes {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.RejectSelfSends {
+		i--
+		if m.RejectSelfSends {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *CodeInfo) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -792,6 +1138,70 @@ func (m *AccessTypeParam) Size() (n int) {
 	return n
 }
 
+func (m *Params) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.RejectSelfSends {
+		n += 2
+	}
+	if m.RejectSelfExecutes {
+		n += 2
+	}
+	if m.MaxQueryStackSize != 0 {
+		n += 1 + sovTypes(uint64(m.MaxQueryStackSize))
+	}
+	if m.MaxMessagesPerCall != 0 {
+		n += 1 + sovTypes(uint64(m.MaxMessagesPerCall))
+	}
+	if m.MsgDispatchCost != 0 {
+		n += 1 + sovTypes(uint64(m.MsgDispatchCost))
+	}
+	if m.MaxEventAttributes != 0 {
+		n += 1 + sovTypes(uint64(m.MaxEventAttributes))
+	}
+	if m.MaxEventAttributeKeyLength != 0 {
+		n += 1 + sovTypes(uint64(m.MaxEventAttributeKeyLength))
+	}
+	if m.MaxEventAttributeValueLength != 0 {
+		n += 1 + sovTypes(uint64(m.MaxEventAttributeValueLength))
+	}
+	if len(m.SendDenomAllowlist) > 0 {
+		for _, s := range m.SendDenomAllowlist {
+			l = len(s)
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if m.RejectModuleAccountSends {
+		n += 2
+	}
+	if m.DispatchEnabled {
+		n += 2
+	}
+	if m.MaxWasmMsgSize != 0 {
+		n += 1 + sovTypes(uint64(m.MaxWasmMsgSize))
+	}
+	if len(m.MinInstantiateFunds) > 0 {
+		for _, e := range m.MinInstantiateFunds {
+			l = e.Size()
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if m.MaxDispatchesPerBlock != 0 {
+		n += 1 + sovTypes(uint64(m.MaxDispatchesPerBlock))
+	}
+	if m.CanonicalWasmMsgs {
+		n += 2
+	}
+	l = len(m.ParamsAuthority)
+	if l > 0 {
+		n += 2 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
 func (m *CodeInfo) Size() (n int) {
 	if m == nil {
 		return 0
@@ -965,6 +1375,406 @@ func (m *AccessTypeParam) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Params) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Params: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Params: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectSelfSends", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.RejectSelfSends = bool(v != 0)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectSelfExecutes", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.RejectSelfExecutes = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxQueryStackSize", wireType)
+			}
+			m.MaxQueryStackSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxQueryStackSize |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxMessagesPerCall", wireType)
+			}
+			m.MaxMessagesPerCall = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxMessagesPerCall |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgDispatchCost", wireType)
+			}
+			m.MsgDispatchCost = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MsgDispatchCost |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxEventAttributes", wireType)
+			}
+			m.MaxEventAttributes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxEventAttributes |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxEventAttributeKeyLength", wireType)
+			}
+			m.MaxEventAttributeKeyLength = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxEventAttributeKeyLength |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxEventAttributeValueLength", wireType)
+			}
+			m.MaxEventAttributeValueLength = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxEventAttributeValueLength |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SendDenomAllowlist", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SendDenomAllowlist = append(m.SendDenomAllowlist, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectModuleAccountSends", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.RejectModuleAccountSends = bool(v != 0)
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DispatchEnabled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.DispatchEnabled = bool(v != 0)
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxWasmMsgSize", wireType)
+			}
+			m.MaxWasmMsgSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxWasmMsgSize |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinInstantiateFunds", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MinInstantiateFunds = append(m.MinInstantiateFunds, types.Coin{})
+			if err := m.MinInstantiateFunds[len(m.MinInstantiateFunds)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxDispatchesPerBlock", wireType)
+			}
+			m.MaxDispatchesPerBlock = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxDispatchesPerBlock |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CanonicalWasmMsgs", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CanonicalWasmMsgs = bool(v != 0)
+		case 16:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ParamsAuthority", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ParamsAuthority = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *CodeInfo) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
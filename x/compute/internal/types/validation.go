@@ -13,6 +13,9 @@ const (
 	// MaxLabelSize is the longest label that can be used when Instantiating a contract
 	MaxLabelSize = 512
 
+	// MaxSaltSize is the longest salt that can be used to derive the address of a contract
+	MaxSaltSize = 64
+
 	// BuildTagRegexp is a docker image regexp.
 	// We only support max 128 characters, with at least one organization name (subset of all legal names).
 	//
@@ -69,12 +72,24 @@ func validateWasmCode(s []byte) error {
 	return nil
 }
 
-func validateLabel(label string) error {
+// ValidateLabel checks that a contract label is set and is at most MaxLabelSize bytes long
+func ValidateLabel(label string) error {
 	if label == "" {
-		return sdkerrors.Wrap(ErrEmpty, "is required")
+		return sdkerrors.Wrap(ErrEmpty, "label is required")
 	}
 	if len(label) > MaxLabelSize {
-		return sdkerrors.Wrap(ErrLimit, "cannot be longer than 128 characters")
+		return sdkerrors.Wrapf(ErrLimit, "label cannot be longer than %d bytes", MaxLabelSize)
+	}
+	return nil
+}
+
+// ValidateSalt checks that a salt for a predictable contract address is set and is at most MaxSaltSize bytes long
+func ValidateSalt(salt []byte) error {
+	if len(salt) == 0 {
+		return sdkerrors.Wrap(ErrEmpty, "salt is required")
+	}
+	if len(salt) > MaxSaltSize {
+		return sdkerrors.Wrapf(ErrLimit, "salt cannot be longer than %d bytes", MaxSaltSize)
 	}
 	return nil
 }